@@ -40,11 +40,29 @@ type Config struct {
 	// Cloudinary
 	Cloudinary CloudinaryConfig
 
+	// Media storage
+	Media MediaConfig
+
 	// Chapa
 	Chapa ChapaConfig
 
 	// SMTP
 	SMTP SMTPConfig
+
+	// Booking lifecycle
+	Booking BookingConfig
+}
+
+// BookingConfig holds booking lifecycle settings
+type BookingConfig struct {
+	// Owners must answer a request within OwnerResponseWindow and renters must
+	// pay a confirmed booking within PaymentWindow, or the booking expires
+	OwnerResponseWindow time.Duration
+	PaymentWindow       time.Duration
+	ExpiryCheckInterval time.Duration
+
+	// QuoteSecret signs price quotes, kept apart from the JWT secret
+	QuoteSecret string
 }
 
 // CloudinaryConfig holds Cloudinary settings
@@ -55,6 +73,33 @@ type CloudinaryConfig struct {
 	UploadPreset string
 }
 
+// MediaConfig holds settings for storing uploaded media
+type MediaConfig struct {
+	// Storage is "local", "s3" or "cloudinary"
+	Storage string
+
+	// LocalDir is where local storage writes files, served under PublicURL
+	LocalDir  string
+	PublicURL string
+
+	S3 S3Config
+}
+
+// S3Config holds settings for an S3-compatible object store
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	// PublicURL is the base URL objects are read from; defaults to the bucket URL
+	PublicURL string
+
+	// PathStyle addresses the bucket as endpoint/bucket, as MinIO expects
+	PathStyle bool
+}
+
 // ChapaConfig holds Chapa settings
 type ChapaConfig struct {
 	SecretKey     string
@@ -131,6 +176,9 @@ type ServicesConfig struct {
 	PaymentServiceAddr      string
 	NotificationServiceAddr string
 	ReviewServiceAddr       string
+
+	// Token authenticates services to each other's internal endpoints
+	Token string
 }
 
 // Load reads configuration from environment variables and config files
@@ -196,6 +244,7 @@ func Load(serviceName string) (*Config, error) {
 			PaymentServiceAddr:      v.GetString("services.payment"),
 			NotificationServiceAddr: v.GetString("services.notification"),
 			ReviewServiceAddr:       v.GetString("services.review"),
+			Token:                   v.GetString("services.token"),
 		},
 
 		Cloudinary: CloudinaryConfig{
@@ -205,6 +254,21 @@ func Load(serviceName string) (*Config, error) {
 			UploadPreset: v.GetString("cloudinary.upload_preset"),
 		},
 
+		Media: MediaConfig{
+			Storage:   v.GetString("media.storage"),
+			LocalDir:  v.GetString("media.local_dir"),
+			PublicURL: v.GetString("media.public_url"),
+			S3: S3Config{
+				Endpoint:  v.GetString("media.s3.endpoint"),
+				Region:    v.GetString("media.s3.region"),
+				Bucket:    v.GetString("media.s3.bucket"),
+				AccessKey: v.GetString("media.s3.access_key"),
+				SecretKey: v.GetString("media.s3.secret_key"),
+				PublicURL: v.GetString("media.s3.public_url"),
+				PathStyle: v.GetBool("media.s3.path_style"),
+			},
+		},
+
 		Chapa: ChapaConfig{
 			SecretKey:     v.GetString("chapa.secret_key"),
 			PublicKey:     v.GetString("chapa.public_key"),
@@ -221,6 +285,13 @@ func Load(serviceName string) (*Config, error) {
 			From:     v.GetString("smtp.from_email"),
 			FromName: v.GetString("smtp.from_name"),
 		},
+
+		Booking: BookingConfig{
+			OwnerResponseWindow: v.GetDuration("booking.owner_response_window"),
+			PaymentWindow:       v.GetDuration("booking.payment_window"),
+			ExpiryCheckInterval: v.GetDuration("booking.expiry_check_interval"),
+			QuoteSecret:         v.GetString("booking.quote_secret"),
+		},
 	}
 
 	return config, nil
@@ -265,6 +336,7 @@ func setDefaults(v *viper.Viper, serviceName string) {
 	v.SetDefault("services.booking", "localhost:50053")
 	v.SetDefault("services.payment", "localhost:50054")
 	v.SetDefault("services.review", "localhost:50056")
+	v.SetDefault("services.token", "internal-service-token-change-in-production")
 
 	// Cloudinary (Defaults are empty, must be provided by env)
 	v.SetDefault("cloudinary.cloud_name", "")
@@ -272,6 +344,18 @@ func setDefaults(v *viper.Viper, serviceName string) {
 	v.SetDefault("cloudinary.api_secret", "")
 	v.SetDefault("cloudinary.upload_preset", "")
 
+	// Media
+	v.SetDefault("media.storage", "local")
+	v.SetDefault("media.local_dir", "./uploads")
+	v.SetDefault("media.public_url", "http://localhost:8080/media")
+	v.SetDefault("media.s3.endpoint", "")
+	v.SetDefault("media.s3.region", "us-east-1")
+	v.SetDefault("media.s3.bucket", "")
+	v.SetDefault("media.s3.access_key", "")
+	v.SetDefault("media.s3.secret_key", "")
+	v.SetDefault("media.s3.public_url", "")
+	v.SetDefault("media.s3.path_style", true)
+
 	// Chapa
 	v.SetDefault("chapa.secret_key", "")
 	v.SetDefault("chapa.public_key", "")
@@ -286,4 +370,10 @@ func setDefaults(v *viper.Viper, serviceName string) {
 	v.SetDefault("smtp.password", "")
 	v.SetDefault("smtp.from_email", "")
 	v.SetDefault("smtp.from_name", "RentalFlow")
+
+	// Booking
+	v.SetDefault("booking.owner_response_window", 24*time.Hour)
+	v.SetDefault("booking.payment_window", 24*time.Hour)
+	v.SetDefault("booking.expiry_check_interval", time.Minute)
+	v.SetDefault("booking.quote_secret", "quote-secret-change-in-production")
 }
@@ -0,0 +1,198 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rentalflow/rentalflow/pkg/config"
+)
+
+const (
+	cloudinaryAPI      = "https://api.cloudinary.com/v1_1/"
+	cloudinaryDelivery = "https://res.cloudinary.com/"
+)
+
+// CloudinaryStorage keeps files in a Cloudinary account using signed uploads.
+// Keys become public IDs without their extension, which Cloudinary adds back
+// as the format. Private keys are uploaded as authenticated assets, which
+// Cloudinary only delivers from signed URLs.
+type CloudinaryStorage struct {
+	cfg     config.CloudinaryConfig
+	baseURL string
+	client  *http.Client
+}
+
+// NewCloudinaryStorage creates storage for the configured Cloudinary account
+func NewCloudinaryStorage(cfg config.CloudinaryConfig) (*CloudinaryStorage, error) {
+	if cfg.CloudName == "" || cfg.APIKey == "" || cfg.APISecret == "" {
+		return nil, errors.New("media: cloudinary storage needs a cloud name and API credentials")
+	}
+	return &CloudinaryStorage{
+		cfg:     cfg,
+		baseURL: cloudinaryAPI + url.PathEscape(cfg.CloudName),
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Put uploads the file and returns its secure URL
+func (s *CloudinaryStorage) Put(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	params := map[string]string{
+		"public_id": publicID(key),
+		"overwrite": "true",
+		"type":      deliveryType(key),
+	}
+	if s.cfg.UploadPreset != "" {
+		params["upload_preset"] = s.cfg.UploadPreset
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range s.signed(params) {
+		form.WriteField(name, value)
+	}
+	file, err := form.CreateFormFile("file", path.Base(key))
+	if err != nil {
+		return "", err
+	}
+	file.Write(data)
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		SecureURL string `json:"secure_url"`
+	}
+	if err := s.post(ctx, "/image/upload", form.FormDataContentType(), &body, &result); err != nil {
+		return "", err
+	}
+	if isPrivate(key) {
+		return "", nil
+	}
+	return result.SecureURL, nil
+}
+
+// Open downloads the file. Authenticated assets are fetched from a signed
+// delivery URL, whose signature is the first eight characters of the
+// URL-safe base64 SHA-1 of the path followed by the secret.
+func (s *CloudinaryStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	target := cloudinaryDelivery + url.PathEscape(s.cfg.CloudName) + "/image/" + deliveryType(key) + "/"
+	if isPrivate(key) {
+		sum := sha1.Sum([]byte(key + s.cfg.APISecret))
+		target += "s--" + base64.RawURLEncoding.EncodeToString(sum[:])[:8] + "--/"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target+key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("media: cloudinary delivery returned %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// Delete destroys the file. Cloudinary answers "not found" rather than
+// failing for missing files.
+func (s *CloudinaryStorage) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	values := url.Values{}
+	params := map[string]string{"public_id": publicID(key), "type": deliveryType(key), "invalidate": "true"}
+	for name, value := range s.signed(params) {
+		values.Set(name, value)
+	}
+	var result struct {
+		Result string `json:"result"`
+	}
+	return s.post(ctx, "/image/destroy", "application/x-www-form-urlencoded",
+		strings.NewReader(values.Encode()), &result)
+}
+
+// signed adds the timestamp, API key and signature to the request parameters.
+// The signature is the SHA-1 of the sorted parameters followed by the secret.
+func (s *CloudinaryStorage) signed(params map[string]string) map[string]string {
+	params["timestamp"] = strconv.FormatInt(time.Now().Unix(), 10)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + params[name]
+	}
+	sum := sha1.Sum([]byte(strings.Join(pairs, "&") + s.cfg.APISecret))
+
+	params["api_key"] = s.cfg.APIKey
+	params["signature"] = hex.EncodeToString(sum[:])
+	return params
+}
+
+func (s *CloudinaryStorage) post(ctx context.Context, endpoint, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("media: cloudinary returned %d: %s", resp.StatusCode, failure.Error.Message)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// publicID drops the extension from a key
+func publicID(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key))
+}
+
+// deliveryType keeps private files behind Cloudinary's signed delivery
+func deliveryType(key string) string {
+	if isPrivate(key) {
+		return "authenticated"
+	}
+	return "upload"
+}
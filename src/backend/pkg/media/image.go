@@ -0,0 +1,182 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+)
+
+const jpegQuality = 85
+
+// encodedImage is one image ready to store
+type encodedImage struct {
+	data   []byte
+	width  int
+	height int
+}
+
+// processedImage is an upload after orientation, scaling and re-encoding
+type processedImage struct {
+	contentType string
+	ext         string
+	original    encodedImage
+	variants    map[string]encodedImage
+}
+
+// processImage decodes an image, turns it upright and encodes it afresh along
+// with each of the policy's sizes. GIFs are stored as a still PNG of their
+// first frame.
+func processImage(data []byte, policy Policy) (*processedImage, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > policy.MaxPixels {
+		return nil, ErrInvalidImage
+	}
+
+	var src image.Image
+	switch format {
+	case "jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "png":
+		src, err = png.Decode(bytes.NewReader(data))
+	case "gif":
+		src, err = gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, ErrUnsupportedType
+	}
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	img := toRGBA(src)
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	if policy.MaxDimension > 0 {
+		img = fit(img, policy.MaxDimension, policy.MaxDimension)
+	}
+
+	out := &processedImage{contentType: TypePNG, ext: ".png", variants: map[string]encodedImage{}}
+	if format == "jpeg" {
+		out.contentType, out.ext = TypeJPEG, ".jpg"
+	}
+
+	if out.original, err = encode(img, out.contentType); err != nil {
+		return nil, err
+	}
+	for _, size := range policy.Sizes {
+		if out.variants[size.Name], err = encode(fit(img, size.Width, size.Height), out.contentType); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func encode(img *image.RGBA, contentType string) (encodedImage, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == TypeJPEG {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return encodedImage{}, err
+	}
+	b := img.Bounds()
+	return encodedImage{data: buf.Bytes(), width: b.Dx(), height: b.Dy()}, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// fit scales an image down, keeping its aspect ratio, until it fits in the
+// box. Images that already fit are returned as they are.
+func fit(src *image.RGBA, maxWidth, maxHeight int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxWidth && h <= maxHeight {
+		return src
+	}
+	dw, dh := maxWidth, h*maxWidth/w
+	if dh > maxHeight {
+		dw, dh = w*maxHeight/h, maxHeight
+	}
+	return resize(src, max(dw, 1), max(dh, 1))
+}
+
+// resize shrinks an image by averaging the source pixels that fall under
+// each destination pixel
+func resize(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// orient turns an image upright according to its EXIF orientation (1-8)
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // upside down and mirrored
+				sx, sy = x, h-1-y
+			case 5: // on its side and mirrored
+				sx, sy = y, x
+			case 6: // turned left, so rotate right
+				sx, sy = y, h-1-x
+			case 7: // turned right and mirrored
+				sx, sy = w-1-y, h-1-x
+			case 8: // turned right, so rotate left
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
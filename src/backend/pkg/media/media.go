@@ -0,0 +1,307 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Upload errors
+var (
+	ErrTooLarge        = errors.New("file is too large")
+	ErrUnsupportedType = errors.New("file type is not allowed")
+	ErrInvalidImage    = errors.New("image could not be read")
+	ErrInvalidKey      = errors.New("invalid media key")
+	ErrNoFile          = errors.New("no file was uploaded")
+	ErrNotFound        = errors.New("media file not found")
+)
+
+// Content types uploads may have. The type is sniffed from the file itself,
+// never taken from the client.
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+)
+
+// privatePrefix starts the keys of files that must never be publicly
+// readable. Storage gives no URL for them; the owning service reads them back
+// with Open and decides who may see them.
+const privatePrefix = "private/"
+
+// Storage keeps uploaded files under a key and serves them from a URL
+type Storage interface {
+	// Put stores data under key, replacing anything already there, and
+	// returns the URL it can be read from, or "" for a private key
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+
+	// Open reads back the file under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the file under key. Deleting a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Size is a box a thumbnail is scaled down to fit in
+type Size struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Policy says what may be uploaded for one use and which sizes are generated
+type Policy struct {
+	// Prefix groups the keys, such as "items" or "avatars"
+	Prefix string
+
+	MaxBytes int64
+	Types    []string
+
+	// MaxPixels bounds width times height, so a small file can't decode to a
+	// huge image
+	MaxPixels int
+
+	// MaxDimension scales larger images down before they are stored
+	MaxDimension int
+
+	Sizes []Size
+
+	// Private keeps the files out of public storage, for uploads such as
+	// identity documents that only their owner and admins may see
+	Private bool
+}
+
+// Policies for the kinds of media the services accept
+var (
+	ItemImagePolicy = Policy{
+		Prefix:       "items",
+		MaxBytes:     10 << 20,
+		Types:        []string{TypeJPEG, TypePNG, TypeGIF},
+		MaxPixels:    40_000_000,
+		MaxDimension: 2048,
+		Sizes: []Size{
+			{Name: "thumb", Width: 200, Height: 200},
+			{Name: "medium", Width: 640, Height: 640},
+			{Name: "large", Width: 1280, Height: 1280},
+		},
+	}
+
+	AvatarPolicy = Policy{
+		Prefix:       "avatars",
+		MaxBytes:     5 << 20,
+		Types:        []string{TypeJPEG, TypePNG, TypeGIF},
+		MaxPixels:    25_000_000,
+		MaxDimension: 1024,
+		Sizes: []Size{
+			{Name: "small", Width: 64, Height: 64},
+			{Name: "medium", Width: 256, Height: 256},
+		},
+	}
+
+	// Documents are images only, so their metadata is always stripped by
+	// re-encoding
+	DocumentPolicy = Policy{
+		Prefix:       "documents",
+		MaxBytes:     10 << 20,
+		Types:        []string{TypeJPEG, TypePNG},
+		MaxPixels:    40_000_000,
+		MaxDimension: 4096,
+		Private:      true,
+	}
+)
+
+// allows reports whether the policy accepts a content type
+func (p Policy) allows(contentType string) bool {
+	for _, t := range p.Types {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// Variant is one generated size of an uploaded image
+type Variant struct {
+	Key    string `json:"-" bson:"key"`
+	URL    string `json:"url" bson:"url"`
+	Width  int    `json:"width" bson:"width"`
+	Height int    `json:"height" bson:"height"`
+}
+
+// Asset is an uploaded file and the sizes generated from it
+type Asset struct {
+	Key         string             `json:"-" bson:"key"`
+	URL         string             `json:"url" bson:"url"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	Width       int                `json:"width,omitempty" bson:"width,omitempty"`
+	Height      int                `json:"height,omitempty" bson:"height,omitempty"`
+	Variants    map[string]Variant `json:"variants,omitempty" bson:"variants,omitempty"`
+}
+
+// Keys lists every stored file the asset is made of
+func (a *Asset) Keys() []string {
+	keys := []string{a.Key}
+	for _, v := range a.Variants {
+		keys = append(keys, v.Key)
+	}
+	return keys
+}
+
+// Uploader validates and processes files and puts them in storage
+type Uploader struct {
+	storage Storage
+}
+
+// NewUploader creates an uploader backed by storage
+func NewUploader(storage Storage) *Uploader {
+	return &Uploader{storage: storage}
+}
+
+// Upload reads a file, checks it against the policy and stores it. Images
+// are re-encoded, which drops their EXIF metadata once its orientation has
+// been applied, and scaled to each of the policy's sizes.
+func (u *Uploader) Upload(ctx context.Context, policy Policy, r io.Reader) (*Asset, error) {
+	data, err := io.ReadAll(io.LimitReader(r, policy.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > policy.MaxBytes {
+		return nil, ErrTooLarge
+	}
+	if len(data) == 0 {
+		return nil, ErrNoFile
+	}
+
+	contentType := http.DetectContentType(data)
+	if !policy.allows(contentType) {
+		return nil, ErrUnsupportedType
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	base := policy.Prefix + "/" + id
+	if policy.Private {
+		base = privatePrefix + base
+	}
+
+	processed, err := processImage(data, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &Asset{
+		Key:         base + processed.ext,
+		ContentType: processed.contentType,
+		Size:        int64(len(processed.original.data)),
+		Width:       processed.original.width,
+		Height:      processed.original.height,
+	}
+	asset.URL, err = u.storage.Put(ctx, asset.Key, processed.original.data, processed.contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(processed.variants) > 0 {
+		asset.Variants = make(map[string]Variant, len(processed.variants))
+	}
+	for name, img := range processed.variants {
+		variant := Variant{Key: base + "_" + name + processed.ext, Width: img.width, Height: img.height}
+		variant.URL, err = u.storage.Put(ctx, variant.Key, img.data, processed.contentType)
+		if err != nil {
+			u.Delete(ctx, asset)
+			return nil, err
+		}
+		asset.Variants[name] = variant
+	}
+	return asset, nil
+}
+
+// Open reads back the original file of an asset
+func (u *Uploader) Open(ctx context.Context, asset *Asset) (io.ReadCloser, error) {
+	if asset == nil {
+		return nil, ErrNotFound
+	}
+	return u.storage.Open(ctx, asset.Key)
+}
+
+// Delete removes every file an asset is made of
+func (u *Uploader) Delete(ctx context.Context, asset *Asset) error {
+	if asset == nil {
+		return nil
+	}
+	var firstErr error
+	for _, key := range asset.Keys() {
+		if err := u.storage.Delete(ctx, key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ReadFile limits the request body to maxBytes plus room for the rest of the
+// form, parses it as multipart and returns the named file's contents
+func ReadFile(w http.ResponseWriter, r *http.Request, field string, maxBytes int64) (io.Reader, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1<<20)
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrTooLarge
+		}
+		return nil, ErrNoFile
+	}
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, ErrNoFile
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// IsMultipart reports whether the request carries a multipart form
+func IsMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// validKey accepts the slash-separated lower-case keys the uploader generates
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '/' || c == '.' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrivate reports whether a key names a private file
+func isPrivate(key string) bool {
+	return strings.HasPrefix(key, privatePrefix)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
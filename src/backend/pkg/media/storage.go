@@ -0,0 +1,29 @@
+package media
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rentalflow/rentalflow/pkg/config"
+)
+
+// NewStorage creates the storage the configuration selects
+func NewStorage(cfg *config.Config) (Storage, error) {
+	switch cfg.Media.Storage {
+	case "", "local":
+		return NewLocalStorage(cfg.Media.LocalDir, cfg.Media.PublicURL)
+	case "s3":
+		return NewS3Storage(cfg.Media.S3)
+	case "cloudinary":
+		return NewCloudinaryStorage(cfg.Cloudinary)
+	}
+	return nil, fmt.Errorf("media: unknown storage %q", cfg.Media.Storage)
+}
+
+// Mount serves local files under prefix on mux. Other storage serves its own
+// files, so nothing is mounted for it.
+func Mount(mux *http.ServeMux, prefix string, storage Storage) {
+	if local, ok := storage.(*LocalStorage); ok {
+		mux.Handle(prefix, http.StripPrefix(prefix, local.Handler()))
+	}
+}
@@ -78,6 +78,65 @@ func (b *MessageBroker) Subscribe(queueName string, handler func([]byte) error)
 	return nil
 }
 
+// SubscribeRouted registers a consumer for a specific queue whose handler also
+// receives each message's routing key, for queues bound with wildcards
+func (b *MessageBroker) SubscribeRouted(queueName string, handler func(routingKey string, body []byte) error) error {
+	msgs, err := b.channel.Consume(
+		queueName, // queue
+		"",        // consumer
+		true,      // auto-ack
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register a consumer: %w", err)
+	}
+
+	go func() {
+		for d := range msgs {
+			if err := handler(d.RoutingKey, d.Body); err != nil {
+				fmt.Printf("Error handling %s message from %s: %v\n", d.RoutingKey, queueName, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// SubscribeAcked registers a consumer for a specific queue that acknowledges
+// each message only once the handler has succeeded. A message that fails is
+// requeued once, then rejected, which moves it to the queue's dead-letter
+// queue if it has one.
+func (b *MessageBroker) SubscribeAcked(queueName string, handler func([]byte) error) error {
+	msgs, err := b.channel.Consume(
+		queueName, // queue
+		"",        // consumer
+		false,     // auto-ack
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register a consumer: %w", err)
+	}
+
+	go func() {
+		for d := range msgs {
+			if err := handler(d.Body); err != nil {
+				fmt.Printf("Error handling message from %s (redelivered: %v): %v\n", queueName, d.Redelivered, err)
+				d.Nack(false, !d.Redelivered)
+				continue
+			}
+			d.Ack(false)
+		}
+	}()
+
+	return nil
+}
+
 // DeclareQueue ensures a queue exists
 func (b *MessageBroker) DeclareQueue(name string) (amqp.Queue, error) {
 	return b.channel.QueueDeclare(
@@ -90,6 +149,26 @@ func (b *MessageBroker) DeclareQueue(name string) (amqp.Queue, error) {
 	)
 }
 
+// DeclareQueueWithDeadLetter ensures a queue exists along with a queue named
+// after it with a ".dead" suffix, where the messages it rejects are kept
+func (b *MessageBroker) DeclareQueueWithDeadLetter(name string) (amqp.Queue, error) {
+	dead := name + ".dead"
+	if _, err := b.DeclareQueue(dead); err != nil {
+		return amqp.Queue{}, err
+	}
+	return b.channel.QueueDeclare(
+		name,  // name
+		true,  // durable
+		false, // delete when unused
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": dead,
+		},
+	)
+}
+
 // DeclareExchange ensures an exchange exists
 func (b *MessageBroker) DeclareExchange(name, kind string) error {
 	return b.channel.ExchangeDeclare(
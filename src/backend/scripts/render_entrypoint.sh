@@ -71,6 +71,7 @@ echo "Starting Inventory Service on :$INVENTORY_PORT..."
 export RENTALFLOW_HTTP_PORT=$INVENTORY_PORT
 export RENTALFLOW_GRPC_PORT=50052
 export RENTALFLOW_SERVICE_NAME=inventory-service
+export RENTALFLOW_SERVICES_AUTH=localhost:$AUTH_PORT
 ./inventory-service &
 PID_INVENTORY=$!
 
@@ -79,6 +80,9 @@ echo "Starting Booking Service on :$BOOKING_PORT..."
 export RENTALFLOW_HTTP_PORT=$BOOKING_PORT
 export RENTALFLOW_GRPC_PORT=50053
 export RENTALFLOW_SERVICE_NAME=booking-service
+export RENTALFLOW_SERVICES_INVENTORY=localhost:$INVENTORY_PORT
+export RENTALFLOW_SERVICES_PAYMENT=localhost:$PAYMENT_PORT
+export RENTALFLOW_SERVICES_AUTH=localhost:$AUTH_PORT
 ./booking-service &
 PID_BOOKING=$!
 
@@ -40,12 +40,16 @@ func (g *Gateway) RegisterRoutes(r *mux.Router) {
 	r.HandleFunc("/api/auth/logout", g.forwardToAuth).Methods("POST")
 	r.HandleFunc("/api/auth/profile", g.forwardToAuth).Methods("GET", "PUT")
 	r.HandleFunc("/api/auth/avatar", g.forwardToAuth).Methods("POST")
+	r.HandleFunc("/api/auth/documents", g.forwardToAuth).Methods("POST")
+	r.HandleFunc("/api/auth/documents/{id}", g.forwardToAuth).Methods("GET", "HEAD")
 	r.HandleFunc("/api/auth/change-password", g.forwardToAuth).Methods("POST")
 	r.HandleFunc("/api/users", g.forwardToAuth).Methods("GET")
 
 	// Inventory service routes
 	r.PathPrefix("/api/inventory").HandlerFunc(g.forwardToInventory)
 	r.PathPrefix("/api/items").HandlerFunc(g.forwardToInventory)
+	r.PathPrefix("/api/categories").HandlerFunc(g.forwardToInventory)
+	r.PathPrefix("/api/maintenance").HandlerFunc(g.forwardToInventory)
 
 	// Booking service routes
 	r.PathPrefix("/api/bookings").HandlerFunc(g.forwardToBooking)
@@ -59,6 +63,11 @@ func (g *Gateway) RegisterRoutes(r *mux.Router) {
 
 	// Review service routes
 	r.PathPrefix("/api/reviews").HandlerFunc(g.forwardToReview)
+
+	// Uploaded media kept in local storage is served by the service that owns
+	// it. Identity documents are private and only served by /api/auth/documents/{id}.
+	r.PathPrefix("/media/items/").HandlerFunc(g.forwardToInventory).Methods("GET", "HEAD")
+	r.PathPrefix("/media/avatars/").HandlerFunc(g.forwardToAuth).Methods("GET", "HEAD")
 }
 
 func (g *Gateway) Health(w http.ResponseWriter, r *http.Request) {
@@ -17,6 +17,7 @@ import (
 	"github.com/rentalflow/auth-service/internal/token"
 	"github.com/rentalflow/rentalflow/pkg/database"
 	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rentalflow/rentalflow/pkg/media"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -61,7 +62,15 @@ func main() {
 		cfg.JWT.Issuer,
 	)
 	passService := token.NewPasswordService(cfg.BCryptCost)
-	authService := service.NewAuthService(userRepo, docRepo, jwtService, passService)
+
+	// Initialize media storage for avatars and identity documents
+	storage, err := media.NewStorage(cfg.Config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize media storage")
+	}
+	uploader := media.NewUploader(storage)
+
+	authService := service.NewAuthService(userRepo, docRepo, jwtService, passService, uploader)
 
 	// Initialize gRPC handler
 	authHandler := handler.NewAuthHandler(authService)
@@ -103,6 +112,7 @@ func main() {
 	httpAddr := fmt.Sprintf(":%d", cfg.HTTPPort)
 	mux := http.NewServeMux()
 	httpHandler.RegisterRoutes(mux)
+	media.Mount(mux, "/media/", storage)
 
 	httpServer := &http.Server{
 		Addr:    httpAddr,
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rentalflow/rentalflow/pkg/media"
 )
 
 // UserRole represents the role of a user
@@ -43,6 +44,7 @@ type User struct {
 	Phone                 string             `json:"phone" bson:"phone"`
 	Bio                   string             `json:"bio" bson:"bio"`
 	AvatarURL             string             `json:"avatar_url" bson:"avatar_url"`
+	Avatar                *media.Asset       `json:"avatar,omitempty" bson:"avatar,omitempty"`
 	Role                  UserRole           `json:"role" bson:"role"`
 	IdentityVerified      bool               `json:"identity_verified" bson:"identity_verified"`
 	VerificationStatus    VerificationStatus `json:"verification_status" bson:"verification_status"`
@@ -119,11 +121,15 @@ func (u *User) ClearRefreshToken() {
 
 // IdentityDocument represents an identity document
 type IdentityDocument struct {
-	ID           uuid.UUID
-	UserID       uuid.UUID
-	DocumentType string
-	DocumentURL  string
-	UploadedAt   time.Time
+	ID           uuid.UUID `json:"id" bson:"_id"`
+	UserID       uuid.UUID `json:"user_id" bson:"user_id"`
+	DocumentType string    `json:"document_type" bson:"document_type"`
+	DocumentURL  string    `json:"document_url" bson:"document_url"`
+
+	// File is set for documents uploaded to media storage
+	File *media.Asset `json:"-" bson:"file,omitempty"`
+
+	UploadedAt time.Time `json:"uploaded_at" bson:"uploaded_at"`
 }
 
 // DocumentType constants
@@ -133,6 +139,15 @@ const (
 	DocTypePassport      = "passport"
 )
 
+// IsValidDocumentType checks if the document type is one we accept
+func IsValidDocumentType(docType string) bool {
+	switch docType {
+	case DocTypeDriverLicense, DocTypeNationalID, DocTypePassport:
+		return true
+	}
+	return false
+}
+
 // NewIdentityDocument creates a new identity document
 func NewIdentityDocument(userID uuid.UUID, docType, docURL string) *IdentityDocument {
 	return &IdentityDocument{
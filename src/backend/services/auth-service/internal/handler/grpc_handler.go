@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 
 	"github.com/google/uuid"
 	"github.com/rentalflow/auth-service/internal/domain"
 	"github.com/rentalflow/auth-service/internal/service"
+	"github.com/rentalflow/rentalflow/pkg/media"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -257,13 +259,11 @@ func (h *AuthHandler) UploadIdentityDocument(ctx context.Context, req *UploadDoc
 		return nil, status.Error(codes.InvalidArgument, "invalid user_id format")
 	}
 
-	// In a real implementation, we would:
-	// 1. Upload the document bytes to S3/MinIO
-	// 2. Get the URL back
-	// For now, we'll assume the document is already uploaded
-	docURL := "https://storage.example.com/docs/" + uuid.New().String()
+	if len(req.DocumentData) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "document_data is required")
+	}
 
-	doc, err := h.authService.UploadDocument(ctx, userID, req.DocumentType, docURL)
+	doc, err := h.authService.UploadDocument(ctx, userID, req.DocumentType, bytes.NewReader(req.DocumentData))
 	if err != nil {
 		return nil, toGRPCError(err)
 	}
@@ -339,7 +339,8 @@ func toGRPCError(err error) error {
 		return status.Error(codes.Unauthenticated, err.Error())
 	case domain.ErrForbidden:
 		return status.Error(codes.PermissionDenied, err.Error())
-	case domain.ErrInvalidRole, domain.ErrInvalidDocumentType:
+	case domain.ErrInvalidRole, domain.ErrInvalidDocumentType,
+		media.ErrInvalidImage, media.ErrNoFile, media.ErrTooLarge, media.ErrUnsupportedType:
 		return status.Error(codes.InvalidArgument, err.Error())
 	default:
 		return status.Error(codes.Internal, "internal server error")
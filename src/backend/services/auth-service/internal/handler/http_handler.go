@@ -2,11 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/google/uuid"
 	"github.com/rentalflow/auth-service/internal/domain"
 	"github.com/rentalflow/auth-service/internal/service"
+	"github.com/rentalflow/rentalflow/pkg/media"
 )
 
 // HTTPHandler provides REST endpoints for testing
@@ -28,6 +30,8 @@ func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/auth/logout", h.Logout)
 	mux.HandleFunc("/api/auth/profile", h.ProfileHandler)
 	mux.HandleFunc("/api/auth/avatar", h.UpdateAvatar)
+	mux.HandleFunc("/api/auth/documents", h.UploadDocument)
+	mux.HandleFunc("/api/auth/documents/{id}", h.GetDocument)
 	mux.HandleFunc("/api/auth/change-password", h.ChangePassword)
 	mux.HandleFunc("/api/auth/validate", h.ValidateToken)
 	mux.HandleFunc("/api/users", h.ListUsers)
@@ -274,6 +278,11 @@ func (h *HTTPHandler) UpdateAvatar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if media.IsMultipart(r) {
+		h.UploadAvatar(w, r)
+		return
+	}
+
 	var req struct {
 		UserID    string `json:"user_id"`
 		AvatarURL string `json:"avatar_url"`
@@ -309,6 +318,102 @@ func (h *HTTPHandler) UpdateAvatar(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// UploadAvatar takes a multipart form with the user_id and an "avatar" file
+func (h *HTTPHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	file, err := media.ReadFile(w, r, "avatar", media.AvatarPolicy.MaxBytes)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	uid, err := uuid.Parse(r.FormValue("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id format", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.authService.UploadAvatar(r.Context(), uid, file)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":         user.ID.String(),
+		"avatar_url": user.AvatarURL,
+		"avatar":     user.Avatar,
+		"success":    true,
+	})
+}
+
+// UploadDocument takes a multipart form with the user_id, document_type and a
+// "document" file
+func (h *HTTPHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	file, err := media.ReadFile(w, r, "document", media.DocumentPolicy.MaxBytes)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	uid, err := uuid.Parse(r.FormValue("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id format", http.StatusBadRequest)
+		return
+	}
+
+	doc, err := h.authService.UploadDocument(r.Context(), uid, r.FormValue("document_type"), file)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(doc)
+}
+
+// GetDocument streams an identity document to its owner or an admin, as
+// identified by the bearer token. Responses must never be cached.
+func (h *HTTPHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid document id format", http.StatusBadRequest)
+		return
+	}
+
+	claims, err := h.authService.ValidateToken(r.Context(), h.extractToken(r))
+	if err != nil {
+		h.handleError(w, domain.ErrUnauthorized)
+		return
+	}
+
+	doc, file, err := h.authService.OpenDocument(r.Context(), id, claims)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", doc.File.ContentType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r.Method == http.MethodHead {
+		return
+	}
+	io.Copy(w, file)
+}
+
 // ChangePassword changes user password
 func (h *HTTPHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -416,16 +521,20 @@ func (h *HTTPHandler) handleError(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 
 	switch err {
-	case domain.ErrUserNotFound:
+	case domain.ErrUserNotFound, domain.ErrDocumentNotFound:
 		w.WriteHeader(http.StatusNotFound)
 	case domain.ErrUserAlreadyExists:
 		w.WriteHeader(http.StatusConflict)
-	case domain.ErrInvalidCredentials:
+	case domain.ErrInvalidCredentials, domain.ErrUnauthorized:
 		w.WriteHeader(http.StatusUnauthorized)
 	case domain.ErrForbidden:
 		w.WriteHeader(http.StatusForbidden)
-	case domain.ErrInvalidRole, domain.ErrInvalidDocumentType:
+	case domain.ErrInvalidRole, domain.ErrInvalidDocumentType, media.ErrInvalidImage, media.ErrNoFile:
 		w.WriteHeader(http.StatusBadRequest)
+	case media.ErrTooLarge:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case media.ErrUnsupportedType:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
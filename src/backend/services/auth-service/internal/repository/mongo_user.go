@@ -71,6 +71,9 @@ func (r *MongoUserRepository) Update(ctx context.Context, user *domain.User) err
 			"first_name":               user.FirstName,
 			"last_name":                user.LastName,
 			"phone":                    user.Phone,
+			"bio":                      user.Bio,
+			"avatar_url":               user.AvatarURL,
+			"avatar":                   user.Avatar,
 			"role":                     user.Role,
 			"identity_verified":        user.IdentityVerified,
 			"verification_status":      user.VerificationStatus,
@@ -2,12 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rentalflow/auth-service/internal/domain"
 	"github.com/rentalflow/auth-service/internal/repository"
 	"github.com/rentalflow/auth-service/internal/token"
+	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rentalflow/rentalflow/pkg/media"
 )
 
 // AuthService handles authentication business logic
@@ -16,6 +20,7 @@ type AuthService struct {
 	docRepo     repository.DocumentRepository
 	jwtService  *token.JWTService
 	passService *token.PasswordService
+	uploader    *media.Uploader
 }
 
 // NewAuthService creates a new auth service
@@ -24,12 +29,14 @@ func NewAuthService(
 	docRepo repository.DocumentRepository,
 	jwtService *token.JWTService,
 	passService *token.PasswordService,
+	uploader *media.Uploader,
 ) *AuthService {
 	return &AuthService{
 		userRepo:    userRepo,
 		docRepo:     docRepo,
 		jwtService:  jwtService,
 		passService: passService,
+		uploader:    uploader,
 	}
 }
 
@@ -236,23 +243,61 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, first
 	return user, nil
 }
 
-// UpdateAvatar updates a user's avatar URL
+// UpdateAvatar points a user's avatar at an image hosted elsewhere
 func (s *AuthService) UpdateAvatar(ctx context.Context, userID uuid.UUID, avatarURL string) (*domain.User, error) {
+	return s.setAvatar(ctx, userID, avatarURL, nil)
+}
+
+// UploadAvatar stores an uploaded image, with its smaller sizes, as the
+// user's avatar
+func (s *AuthService) UploadAvatar(ctx context.Context, userID uuid.UUID, file io.Reader) (*domain.User, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	asset, err := s.uploader.Upload(ctx, media.AvatarPolicy, file)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.setAvatar(ctx, userID, asset.URL, asset)
+	if err != nil {
+		s.deleteMedia(ctx, asset)
+		return nil, err
+	}
+	return user, nil
+}
+
+// setAvatar replaces the user's avatar, deleting the old one's files if it
+// was uploaded here
+func (s *AuthService) setAvatar(ctx context.Context, userID uuid.UUID, avatarURL string, asset *media.Asset) (*domain.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	previous := user.Avatar
 	user.AvatarURL = avatarURL
+	user.Avatar = asset
 	user.UpdatedAt = time.Now()
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return nil, err
 	}
 
+	s.deleteMedia(ctx, previous)
 	return user, nil
 }
 
+// deleteMedia removes an upload's files. Failures only leave orphaned files
+// behind, so they are logged rather than returned.
+func (s *AuthService) deleteMedia(ctx context.Context, asset *media.Asset) {
+	if err := s.uploader.Delete(ctx, asset); err != nil {
+		log := logger.NewLogger("auth")
+		log.Warn().Err(err).Str("key", asset.Key).Msg("Failed to delete media files")
+	}
+}
+
 // ChangePassword changes a user's password
 func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, currentPassword, newPassword string) error {
 	// Get user
@@ -284,30 +329,57 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, curr
 	return s.userRepo.Update(ctx, user)
 }
 
-// UploadDocument uploads an identity document
-func (s *AuthService) UploadDocument(ctx context.Context, userID uuid.UUID, docType, docURL string) (*domain.IdentityDocument, error) {
-	// Validate document type
-	if docType != domain.DocTypeDriverLicense &&
-		docType != domain.DocTypeNationalID &&
-		docType != domain.DocTypePassport {
+// UploadDocument stores a scan or photo of an identity document and
+// records it for verification
+func (s *AuthService) UploadDocument(ctx context.Context, userID uuid.UUID, docType string, file io.Reader) (*domain.IdentityDocument, error) {
+	if !domain.IsValidDocumentType(docType) {
 		return nil, domain.ErrInvalidDocumentType
 	}
 
-	// Verify user exists
-	_, err := s.userRepo.GetByID(ctx, userID)
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	asset, err := s.uploader.Upload(ctx, media.DocumentPolicy, file)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create document
-	doc := domain.NewIdentityDocument(userID, docType, docURL)
+	doc := domain.NewIdentityDocument(userID, docType, "")
+	doc.DocumentURL = "/api/auth/documents/" + doc.ID.String()
+	doc.File = asset
 	if err := s.docRepo.Create(ctx, doc); err != nil {
+		s.deleteMedia(ctx, asset)
 		return nil, err
 	}
 
 	return doc, nil
 }
 
+// OpenDocument reads back an identity document's file. Documents are kept in
+// private storage, so only their owner and admins may see them.
+func (s *AuthService) OpenDocument(ctx context.Context, id uuid.UUID, claims *token.Claims) (*domain.IdentityDocument, io.ReadCloser, error) {
+	doc, err := s.docRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.UserID != doc.UserID.String() && domain.UserRole(claims.Role) != domain.RoleAdmin {
+		return nil, nil, domain.ErrForbidden
+	}
+	if doc.File == nil {
+		return nil, nil, domain.ErrDocumentNotFound
+	}
+
+	file, err := s.uploader.Open(ctx, doc.File)
+	if errors.Is(err, media.ErrNotFound) {
+		return nil, nil, domain.ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, file, nil
+}
+
 // GetVerificationStatus gets a user's verification status
 func (s *AuthService) GetVerificationStatus(ctx context.Context, userID uuid.UUID) (domain.VerificationStatus, []*domain.IdentityDocument, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
@@ -9,6 +9,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/rentalflow/booking-service/internal/clients"
 	"github.com/rentalflow/booking-service/internal/config"
 	"github.com/rentalflow/booking-service/internal/handler"
 	"github.com/rentalflow/booking-service/internal/repository"
@@ -58,8 +59,45 @@ func main() {
 
 	// Initialize repositories
 	bookingRepo := repository.NewMongoBookingRepository(client.DB)
-	bookingService := service.NewBookingService(bookingRepo, broker)
-	httpHandler := handler.NewHTTPHandler(bookingService)
+	sagaRepo := repository.NewMongoSagaRepository(client.DB)
+	agreementRepo := repository.NewMongoAgreementRepository(client.DB)
+	disputeRepo := repository.NewMongoDisputeRepository(client.DB)
+
+	if err := bookingRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create booking indexes")
+	}
+	if err := sagaRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create saga indexes")
+	}
+	if err := agreementRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create agreement indexes")
+	}
+
+	// Initialize clients for downstream services
+	inventoryClient := clients.NewInventoryClient(cfg.InventoryServiceURL)
+	paymentClient := clients.NewPaymentClient(cfg.PaymentServiceURL, cfg.Services.Token)
+	userClient := clients.NewUserClient(cfg.AuthServiceURL)
+
+	sagas := service.NewSagaOrchestrator(sagaRepo, bookingRepo, inventoryClient, paymentClient, cfg.SagaTimeout, cfg.SagaStepTimeout)
+	quoteService := service.NewQuoteService(inventoryClient, cfg.QuoteSecret, cfg.ServiceFeePercentage, cfg.QuoteTTL)
+	disputeService := service.NewDisputeService(bookingRepo, disputeRepo, paymentClient, userClient, broker)
+	bookingService := service.NewBookingService(bookingRepo, inventoryClient, paymentClient, quoteService, sagas, disputeService, broker, cfg.OwnerResponseWindow, cfg.PaymentWindow)
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Resume sagas left incomplete by a previous run
+	go sagas.Recover(backgroundCtx, cfg.SagaRecoveryInterval)
+
+	// Expire bookings whose owner or renter let a deadline pass
+	expiry := service.NewExpiryScheduler(bookingRepo, inventoryClient, paymentClient, broker)
+	go expiry.Run(backgroundCtx, cfg.ExpiryCheckInterval)
+
+	// Settle accepted modifications whose price difference failed to go through
+	go bookingService.SettleModifications(backgroundCtx, cfg.ExpiryCheckInterval)
+
+	agreementService := service.NewAgreementService(bookingRepo, agreementRepo, inventoryClient, userClient)
+	httpHandler := handler.NewHTTPHandler(bookingService, agreementService, disputeService)
 
 	httpAddr := fmt.Sprintf(":%d", cfg.HTTPPort)
 	mux := http.NewServeMux()
@@ -3,8 +3,10 @@ module github.com/rentalflow/booking-service
 go 1.24.0
 
 require (
+	github.com/go-pdf/fpdf v0.9.0
 	github.com/google/uuid v1.5.0
 	github.com/rentalflow/rentalflow v0.0.0
+	github.com/rs/zerolog v1.31.0
 	go.mongodb.org/mongo-driver v1.17.6
 )
 
@@ -20,7 +22,6 @@ require (
 	github.com/montanaflynn/stats v0.7.1 // indirect
 	github.com/pelletier/go-toml/v2 v2.1.0 // indirect
 	github.com/rabbitmq/amqp091-go v1.10.0 // indirect
-	github.com/rs/zerolog v1.31.0 // indirect
 	github.com/sagikazarmark/locafero v0.4.0 // indirect
 	github.com/sagikazarmark/slog-shim v0.1.0 // indirect
 	github.com/sourcegraph/conc v0.3.0 // indirect
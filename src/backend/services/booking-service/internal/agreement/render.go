@@ -0,0 +1,173 @@
+// Package agreement renders rental agreements as HTML and PDF documents
+package agreement
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/rentalflow/booking-service/internal/domain"
+)
+
+const dateLayout = "January 2, 2006"
+
+var htmlTemplate = template.Must(template.New("agreement").Funcs(template.FuncMap{
+	"date":    formatDate,
+	"money":   money,
+	"clauses": clauses,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Rental Agreement {{.BookingNumber}}</title>
+</head>
+<body>
+<h1>Rental Agreement</h1>
+<p>Booking {{.BookingNumber}}</p>
+
+<h2>Parties</h2>
+<p><strong>Owner:</strong> {{.Owner.Name}}, {{.Owner.Email}}{{if .Owner.Phone}}, {{.Owner.Phone}}{{end}}</p>
+<p><strong>Renter:</strong> {{.Renter.Name}}, {{.Renter.Email}}{{if .Renter.Phone}}, {{.Renter.Phone}}{{end}}</p>
+
+<h2>Item</h2>
+<p><strong>{{.Item.Title}}</strong></p>
+{{if .Item.Description}}<p>{{.Item.Description}}</p>{{end}}
+{{if or .Item.Address .Item.City}}<p>Pickup location: {{.Item.Address}}{{if and .Item.Address .Item.City}}, {{end}}{{.Item.City}}</p>{{end}}
+
+<h2>Rental Period</h2>
+<p>From {{date .Terms.StartDate}} to {{date .Terms.EndDate}} ({{.Terms.TotalDays}} days)</p>
+
+<h2>Charges</h2>
+<table>
+{{range .Terms.PriceBreakdown}}<tr><td>{{.Quantity}} {{.Description}} at {{money .UnitPrice}}</td><td>{{money .Amount}}</td></tr>
+{{end}}<tr><td>Service fee</td><td>{{money .Terms.ServiceFee}}</td></tr>
+<tr><td>Security deposit (refundable)</td><td>{{money .Terms.SecurityDeposit}}</td></tr>
+<tr><td><strong>Total</strong></td><td><strong>{{money .Terms.TotalAmount}}</strong></td></tr>
+</table>
+
+<h2>Terms</h2>
+<ol>
+{{range clauses .}}<li>{{.}}</li>
+{{end}}</ol>
+</body>
+</html>
+`))
+
+// RenderHTML renders the agreement document. The output only depends on the
+// terms, so the same terms always hash the same.
+func RenderHTML(terms domain.AgreementTerms) (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, terms); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderPDF renders the agreement document with its signatures and content hash
+func RenderPDF(a *domain.Agreement) ([]byte, error) {
+	terms := a.Terms
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	heading := func(text string) {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, tr(text), "", 1, "", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+	}
+	line := func(text string) {
+		pdf.MultiCell(0, 5, tr(text), "", "", false)
+	}
+	row := func(label, amount string) {
+		pdf.CellFormat(130, 6, tr(label), "", 0, "", false, 0, "")
+		pdf.CellFormat(0, 6, amount, "", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Rental Agreement", "", 1, "", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	line("Booking " + terms.BookingNumber)
+
+	heading("Parties")
+	line("Owner: " + party(terms.Owner))
+	line("Renter: " + party(terms.Renter))
+
+	heading("Item")
+	line(terms.Item.Title)
+	if terms.Item.Description != "" {
+		line(terms.Item.Description)
+	}
+	if location := strings.Trim(terms.Item.Address+", "+terms.Item.City, ", "); location != "" {
+		line("Pickup location: " + location)
+	}
+
+	heading("Rental Period")
+	line(fmt.Sprintf("From %s to %s (%d days)",
+		formatDate(terms.Terms.StartDate), formatDate(terms.Terms.EndDate), terms.Terms.TotalDays))
+
+	heading("Charges")
+	for _, l := range terms.Terms.PriceBreakdown {
+		row(fmt.Sprintf("%d %s at %s", l.Quantity, l.Description, money(l.UnitPrice)), money(l.Amount))
+	}
+	row("Service fee", money(terms.Terms.ServiceFee))
+	row("Security deposit (refundable)", money(terms.Terms.SecurityDeposit))
+	pdf.SetFont("Helvetica", "B", 10)
+	row("Total", money(terms.Terms.TotalAmount))
+	pdf.SetFont("Helvetica", "", 10)
+
+	heading("Terms")
+	for i, clause := range clauses(terms) {
+		line(fmt.Sprintf("%d. %s", i+1, clause))
+	}
+
+	heading("Signatures")
+	if len(a.Signatures) == 0 {
+		line("Not yet signed.")
+	}
+	for _, s := range a.Signatures {
+		line(fmt.Sprintf("Signed by the %s (%s) on %s from %s",
+			s.Role, s.SignerID, s.SignedAt.UTC().Format("2006-01-02 15:04:05 MST"), s.IPAddress))
+	}
+	pdf.Ln(2)
+	pdf.SetFont("Courier", "", 8)
+	line("Document SHA-256: " + a.ContentHash)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clauses are the standard terms every agreement carries
+func clauses(terms domain.AgreementTerms) []string {
+	return []string{
+		"The owner rents the item above to the renter for the rental period at the charges listed.",
+		"The renter will return the item by the end of the rental period in the condition it was received, apart from normal wear.",
+		"The security deposit is held for the rental and returned after the item is checked in, less any agreed cost of damage.",
+		fmt.Sprintf("Cancellations are refunded under the %s cancellation policy.", terms.CancellationPolicy),
+		"Changes to the rental period must be requested through RentalFlow and agreed by the owner, and require this agreement to be signed again.",
+	}
+}
+
+func party(p domain.AgreementParty) string {
+	s := p.Name + ", " + p.Email
+	if p.Phone != "" {
+		s += ", " + p.Phone
+	}
+	return s
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f ETB", v)
+}
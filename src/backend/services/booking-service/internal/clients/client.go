@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StatusError is returned when a downstream service answers with an error status
+type StatusError struct {
+	Service    string
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %d: %s", e.Service, e.StatusCode, e.Message)
+}
+
+// serviceTokenHeader carries the token internal endpoints of other services check
+const serviceTokenHeader = "X-Service-Token"
+
+// serviceTokenTransport adds the service token to every request it sends
+type serviceTokenTransport struct {
+	token string
+	next  http.RoundTripper
+}
+
+func (t serviceTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(serviceTokenHeader, t.token)
+	return t.next.RoundTrip(req)
+}
+
+// doJSON sends body as JSON and decodes the JSON response into out, if given
+func doJSON(ctx context.Context, client *http.Client, service, method, url string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s unavailable: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		msg, _ := io.ReadAll(resp.Body)
+		return &StatusError{Service: service, StatusCode: resp.StatusCode, Message: string(bytes.TrimSpace(msg))}
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,184 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+)
+
+// InventoryClient calls the inventory service HTTP API
+type InventoryClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewInventoryClient creates a new inventory service client
+func NewInventoryClient(baseURL string) *InventoryClient {
+	return &InventoryClient{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Item is the part of an inventory rental item that bookings need
+type Item struct {
+	ID              uuid.UUID `json:"id"`
+	OwnerID         uuid.UUID `json:"owner_id"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	City            string    `json:"city"`
+	Address         string    `json:"address"`
+	DailyRate       float64   `json:"daily_rate"`
+	WeeklyRate      float64   `json:"weekly_rate"`
+	MonthlyRate     float64   `json:"monthly_rate"`
+	SecurityDeposit float64   `json:"security_deposit"`
+	IsActive        bool      `json:"is_active"`
+	AddOns          []AddOn   `json:"add_ons"`
+}
+
+// AddOn is an extra or bundle the owner offers with an item
+type AddOn struct {
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Pricing string    `json:"pricing"`
+	Price   float64   `json:"price"`
+}
+
+// GetItem fetches a rental item with its current rates
+func (c *InventoryClient) GetItem(ctx context.Context, itemID uuid.UUID) (*Item, error) {
+	var item Item
+	url := c.baseURL + "/api/items?id=" + itemID.String()
+	err := doJSON(ctx, c.client, "inventory service", http.MethodGet, url, nil, &item)
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, domain.ErrItemNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
+
+// PriceRental prices renting the item over the date range with inventory's
+// pricing engine, under the item's rates and pricing rules
+func (c *InventoryClient) PriceRental(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time) (*domain.RentalPrice, error) {
+	query := url.Values{
+		"start_date": {startDate.Format(time.RFC3339)},
+		"end_date":   {endDate.Format(time.RFC3339)},
+	}
+	var price domain.RentalPrice
+	endpoint := c.baseURL + "/api/items/" + itemID.String() + "/price?" + query.Encode()
+	err := doJSON(ctx, c.client, "inventory service", http.MethodGet, endpoint, nil, &price)
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, domain.ErrItemNotFound
+		}
+		return nil, err
+	}
+	return &price, nil
+}
+
+// BlockDates reserves the item's date range for a booking, along with the
+// add-ons chosen with it
+func (c *InventoryClient) BlockDates(ctx context.Context, itemID, bookingID uuid.UUID, startDate, endDate time.Time, addOns []domain.SelectedAddOn) error {
+	booked := make([]map[string]interface{}, 0, len(addOns))
+	for _, addOn := range addOns {
+		booked = append(booked, map[string]interface{}{
+			"add_on_id": addOn.AddOnID.String(),
+			"quantity":  addOn.Quantity,
+		})
+	}
+	body := map[string]interface{}{
+		"item_id":    itemID.String(),
+		"booking_id": bookingID.String(),
+		"start_date": startDate.Format(time.RFC3339),
+		"end_date":   endDate.Format(time.RFC3339),
+		"add_ons":    booked,
+	}
+	return c.post(ctx, "/api/availability/block", body, nil)
+}
+
+// ReleaseDates frees whatever dates are held for a booking
+func (c *InventoryClient) ReleaseDates(ctx context.Context, bookingID uuid.UUID) error {
+	body := map[string]string{"booking_id": bookingID.String()}
+	return c.post(ctx, "/api/availability/release", body, nil)
+}
+
+// ConfirmDates marks the dates held for a booking as confirmed
+func (c *InventoryClient) ConfirmDates(ctx context.Context, bookingID uuid.UUID) error {
+	body := map[string]string{"booking_id": bookingID.String()}
+	return c.post(ctx, "/api/availability/confirm", body, nil)
+}
+
+// ValidateBooking checks a rental against the item's rules and reservations,
+// returning the reasons it can't be booked, if any. Dates already held by
+// bookingID, if given, don't count as reserved.
+func (c *InventoryClient) ValidateBooking(ctx context.Context, itemID uuid.UUID, bookingID *uuid.UUID, startDate, endDate time.Time) ([]domain.RuleViolation, error) {
+	body := map[string]string{
+		"item_id":    itemID.String(),
+		"start_date": startDate.Format(time.RFC3339),
+		"end_date":   endDate.Format(time.RFC3339),
+	}
+	if bookingID != nil {
+		body["booking_id"] = bookingID.String()
+	}
+	var resp struct {
+		Valid   bool                   `json:"valid"`
+		Reasons []domain.RuleViolation `json:"reasons"`
+	}
+	if err := c.post(ctx, "/api/availability/validate", body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Reasons, nil
+}
+
+// RescheduleDates moves the dates held for a booking
+func (c *InventoryClient) RescheduleDates(ctx context.Context, bookingID uuid.UUID, startDate, endDate time.Time) error {
+	body := map[string]string{
+		"booking_id": bookingID.String(),
+		"start_date": startDate.Format(time.RFC3339),
+		"end_date":   endDate.Format(time.RFC3339),
+	}
+	return c.post(ctx, "/api/availability/reschedule", body, nil)
+}
+
+// AssignUnit assigns the booking one of the item's units at pickup: unitID
+// if given, otherwise whichever is free. It returns nil for items without units.
+func (c *InventoryClient) AssignUnit(ctx context.Context, bookingID uuid.UUID, unitID *uuid.UUID) (*uuid.UUID, error) {
+	body := map[string]string{"booking_id": bookingID.String()}
+	if unitID != nil {
+		body["unit_id"] = unitID.String()
+	}
+	var resp struct {
+		UnitID *uuid.UUID `json:"unit_id"`
+	}
+	if err := c.post(ctx, "/api/availability/assign", body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.UnitID, nil
+}
+
+func (c *InventoryClient) post(ctx context.Context, path string, body, out interface{}) error {
+	err := doJSON(ctx, c.client, "inventory service", http.MethodPost, c.baseURL+path, body, out)
+	return mapInventoryError(err)
+}
+
+func mapInventoryError(err error) error {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+		if strings.Contains(statusErr.Message, domain.ErrAddOnUnavailable.Error()) {
+			return domain.ErrAddOnUnavailable
+		}
+		return domain.ErrDateConflict
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+)
+
+// PaymentClient calls the payment service HTTP API
+type PaymentClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// InitializedPayment is the part of the payment service response the booking needs
+type InitializedPayment struct {
+	PaymentID   uuid.UUID `json:"payment_id"`
+	CheckoutURL string    `json:"checkout_url"`
+	Status      string    `json:"status"`
+}
+
+// NewPaymentClient creates a new payment service client, authenticated to its
+// internal endpoints with the service token
+func NewPaymentClient(baseURL, serviceToken string) *PaymentClient {
+	return &PaymentClient{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: serviceTokenTransport{token: serviceToken, next: http.DefaultTransport},
+		},
+	}
+}
+
+// InitializePayment opens a payment for the booking, of which securityDeposit
+// is held until check-in. charges, if given, itemise the rest of the amount on
+// the payment. The payment service reuses an open payment for the same booking
+// and amounts, so retries are safe.
+func (c *PaymentClient) InitializePayment(ctx context.Context, bookingID, userID uuid.UUID, amount, securityDeposit float64, method string,
+	charges *domain.Charges) (*InitializedPayment, error) {
+	body := map[string]interface{}{
+		"booking_id":       bookingID.String(),
+		"user_id":          userID.String(),
+		"amount":           amount,
+		"security_deposit": securityDeposit,
+		"method":           method,
+	}
+	if charges != nil {
+		body["breakdown"] = charges
+	}
+
+	var payment InitializedPayment
+	if err := doJSON(ctx, c.client, "payment service", http.MethodPost, c.baseURL+"/api/payments/initialize", body, &payment); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// VoidBookingPayments cancels open payments for a booking and refunds completed ones
+func (c *PaymentClient) VoidBookingPayments(ctx context.Context, bookingID uuid.UUID) error {
+	body := map[string]string{"booking_id": bookingID.String()}
+	return doJSON(ctx, c.client, "payment service", http.MethodPost, c.baseURL+"/internal/payments/void", body, nil)
+}
+
+// RefundPayment refunds part of a completed payment
+func (c *PaymentClient) RefundPayment(ctx context.Context, paymentID uuid.UUID, amount float64) error {
+	body := map[string]interface{}{
+		"payment_id": paymentID.String(),
+		"amount":     amount,
+	}
+	return doJSON(ctx, c.client, "payment service", http.MethodPost, c.baseURL+"/api/payments/refund", body, nil)
+}
+
+// IsBookingPaid reports whether any payment for the booking has completed
+func (c *PaymentClient) IsBookingPaid(ctx context.Context, bookingID uuid.UUID) (bool, error) {
+	var resp struct {
+		Payments []struct {
+			Status string `json:"status"`
+		} `json:"payments"`
+	}
+	url := c.baseURL + "/api/payments/booking?booking_id=" + bookingID.String()
+	if err := doJSON(ctx, c.client, "payment service", http.MethodGet, url, nil, &resp); err != nil {
+		return false, err
+	}
+
+	for _, payment := range resp.Payments {
+		if payment.Status == domain.PaymentCompleted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DepositSettlement is the payment service's record of a settled deposit
+type DepositSettlement struct {
+	Settled        bool    `json:"settled"`
+	DepositStatus  string  `json:"deposit_status"`
+	CapturedAmount float64 `json:"captured_amount"`
+	ReleasedAmount float64 `json:"released_amount"`
+}
+
+// SettleDeposit captures captureAmount of the booking's held deposit for the
+// owner and releases the rest to the renter. Settled is false if no deposit
+// was held, either because it was settled before, in which case the other
+// fields describe that settlement, or because there never was one.
+func (c *PaymentClient) SettleDeposit(ctx context.Context, bookingID uuid.UUID, captureAmount float64) (*DepositSettlement, error) {
+	body := map[string]interface{}{
+		"booking_id":     bookingID.String(),
+		"capture_amount": captureAmount,
+	}
+
+	var settlement DepositSettlement
+	if err := doJSON(ctx, c.client, "payment service", http.MethodPost, c.baseURL+"/internal/payments/deposit/settle", body, &settlement); err != nil {
+		return nil, err
+	}
+	return &settlement, nil
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/rentalflow/rentalflow/pkg/config"
 )
 
@@ -8,6 +10,24 @@ import (
 type Config struct {
 	*config.Config
 	ServiceFeePercentage float64
+	InventoryServiceURL  string
+	PaymentServiceURL    string
+	AuthServiceURL       string
+
+	// Quotes are signed with QuoteSecret and valid for QuoteTTL
+	QuoteSecret string
+	QuoteTTL    time.Duration
+
+	// Owners must answer a request within OwnerResponseWindow and renters must
+	// pay a confirmed booking within PaymentWindow, or the booking expires
+	OwnerResponseWindow time.Duration
+	PaymentWindow       time.Duration
+	ExpiryCheckInterval time.Duration
+
+	// Booking saga settings
+	SagaTimeout          time.Duration
+	SagaStepTimeout      time.Duration
+	SagaRecoveryInterval time.Duration
 }
 
 // Load loads the booking service configuration
@@ -26,5 +46,16 @@ func Load() (*Config, error) {
 	return &Config{
 		Config:               baseConfig,
 		ServiceFeePercentage: 0.10, // 10% service fee
+		InventoryServiceURL:  "http://" + baseConfig.Services.InventoryServiceAddr,
+		PaymentServiceURL:    "http://" + baseConfig.Services.PaymentServiceAddr,
+		AuthServiceURL:       "http://" + baseConfig.Services.AuthServiceAddr,
+		QuoteSecret:          baseConfig.Booking.QuoteSecret,
+		QuoteTTL:             15 * time.Minute,
+		OwnerResponseWindow:  baseConfig.Booking.OwnerResponseWindow,
+		PaymentWindow:        baseConfig.Booking.PaymentWindow,
+		ExpiryCheckInterval:  baseConfig.Booking.ExpiryCheckInterval,
+		SagaTimeout:          2 * time.Minute,
+		SagaStepTimeout:      15 * time.Second,
+		SagaRecoveryInterval: time.Minute,
 	}, nil
 }
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Add-on pricing, as set on the item in inventory
+const (
+	AddOnPerDay = "per_day"
+	AddOnFlat   = "flat"
+)
+
+// maxAddOnQuantity matches the most of one add-on inventory reserves for a booking
+const maxAddOnQuantity = 10
+
+// AddOnRate is an add-on's published price, as read from inventory
+type AddOnRate struct {
+	ID      uuid.UUID
+	Name    string
+	Pricing string
+	Price   float64
+}
+
+// SelectedAddOn is an add-on the renter chose for a booking and how many of it
+type SelectedAddOn struct {
+	AddOnID  uuid.UUID `json:"add_on_id" bson:"add_on_id"`
+	Name     string    `json:"name,omitempty" bson:"name,omitempty"`
+	Quantity int       `json:"quantity" bson:"quantity"`
+}
+
+// priceAddOns prices the chosen add-ons at their current rates, one line
+// each. Per-day add-ons are charged for every day of the rental.
+func priceAddOns(selected []SelectedAddOn, rates []AddOnRate, days int) ([]SelectedAddOn, []PriceLine, error) {
+	byID := make(map[uuid.UUID]AddOnRate, len(rates))
+	for _, rate := range rates {
+		byID[rate.ID] = rate
+	}
+
+	named := make([]SelectedAddOn, 0, len(selected))
+	lines := make([]PriceLine, 0, len(selected))
+	seen := make(map[uuid.UUID]bool, len(selected))
+	for _, choice := range selected {
+		rate, ok := byID[choice.AddOnID]
+		if !ok || seen[choice.AddOnID] || choice.Quantity < 1 || choice.Quantity > maxAddOnQuantity {
+			return nil, nil, ErrInvalidAddOn
+		}
+		seen[choice.AddOnID] = true
+
+		addOnID := rate.ID
+		line := PriceLine{
+			Description: rate.Name,
+			Quantity:    choice.Quantity,
+			UnitPrice:   rate.Price,
+			AddOnID:     &addOnID,
+		}
+		if rate.Pricing == AddOnPerDay {
+			unit := "days"
+			if days == 1 {
+				unit = "day"
+			}
+			line.Description = fmt.Sprintf("%s for %d %s", rate.Name, days, unit)
+			line.UnitPrice = roundAmount(rate.Price * float64(days))
+			line.DailyPrice = rate.Price
+		}
+		line.Amount = roundAmount(float64(choice.Quantity) * line.UnitPrice)
+
+		named = append(named, SelectedAddOn{AddOnID: addOnID, Name: rate.Name, Quantity: choice.Quantity})
+		lines = append(lines, line)
+	}
+	return named, lines, nil
+}
+
+// BookedAddOnRates are the rates the booking's add-ons were charged at, taken
+// from its price breakdown, so new dates can be priced without the owner's
+// later changes to their add-ons
+func (b *Booking) BookedAddOnRates() []AddOnRate {
+	names := make(map[uuid.UUID]string, len(b.AddOns))
+	for _, addOn := range b.AddOns {
+		names[addOn.AddOnID] = addOn.Name
+	}
+
+	var rates []AddOnRate
+	for _, line := range b.PriceBreakdown {
+		if line.AddOnID == nil {
+			continue
+		}
+		rate := AddOnRate{ID: *line.AddOnID, Name: names[*line.AddOnID], Pricing: AddOnFlat, Price: line.UnitPrice}
+		if line.DailyPrice > 0 {
+			rate.Pricing = AddOnPerDay
+			rate.Price = line.DailyPrice
+		}
+		rates = append(rates, rate)
+	}
+	return rates
+}
+
+// addOnsTotal is what the add-on lines of a price breakdown come to
+func addOnsTotal(lines []PriceLine) float64 {
+	total := 0.0
+	for _, line := range lines {
+		if line.AddOnID != nil {
+			total += line.Amount
+		}
+	}
+	return roundAmount(total)
+}
+
+// Charges itemise a price for the payment record: the rental itself, its
+// add-ons and the service fee, with the lines they are made up of
+type Charges struct {
+	RentalFee          float64     `json:"rental_fee"`
+	AdditionalServices float64     `json:"additional_services"`
+	ServiceFee         float64     `json:"service_fee"`
+	Lines              []PriceLine `json:"lines"`
+}
+
+// Charges splits the terms' price into rental, add-ons and service fee
+func (t BookingTerms) Charges() *Charges {
+	addOns := addOnsTotal(t.PriceBreakdown)
+	return &Charges{
+		RentalFee:          roundAmount(t.Subtotal - addOns),
+		AdditionalServices: addOns,
+		ServiceFee:         t.ServiceFee,
+		Lines:              t.PriceBreakdown,
+	}
+}
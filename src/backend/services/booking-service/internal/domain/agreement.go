@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SignerRole string
+
+const (
+	SignerRenter SignerRole = "renter"
+	SignerOwner  SignerRole = "owner"
+)
+
+// AgreementParty is a signing party as they appear on the agreement
+type AgreementParty struct {
+	ID    uuid.UUID `json:"id" bson:"id"`
+	Name  string    `json:"name" bson:"name"`
+	Email string    `json:"email" bson:"email"`
+	Phone string    `json:"phone,omitempty" bson:"phone,omitempty"`
+}
+
+// AgreementItem is the rented item as it appears on the agreement
+type AgreementItem struct {
+	ID          uuid.UUID `json:"id" bson:"id"`
+	Title       string    `json:"title" bson:"title"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	Address     string    `json:"address,omitempty" bson:"address,omitempty"`
+	City        string    `json:"city,omitempty" bson:"city,omitempty"`
+}
+
+// AgreementTerms is everything the agreement document is rendered from
+type AgreementTerms struct {
+	BookingNumber      string             `json:"booking_number" bson:"booking_number"`
+	Renter             AgreementParty     `json:"renter" bson:"renter"`
+	Owner              AgreementParty     `json:"owner" bson:"owner"`
+	Item               AgreementItem      `json:"item" bson:"item"`
+	Terms              BookingTerms       `json:"terms" bson:"terms"`
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy" bson:"cancellation_policy"`
+}
+
+// Signature records one party accepting an agreement
+type Signature struct {
+	SignerID    uuid.UUID  `json:"signer_id" bson:"signer_id"`
+	Role        SignerRole `json:"role" bson:"role"`
+	IPAddress   string     `json:"ip_address" bson:"ip_address"`
+	UserAgent   string     `json:"user_agent,omitempty" bson:"user_agent,omitempty"`
+	ContentHash string     `json:"content_hash" bson:"content_hash"`
+	SignedAt    time.Time  `json:"signed_at" bson:"signed_at"`
+}
+
+// Agreement is the rental agreement for one revision of a booking. A new
+// revision gets a new agreement, which must be signed again.
+type Agreement struct {
+	ID          uuid.UUID      `json:"id" bson:"_id"`
+	BookingID   uuid.UUID      `json:"booking_id" bson:"booking_id"`
+	Revision    int            `json:"revision" bson:"revision"`
+	Terms       AgreementTerms `json:"terms" bson:"terms"`
+	HTML        string         `json:"-" bson:"html"`
+	ContentHash string         `json:"content_hash" bson:"content_hash"`
+	Signatures  []Signature    `json:"signatures" bson:"signatures"`
+	CreatedAt   time.Time      `json:"created_at" bson:"created_at"`
+}
+
+// NewAgreement creates an agreement for the booking's current revision. The
+// content hash is taken over the rendered HTML, which is kept as signed.
+func NewAgreement(b *Booking, terms AgreementTerms, html string) *Agreement {
+	sum := sha256.Sum256([]byte(html))
+	return &Agreement{
+		ID:          uuid.New(),
+		BookingID:   b.ID,
+		Revision:    b.Revision,
+		Terms:       terms,
+		HTML:        html,
+		ContentHash: hex.EncodeToString(sum[:]),
+		Signatures:  []Signature{},
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Sign records the party's signature. The signer must present the hash of the
+// content they were shown, so a changed document can't be signed unseen.
+func (a *Agreement) Sign(b *Booking, signerID uuid.UUID, contentHash, ipAddress, userAgent string) error {
+	var role SignerRole
+	switch signerID {
+	case b.RenterID:
+		role = SignerRenter
+	case b.OwnerID:
+		role = SignerOwner
+	default:
+		return ErrUnauthorized
+	}
+
+	if a.Revision != b.Revision || contentHash != a.ContentHash {
+		return ErrAgreementOutdated
+	}
+	if a.SignedBy(role) {
+		return ErrAlreadySigned
+	}
+
+	a.Signatures = append(a.Signatures, Signature{
+		SignerID:    signerID,
+		Role:        role,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+		ContentHash: contentHash,
+		SignedAt:    time.Now(),
+	})
+	return nil
+}
+
+// SignedBy reports whether the given party has signed
+func (a *Agreement) SignedBy(role SignerRole) bool {
+	for _, s := range a.Signatures {
+		if s.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// FullySigned reports whether both parties have signed
+func (a *Agreement) FullySigned() bool {
+	return a.SignedBy(SignerRenter) && a.SignedBy(SignerOwner)
+}
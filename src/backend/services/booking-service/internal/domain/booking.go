@@ -14,6 +14,8 @@ const (
 	StatusActive    BookingStatus = "active"
 	StatusCompleted BookingStatus = "completed"
 	StatusCancelled BookingStatus = "cancelled"
+	StatusRejected  BookingStatus = "rejected"
+	StatusExpired   BookingStatus = "expired"
 )
 
 type CancellationPolicy string
@@ -34,14 +36,19 @@ type Booking struct {
 	StartDate          time.Time          `json:"start_date" bson:"start_date"`
 	EndDate            time.Time          `json:"end_date" bson:"end_date"`
 	TotalDays          int                `json:"total_days" bson:"total_days"`
+	QuoteID            uuid.UUID          `json:"quote_id" bson:"quote_id"`
 	DailyRate          float64            `json:"daily_rate" bson:"daily_rate"`
+	AddOns             []SelectedAddOn    `json:"add_ons,omitempty" bson:"add_ons,omitempty"`
+	PriceBreakdown     []PriceLine        `json:"price_breakdown" bson:"price_breakdown"`
 	Subtotal           float64            `json:"subtotal" bson:"subtotal"`
 	SecurityDeposit    float64            `json:"security_deposit" bson:"security_deposit"`
+	DepositStatus      string             `json:"deposit_status,omitempty" bson:"deposit_status,omitempty"`
 	ServiceFee         float64            `json:"service_fee" bson:"service_fee"`
 	TotalAmount        float64            `json:"total_amount" bson:"total_amount"`
 	PickupAddress      string             `json:"pickup_address,omitempty" bson:"pickup_address,omitempty"`
 	PickupNotes        string             `json:"pickup_notes,omitempty" bson:"pickup_notes,omitempty"`
 	PickupTime         *time.Time         `json:"pickup_time,omitempty" bson:"pickup_time,omitempty"`
+	UnitID             *uuid.UUID         `json:"unit_id,omitempty" bson:"unit_id,omitempty"`
 	ReturnAddress      string             `json:"return_address,omitempty" bson:"return_address,omitempty"`
 	ReturnNotes        string             `json:"return_notes,omitempty" bson:"return_notes,omitempty"`
 	ReturnTime         *time.Time         `json:"return_time,omitempty" bson:"return_time,omitempty"`
@@ -50,42 +57,63 @@ type Booking struct {
 	AgreementURL       string             `json:"agreement_url,omitempty" bson:"agreement_url,omitempty"`
 	CancelledBy        *uuid.UUID         `json:"cancelled_by,omitempty" bson:"cancelled_by,omitempty"`
 	CancellationReason string             `json:"cancellation_reason,omitempty" bson:"cancellation_reason,omitempty"`
+	Refund             *Refund            `json:"refund,omitempty" bson:"refund,omitempty"`
 	PaymentStatus      string             `json:"payment_status,omitempty" bson:"payment_status,omitempty"`
 	PaymentID          *uuid.UUID         `json:"payment_id,omitempty" bson:"payment_id,omitempty"`
+	PaymentMethod      string             `json:"payment_method,omitempty" bson:"payment_method,omitempty"`
+	CheckoutURL        string             `json:"checkout_url,omitempty" bson:"checkout_url,omitempty"`
+	ResponseDeadline   *time.Time         `json:"response_deadline,omitempty" bson:"response_deadline,omitempty"`
+	PaymentDeadline    *time.Time         `json:"payment_deadline,omitempty" bson:"payment_deadline,omitempty"`
+	StatusHistory      []StatusChange     `json:"status_history" bson:"status_history"`
+	Revision           int                `json:"revision" bson:"revision"`
+	Modifications      []Modification     `json:"modifications,omitempty" bson:"modifications,omitempty"`
 	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at"`
-}
 
-func NewBooking(renterID, ownerID, rentalItemID uuid.UUID, startDate, endDate time.Time, dailyRate, securityDeposit float64) *Booking {
-	totalDays := int(endDate.Sub(startDate).Hours() / 24)
-	if totalDays < 1 {
-		totalDays = 1
-	}
+	// Version counts the saved changes to the booking, so an update made
+	// from a stale copy is refused rather than overwriting newer changes
+	Version int64 `json:"-" bson:"version"`
+
+	// ReleasePending marks an expired booking whose dates and payments, or a
+	// cancelled booking whose dates, have yet to be released
+	ReleasePending bool `json:"-" bson:"release_pending,omitempty"`
 
-	subtotal := float64(totalDays) * dailyRate
-	serviceFee := subtotal * 0.10 // 10%
-	totalAmount := subtotal + serviceFee + securityDeposit
+	// SettlementPending marks a booking whose accepted modification has yet
+	// to have its price difference charged or refunded
+	SettlementPending bool `json:"-" bson:"settlement_pending,omitempty"`
+
+	// status changes not yet persisted
+	newHistory []StatusChange
+}
 
+// NewBooking creates a pending booking priced by a quote
+func NewBooking(quote *Quote) *Booking {
 	now := time.Now()
-	return &Booking{
+	booking := &Booking{
 		ID:                 uuid.New(),
 		BookingNumber:      generateBookingNumber(),
-		RenterID:           renterID,
-		OwnerID:            ownerID,
-		RentalItemID:       rentalItemID,
+		RenterID:           quote.RenterID,
+		OwnerID:            quote.OwnerID,
+		RentalItemID:       quote.RentalItemID,
+		QuoteID:            quote.ID,
 		Status:             StatusPending,
-		StartDate:          startDate,
-		EndDate:            endDate,
-		TotalDays:          totalDays,
-		DailyRate:          dailyRate,
-		Subtotal:           subtotal,
-		SecurityDeposit:    securityDeposit,
-		ServiceFee:         serviceFee,
-		TotalAmount:        totalAmount,
+		StartDate:          quote.StartDate,
+		EndDate:            quote.EndDate,
+		TotalDays:          quote.TotalDays,
+		DailyRate:          quote.DailyRate,
+		AddOns:             quote.AddOns,
+		PriceBreakdown:     quote.Lines,
+		Subtotal:           quote.Subtotal,
+		SecurityDeposit:    quote.SecurityDeposit,
+		ServiceFee:         quote.ServiceFee,
+		TotalAmount:        quote.TotalAmount,
 		CancellationPolicy: PolicyModerate,
+		Revision:           1,
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
+	booking.recordStatus("", StatusPending, &quote.RenterID, "booking requested")
+	return booking
 }
 
 func generateBookingNumber() string {
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookingView groups bookings by where they are in their lifecycle
+type BookingView string
+
+const (
+	ViewUpcoming BookingView = "upcoming"
+	ViewCurrent  BookingView = "current"
+	ViewPast     BookingView = "past"
+)
+
+// Statuses returns the booking statuses the view covers
+func (v BookingView) Statuses() ([]BookingStatus, error) {
+	switch v {
+	case ViewUpcoming:
+		return []BookingStatus{StatusPending, StatusConfirmed}, nil
+	case ViewCurrent:
+		return []BookingStatus{StatusActive}, nil
+	case ViewPast:
+		return []BookingStatus{StatusCompleted, StatusCancelled, StatusRejected, StatusExpired}, nil
+	default:
+		return nil, ErrInvalidFilter
+	}
+}
+
+// BookingFilter narrows a renter's or owner's booking list. From and To select
+// bookings whose dates overlap the range; either may be left open.
+type BookingFilter struct {
+	Statuses     []BookingStatus
+	RentalItemID *uuid.UUID
+	From         *time.Time
+	To           *time.Time
+}
+
+// BookingCursor is the position of the last booking on a page. Lists are
+// ordered newest first by creation time, with the ID breaking ties.
+type BookingCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// CursorAfter returns the cursor for the page following the booking
+func CursorAfter(b *Booking) *BookingCursor {
+	return &BookingCursor{CreatedAt: b.CreatedAt, ID: b.ID}
+}
+
+// Encode returns the cursor as an opaque token for clients
+func (c *BookingCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBookingCursor parses a token produced by Encode
+func DecodeBookingCursor(token string) (*BookingCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c BookingCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "strings"
+
+// RuleViolation is one reason the inventory service refused a rental, such as
+// a minimum stay, lead time or blackout date
+type RuleViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Codes of the violations that concern only when a rental starts
+const (
+	ViolationLeadTime      = "lead_time"
+	ViolationAdvanceWindow = "advance_window"
+)
+
+// ModificationViolations drops the violations a modification keeping the
+// booked start date can't cause: that start met the lead time and advance
+// window when it was booked, and an active rental has already started
+func ModificationViolations(violations []RuleViolation, keepsStart bool) []RuleViolation {
+	if !keepsStart {
+		return violations
+	}
+	var kept []RuleViolation
+	for _, v := range violations {
+		if v.Code != ViolationLeadTime && v.Code != ViolationAdvanceWindow {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// RulesViolatedError is returned when a rental breaks the item's rules
+type RulesViolatedError struct {
+	Violations []RuleViolation
+}
+
+func (e *RulesViolatedError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return "booking not allowed: " + strings.Join(messages, "; ")
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// CancelledBy identifies which side cancelled a booking
+type CancelledBy string
+
+const (
+	CancelledByRenter CancelledBy = "renter"
+	CancelledByOwner  CancelledBy = "owner"
+	CancelledBySystem CancelledBy = "system"
+)
+
+// refundTier refunds RentalFeeShare of the rental fee when the booking is
+// cancelled at least MinNotice before it starts
+type refundTier struct {
+	MinNotice      time.Duration
+	RentalFeeShare float64
+}
+
+// policyTiers are checked in order; the first tier whose notice is met applies.
+// Cancelling with less notice than every tier refunds no rental fee.
+var policyTiers = map[CancellationPolicy][]refundTier{
+	PolicyFlexible: {
+		{MinNotice: 24 * time.Hour, RentalFeeShare: 1},
+		{MinNotice: 0, RentalFeeShare: 0.5},
+	},
+	PolicyModerate: {
+		{MinNotice: 5 * 24 * time.Hour, RentalFeeShare: 1},
+		{MinNotice: 24 * time.Hour, RentalFeeShare: 0.5},
+	},
+	PolicyStrict: {
+		{MinNotice: 14 * 24 * time.Hour, RentalFeeShare: 1},
+		{MinNotice: 7 * 24 * time.Hour, RentalFeeShare: 0.5},
+	},
+}
+
+// IsValid checks if the policy is known
+func (p CancellationPolicy) IsValid() bool {
+	_, ok := policyTiers[p]
+	return ok
+}
+
+// Refund is what a cancelled booking gives back to the renter
+type Refund struct {
+	Policy           CancellationPolicy `json:"policy" bson:"policy"`
+	CancelledBy      CancelledBy        `json:"cancelled_by" bson:"cancelled_by"`
+	HoursBeforeStart float64            `json:"hours_before_start" bson:"hours_before_start"`
+	RentalFee        float64            `json:"rental_fee" bson:"rental_fee"`
+	ServiceFee       float64            `json:"service_fee" bson:"service_fee"`
+	SecurityDeposit  float64            `json:"security_deposit" bson:"security_deposit"`
+	Total            float64            `json:"total" bson:"total"`
+}
+
+// CalculateRefund applies the booking's cancellation policy. The deposit is
+// always returned since the item was never handed over. The renter gets
+// everything back when the owner or the system cancels, or when the owner had
+// not yet confirmed. Otherwise the policy tier sets the rental fee share, and
+// the service fee is only returned along with a full rental fee refund.
+func CalculateRefund(b *Booking, by CancelledBy, at time.Time) *Refund {
+	notice := b.StartDate.Sub(at)
+
+	share := 0.0
+	switch {
+	case by != CancelledByRenter, b.Status == StatusPending:
+		share = 1
+	default:
+		tiers, ok := policyTiers[b.CancellationPolicy]
+		if !ok {
+			tiers = policyTiers[PolicyModerate]
+		}
+		for _, tier := range tiers {
+			if notice >= tier.MinNotice {
+				share = tier.RentalFeeShare
+				break
+			}
+		}
+	}
+
+	refund := &Refund{
+		Policy:           b.CancellationPolicy,
+		CancelledBy:      by,
+		HoursBeforeStart: roundAmount(notice.Hours()),
+		RentalFee:        roundAmount(b.Subtotal * share),
+		SecurityDeposit:  b.SecurityDeposit,
+	}
+	if share == 1 {
+		refund.ServiceFee = b.ServiceFee
+	}
+	refund.Total = roundAmount(refund.RentalFee + refund.ServiceFee + refund.SecurityDeposit)
+	return refund
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateRefundTiers(t *testing.T) {
+	start := time.Date(2026, time.June, 15, 10, 0, 0, 0, time.UTC)
+	before := func(hours int) time.Time {
+		return start.Add(-time.Duration(hours) * time.Hour)
+	}
+
+	tests := []struct {
+		name       string
+		policy     CancellationPolicy
+		status     BookingStatus
+		by         CancelledBy
+		at         time.Time
+		rentalFee  float64
+		serviceFee float64
+		total      float64
+	}{
+		{"flexible, a day ahead", PolicyFlexible, StatusConfirmed, CancelledByRenter, before(24), 300, 30, 380},
+		{"flexible, the same day", PolicyFlexible, StatusConfirmed, CancelledByRenter, before(2), 150, 0, 200},
+		{"flexible, after the start", PolicyFlexible, StatusConfirmed, CancelledByRenter, before(-1), 0, 0, 50},
+		{"moderate, five days ahead", PolicyModerate, StatusConfirmed, CancelledByRenter, before(5 * 24), 300, 30, 380},
+		{"moderate, two days ahead", PolicyModerate, StatusConfirmed, CancelledByRenter, before(48), 150, 0, 200},
+		{"moderate, hours ahead", PolicyModerate, StatusConfirmed, CancelledByRenter, before(12), 0, 0, 50},
+		{"strict, two weeks ahead", PolicyStrict, StatusConfirmed, CancelledByRenter, before(14 * 24), 300, 30, 380},
+		{"strict, a week ahead", PolicyStrict, StatusConfirmed, CancelledByRenter, before(7 * 24), 150, 0, 200},
+		{"strict, six days ahead", PolicyStrict, StatusConfirmed, CancelledByRenter, before(6 * 24), 0, 0, 50},
+		{"unknown policy treated as moderate", "lenient", StatusConfirmed, CancelledByRenter, before(48), 150, 0, 200},
+		{"owner cancels", PolicyStrict, StatusConfirmed, CancelledByOwner, before(1), 300, 30, 380},
+		{"system cancels", PolicyStrict, StatusConfirmed, CancelledBySystem, before(1), 300, 30, 380},
+		{"renter cancels before the owner answered", PolicyStrict, StatusPending, CancelledByRenter, before(1), 300, 30, 380},
+	}
+	for _, tt := range tests {
+		booking := &Booking{
+			Status:             tt.status,
+			StartDate:          start,
+			CancellationPolicy: tt.policy,
+			Subtotal:           300,
+			ServiceFee:         30,
+			SecurityDeposit:    50,
+		}
+		got := CalculateRefund(booking, tt.by, tt.at)
+		if got.RentalFee != tt.rentalFee || got.ServiceFee != tt.serviceFee || got.SecurityDeposit != 50 || got.Total != tt.total {
+			t.Errorf("%s: refund = %+v, want rental fee %v, service fee %v, total %v", tt.name, got, tt.rentalFee, tt.serviceFee, tt.total)
+		}
+	}
+}
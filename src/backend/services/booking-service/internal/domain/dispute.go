@@ -0,0 +1,165 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DisputeStatus string
+
+const (
+	// DisputeOpen is a claim awaiting the renter's response
+	DisputeOpen DisputeStatus = "open"
+	// DisputeUnderReview is a contested claim awaiting an admin's decision
+	DisputeUnderReview DisputeStatus = "under_review"
+	DisputeResolved    DisputeStatus = "resolved"
+)
+
+// Deposit statuses as tracked on the booking
+const (
+	DepositHeld              = "held"
+	DepositDisputed          = "disputed"
+	DepositReleased          = "released"
+	DepositCaptured          = "captured"
+	DepositPartiallyCaptured = "partially_captured"
+)
+
+// DamageClaim is the owner's report of damage found at check-in
+type DamageClaim struct {
+	Description string    `json:"description" bson:"description"`
+	PhotoURLs   []string  `json:"photo_urls" bson:"photo_urls"`
+	Amount      float64   `json:"amount" bson:"amount"`
+	FiledAt     time.Time `json:"filed_at" bson:"filed_at"`
+}
+
+// DisputeResponse is the renter's answer to a damage claim
+type DisputeResponse struct {
+	Message     string    `json:"message" bson:"message"`
+	PhotoURLs   []string  `json:"photo_urls,omitempty" bson:"photo_urls,omitempty"`
+	Accepted    bool      `json:"accepted" bson:"accepted"`
+	RespondedAt time.Time `json:"responded_at" bson:"responded_at"`
+}
+
+// DisputeResolution is how much of the deposit goes to the owner. DecidedBy is
+// nil when the renter accepted the claim and no admin was involved.
+type DisputeResolution struct {
+	DecidedBy      *uuid.UUID `json:"decided_by,omitempty" bson:"decided_by,omitempty"`
+	CapturedAmount float64    `json:"captured_amount" bson:"captured_amount"`
+	ReleasedAmount float64    `json:"released_amount" bson:"released_amount"`
+	Notes          string     `json:"notes,omitempty" bson:"notes,omitempty"`
+	DecidedAt      time.Time  `json:"decided_at" bson:"decided_at"`
+}
+
+// Dispute is a damage claim against a booking's security deposit
+type Dispute struct {
+	ID              uuid.UUID          `json:"id" bson:"_id"`
+	BookingID       uuid.UUID          `json:"booking_id" bson:"booking_id"`
+	BookingNumber   string             `json:"booking_number" bson:"booking_number"`
+	OwnerID         uuid.UUID          `json:"owner_id" bson:"owner_id"`
+	RenterID        uuid.UUID          `json:"renter_id" bson:"renter_id"`
+	SecurityDeposit float64            `json:"security_deposit" bson:"security_deposit"`
+	Status          DisputeStatus      `json:"status" bson:"status"`
+	Claim           DamageClaim        `json:"claim" bson:"claim"`
+	Response        *DisputeResponse   `json:"response,omitempty" bson:"response,omitempty"`
+	Resolution      *DisputeResolution `json:"resolution,omitempty" bson:"resolution,omitempty"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NewDispute opens a damage claim against the booking's deposit. The claim
+// can't be for more than the deposit, which is all the owner can recover.
+func NewDispute(b *Booking, description string, photoURLs []string, amount float64) (*Dispute, error) {
+	if strings.TrimSpace(description) == "" || amount <= 0 || amount > b.SecurityDeposit {
+		return nil, ErrInvalidClaim
+	}
+	if photoURLs == nil {
+		photoURLs = []string{}
+	}
+
+	now := time.Now()
+	return &Dispute{
+		ID:              uuid.New(),
+		BookingID:       b.ID,
+		BookingNumber:   b.BookingNumber,
+		OwnerID:         b.OwnerID,
+		RenterID:        b.RenterID,
+		SecurityDeposit: b.SecurityDeposit,
+		Status:          DisputeOpen,
+		Claim: DamageClaim{
+			Description: description,
+			PhotoURLs:   photoURLs,
+			Amount:      amount,
+			FiledAt:     now,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// Respond records the renter's answer. Accepting the claim resolves the
+// dispute for the claimed amount; contesting it sends it to an admin.
+func (d *Dispute) Respond(renterID uuid.UUID, message string, photoURLs []string, accept bool) error {
+	if renterID != d.RenterID {
+		return ErrUnauthorized
+	}
+	if d.Status != DisputeOpen {
+		return ErrDisputeClosed
+	}
+
+	now := time.Now()
+	d.Response = &DisputeResponse{
+		Message:     message,
+		PhotoURLs:   photoURLs,
+		Accepted:    accept,
+		RespondedAt: now,
+	}
+	d.UpdatedAt = now
+
+	if accept {
+		d.resolve(nil, d.Claim.Amount, "claim accepted by the renter")
+		return nil
+	}
+	d.Status = DisputeUnderReview
+	return nil
+}
+
+// Resolve records an admin's decision on how much of the deposit to capture.
+// Admins may decide before the renter responds, e.g. if they never do.
+func (d *Dispute) Resolve(adminID uuid.UUID, capturedAmount float64, notes string) error {
+	if d.Status == DisputeResolved {
+		return ErrDisputeClosed
+	}
+	if capturedAmount < 0 || capturedAmount > d.Claim.Amount {
+		return ErrInvalidClaim
+	}
+
+	d.resolve(&adminID, capturedAmount, notes)
+	return nil
+}
+
+func (d *Dispute) resolve(decidedBy *uuid.UUID, captured float64, notes string) {
+	now := time.Now()
+	d.Status = DisputeResolved
+	d.Resolution = &DisputeResolution{
+		DecidedBy:      decidedBy,
+		CapturedAmount: captured,
+		ReleasedAmount: roundAmount(d.SecurityDeposit - captured),
+		Notes:          notes,
+		DecidedAt:      now,
+	}
+	d.UpdatedAt = now
+}
+
+// DepositStatus is the booking deposit status a resolution leads to
+func (r *DisputeResolution) DepositStatus() string {
+	switch {
+	case r.CapturedAmount == 0:
+		return DepositReleased
+	case r.ReleasedAmount == 0:
+		return DepositCaptured
+	default:
+		return DepositPartiallyCaptured
+	}
+}
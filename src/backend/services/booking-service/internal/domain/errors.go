@@ -4,13 +4,40 @@ import "errors"
 
 // Domain errors
 var (
-	ErrBookingNotFound     = errors.New("booking not found")
-	ErrUnauthorized        = errors.New("unauthorized to perform this action")
-	ErrInvalidStatus       = errors.New("invalid booking status")
-	ErrInvalidDates        = errors.New("invalid booking dates")
-	ErrDateConflict        = errors.New("booking dates conflict with existing reservation")
-	ErrAlreadyCancelled    = errors.New("booking is already cancelled")
-	ErrCannotCancel        = errors.New("booking cannot be cancelled")
-	ErrAgreementNotSigned  = errors.New("rental agreement not signed")
-	ErrPaymentNotCompleted = errors.New("payment not completed")
+	ErrBookingNotFound      = errors.New("booking not found")
+	ErrBookingChanged       = errors.New("booking was changed by another request, please retry")
+	ErrUnauthorized         = errors.New("unauthorized to perform this action")
+	ErrInvalidStatus        = errors.New("invalid booking status")
+	ErrInvalidTransition    = errors.New("booking status transition not allowed")
+	ErrInvalidDates         = errors.New("invalid booking dates")
+	ErrDateConflict         = errors.New("booking dates conflict with existing reservation")
+	ErrAlreadyCancelled     = errors.New("booking is already cancelled")
+	ErrCannotCancel         = errors.New("booking cannot be cancelled")
+	ErrAgreementNotSigned   = errors.New("rental agreement not signed")
+	ErrPaymentNotCompleted  = errors.New("payment not completed")
+	ErrItemNotFound         = errors.New("rental item not found")
+	ErrItemUnavailable      = errors.New("rental item is not available for booking")
+	ErrInvalidPrice         = errors.New("item has no valid price")
+	ErrInvalidQuote         = errors.New("invalid or tampered quote")
+	ErrInvalidAddOn         = errors.New("add-on not offered with this item or invalid quantity")
+	ErrAddOnUnavailable     = errors.New("not enough of the add-on is free for these dates")
+	ErrQuoteExpired         = errors.New("quote has expired, please request a new one")
+	ErrCannotModify         = errors.New("booking can no longer be modified")
+	ErrInvalidModification  = errors.New("invalid booking modification")
+	ErrModificationPending  = errors.New("booking already has a modification awaiting a response")
+	ErrModificationNotFound = errors.New("modification not found or no longer open")
+	ErrModificationStale    = errors.New("booking changed since the modification was proposed")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrAgreementNotFound    = errors.New("rental agreement not found")
+	ErrAgreementOutdated    = errors.New("rental agreement has changed, please review it again")
+	ErrAlreadySigned        = errors.New("rental agreement already signed by this party")
+	ErrInvalidClaim         = errors.New("damage claim needs a description and an amount up to the security deposit")
+	ErrDisputeNotFound      = errors.New("dispute not found")
+	ErrDisputeClosed        = errors.New("dispute is no longer open")
+	ErrDepositNotHeld       = errors.New("no security deposit is held for this booking")
+	ErrInvalidFilter        = errors.New("invalid booking filter")
+	ErrInvalidCursor        = errors.New("invalid pagination cursor")
+	ErrSagaNotFound         = errors.New("booking saga not found")
+	ErrSagaTimedOut         = errors.New("booking saga timed out")
+	ErrSagaClaimLost        = errors.New("booking saga was taken over by another run")
 )
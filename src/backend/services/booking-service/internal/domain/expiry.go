@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// PaymentCompleted is the payment service status of a settled payment
+const PaymentCompleted = "completed"
+
+// SetResponseDeadline gives the owner window to answer a pending booking
+func (b *Booking) SetResponseDeadline(now time.Time, window time.Duration) {
+	deadline := b.deadline(now, window)
+	b.ResponseDeadline = &deadline
+}
+
+// SetPaymentDeadline gives the renter window to pay a confirmed booking
+func (b *Booking) SetPaymentDeadline(now time.Time, window time.Duration) {
+	deadline := b.deadline(now, window)
+	b.PaymentDeadline = &deadline
+}
+
+// deadline is now plus window, but never later than the start of the rental
+func (b *Booking) deadline(now time.Time, window time.Duration) time.Time {
+	deadline := now.Add(window)
+	if b.StartDate.After(now) && b.StartDate.Before(deadline) {
+		return b.StartDate
+	}
+	return deadline
+}
+
+// IsPaid reports whether the booking's payment is known to have completed
+func (b *Booking) IsPaid() bool {
+	return b.PaymentStatus == PaymentCompleted
+}
+
+// ExpiryReason says why the booking is due to expire at the given time, or
+// returns "" if it is not
+func (b *Booking) ExpiryReason(now time.Time) string {
+	switch {
+	case b.Status == StatusPending && b.ResponseDeadline != nil && now.After(*b.ResponseDeadline):
+		return "owner did not respond in time"
+	case b.Status == StatusConfirmed && !b.IsPaid() && b.PaymentDeadline != nil && now.After(*b.PaymentDeadline):
+		return "payment was not completed in time"
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ModificationStatus string
+
+const (
+	ModificationProposed ModificationStatus = "proposed"
+	ModificationAccepted ModificationStatus = "accepted"
+	ModificationRejected ModificationStatus = "rejected"
+)
+
+// BookingTerms are the dates and price of one revision of a booking
+type BookingTerms struct {
+	StartDate       time.Time   `json:"start_date" bson:"start_date"`
+	EndDate         time.Time   `json:"end_date" bson:"end_date"`
+	TotalDays       int         `json:"total_days" bson:"total_days"`
+	PriceBreakdown  []PriceLine `json:"price_breakdown" bson:"price_breakdown"`
+	Subtotal        float64     `json:"subtotal" bson:"subtotal"`
+	ServiceFee      float64     `json:"service_fee" bson:"service_fee"`
+	SecurityDeposit float64     `json:"security_deposit" bson:"security_deposit"`
+	TotalAmount     float64     `json:"total_amount" bson:"total_amount"`
+}
+
+// Modification is a renter's request to change a booking's dates. Modifications
+// are never removed, so together they are the audit trail of every revision.
+type Modification struct {
+	ID             uuid.UUID          `json:"id" bson:"id"`
+	BaseRevision   int                `json:"base_revision" bson:"base_revision"`
+	RequestedBy    uuid.UUID          `json:"requested_by" bson:"requested_by"`
+	Reason         string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	Previous       BookingTerms       `json:"previous" bson:"previous"`
+	Proposed       BookingTerms       `json:"proposed" bson:"proposed"`
+	AmountDue      float64            `json:"amount_due" bson:"amount_due"`
+	Status         ModificationStatus `json:"status" bson:"status"`
+	RespondedBy    *uuid.UUID         `json:"responded_by,omitempty" bson:"responded_by,omitempty"`
+	ResponseReason string             `json:"response_reason,omitempty" bson:"response_reason,omitempty"`
+	PaymentID      *uuid.UUID         `json:"payment_id,omitempty" bson:"payment_id,omitempty"`
+	CheckoutURL    string             `json:"checkout_url,omitempty" bson:"checkout_url,omitempty"`
+	RefundedAmount float64            `json:"refunded_amount,omitempty" bson:"refunded_amount,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	RespondedAt    *time.Time         `json:"responded_at,omitempty" bson:"responded_at,omitempty"`
+}
+
+// Terms returns the booking's current dates and price
+func (b *Booking) Terms() BookingTerms {
+	return BookingTerms{
+		StartDate:       b.StartDate,
+		EndDate:         b.EndDate,
+		TotalDays:       b.TotalDays,
+		PriceBreakdown:  b.PriceBreakdown,
+		Subtotal:        b.Subtotal,
+		ServiceFee:      b.ServiceFee,
+		SecurityDeposit: b.SecurityDeposit,
+		TotalAmount:     b.TotalAmount,
+	}
+}
+
+// ProposeModification records a request to move the booking to the quote's
+// dates and price. The deposit is kept as agreed. Once the rental is active
+// only the end date may change.
+func (b *Booking) ProposeModification(requestedBy uuid.UUID, quote *Quote, reason string) (*Modification, error) {
+	switch b.Status {
+	case StatusPending, StatusConfirmed, StatusActive:
+	default:
+		return nil, ErrCannotModify
+	}
+	if b.OpenModification() != nil || b.SettlementPending {
+		return nil, ErrModificationPending
+	}
+	if quote.StartDate.Equal(b.StartDate) && quote.EndDate.Equal(b.EndDate) {
+		return nil, ErrInvalidModification
+	}
+	if b.Status == StatusActive && !quote.StartDate.Equal(b.StartDate) {
+		return nil, ErrInvalidModification
+	}
+
+	proposed := BookingTerms{
+		StartDate:       quote.StartDate,
+		EndDate:         quote.EndDate,
+		TotalDays:       quote.TotalDays,
+		PriceBreakdown:  quote.Lines,
+		Subtotal:        quote.Subtotal,
+		ServiceFee:      quote.ServiceFee,
+		SecurityDeposit: b.SecurityDeposit,
+		TotalAmount:     roundAmount(quote.Subtotal + quote.ServiceFee + b.SecurityDeposit),
+	}
+
+	b.Modifications = append(b.Modifications, Modification{
+		ID:           uuid.New(),
+		BaseRevision: b.Revision,
+		RequestedBy:  requestedBy,
+		Reason:       reason,
+		Previous:     b.Terms(),
+		Proposed:     proposed,
+		AmountDue:    roundAmount(proposed.TotalAmount - b.TotalAmount),
+		Status:       ModificationProposed,
+		CreatedAt:    time.Now(),
+	})
+	return &b.Modifications[len(b.Modifications)-1], nil
+}
+
+// OpenModification returns the modification awaiting the owner's answer, if any
+func (b *Booking) OpenModification() *Modification {
+	for i := range b.Modifications {
+		if b.Modifications[i].Status == ModificationProposed {
+			return &b.Modifications[i]
+		}
+	}
+	return nil
+}
+
+// PendingModification returns the open modification with the given ID. It fails
+// if the booking changed since the modification was proposed.
+func (b *Booking) PendingModification(id uuid.UUID) (*Modification, error) {
+	m := b.OpenModification()
+	if m == nil || m.ID != id {
+		return nil, ErrModificationNotFound
+	}
+	if m.BaseRevision != b.Revision {
+		return nil, ErrModificationStale
+	}
+	return m, nil
+}
+
+// AcceptModification applies the proposed terms as a new revision, marking
+// the booking until the price difference, if any, is settled
+func (b *Booking) AcceptModification(m *Modification, ownerID uuid.UUID) {
+	b.StartDate = m.Proposed.StartDate
+	b.EndDate = m.Proposed.EndDate
+	b.TotalDays = m.Proposed.TotalDays
+	b.PriceBreakdown = m.Proposed.PriceBreakdown
+	b.Subtotal = m.Proposed.Subtotal
+	b.ServiceFee = m.Proposed.ServiceFee
+	b.TotalAmount = m.Proposed.TotalAmount
+	b.Revision++
+	// The new terms need a new agreement, signed again by both parties
+	b.AgreementSigned = false
+	b.AgreementURL = ""
+	b.SettlementPending = m.AmountDue != 0
+
+	m.respond(ModificationAccepted, ownerID, "")
+}
+
+// SettlingModification returns the accepted modification whose price
+// difference is yet to be settled, if any
+func (b *Booking) SettlingModification() *Modification {
+	if !b.SettlementPending {
+		return nil
+	}
+	for i := len(b.Modifications) - 1; i >= 0; i-- {
+		if b.Modifications[i].Status == ModificationAccepted {
+			return &b.Modifications[i]
+		}
+	}
+	return nil
+}
+
+// RejectModification closes the modification, leaving the booking unchanged
+func (b *Booking) RejectModification(m *Modification, ownerID uuid.UUID, reason string) {
+	m.respond(ModificationRejected, ownerID, reason)
+}
+
+func (m *Modification) respond(status ModificationStatus, ownerID uuid.UUID, reason string) {
+	now := time.Now()
+	m.Status = status
+	m.RespondedBy = &ownerID
+	m.ResponseReason = reason
+	m.RespondedAt = &now
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceLine is one line of a price breakdown. Lines for add-ons carry the
+// add-on's ID, and those for per-day add-ons the daily price they were
+// charged at.
+type PriceLine struct {
+	Description string     `json:"description" bson:"description"`
+	Quantity    int        `json:"quantity" bson:"quantity"`
+	UnitPrice   float64    `json:"unit_price" bson:"unit_price"`
+	Amount      float64    `json:"amount" bson:"amount"`
+	AddOnID     *uuid.UUID `json:"add_on_id,omitempty" bson:"add_on_id,omitempty"`
+	DailyPrice  float64    `json:"daily_price,omitempty" bson:"daily_price,omitempty"`
+}
+
+// ItemRates are the item's published rates, as read from inventory, with the
+// rental priced by inventory's pricing engine
+type ItemRates struct {
+	DailyRate       float64
+	SecurityDeposit float64
+	Rental          RentalPrice
+	AddOns          []AddOnRate
+}
+
+// RentalPrice is what inventory's pricing engine charges for a rental: the
+// lines it is charged in, made up of the item's rates and any pricing rules
+// that apply, and each day's price
+type RentalPrice struct {
+	Days  []DayPrice  `json:"days"`
+	Lines []PriceLine `json:"lines"`
+	Total float64     `json:"total"`
+}
+
+// DayPrice is the price of one day of a rental
+type DayPrice struct {
+	Date        time.Time         `json:"date"`
+	BaseRate    float64           `json:"base_rate"`
+	Adjustments []PriceAdjustment `json:"adjustments,omitempty"`
+	Price       float64           `json:"price"`
+}
+
+// PriceAdjustment is what one of the owner's pricing rules changed a day's
+// price by
+type PriceAdjustment struct {
+	RuleID  uuid.UUID `json:"rule_id"`
+	Name    string    `json:"name"`
+	Percent float64   `json:"percent"`
+	Amount  float64   `json:"amount"`
+}
+
+// Quote is a server-computed price for renting an item over a date range. It is
+// handed to the client signed and must be presented back to create a booking.
+type Quote struct {
+	ID              uuid.UUID       `json:"id"`
+	RenterID        uuid.UUID       `json:"renter_id"`
+	OwnerID         uuid.UUID       `json:"owner_id"`
+	RentalItemID    uuid.UUID       `json:"rental_item_id"`
+	StartDate       time.Time       `json:"start_date"`
+	EndDate         time.Time       `json:"end_date"`
+	TotalDays       int             `json:"total_days"`
+	DailyRate       float64         `json:"daily_rate"`
+	Days            []DayPrice      `json:"-"`
+	AddOns          []SelectedAddOn `json:"add_ons,omitempty"`
+	Lines           []PriceLine     `json:"lines"`
+	AddOnsTotal     float64         `json:"add_ons_total"`
+	Subtotal        float64         `json:"subtotal"`
+	ServiceFee      float64         `json:"service_fee"`
+	SecurityDeposit float64         `json:"security_deposit"`
+	TotalAmount     float64         `json:"total_amount"`
+	ExpiresAt       time.Time       `json:"expires_at"`
+}
+
+// NewQuote prices a rental as inventory charges it, plus the add-ons chosen
+// with it. The days are kept for showing the client but not signed, since
+// the lines are what the rental is charged at.
+func NewQuote(renterID, ownerID, rentalItemID uuid.UUID, startDate, endDate time.Time, rates ItemRates, addOns []SelectedAddOn,
+	serviceFeeRate float64, ttl time.Duration) (*Quote, error) {
+	if !endDate.After(startDate) {
+		return nil, ErrInvalidDates
+	}
+	if rates.DailyRate <= 0 || len(rates.Rental.Lines) == 0 {
+		return nil, ErrInvalidPrice
+	}
+
+	totalDays := RentalDays(startDate, endDate)
+	lines := append([]PriceLine{}, rates.Rental.Lines...)
+
+	addOns, addOnLines, err := priceAddOns(addOns, rates.AddOns, totalDays)
+	if err != nil {
+		return nil, err
+	}
+	lines = append(lines, addOnLines...)
+
+	subtotal := roundAmount(sumLines(lines))
+	serviceFee := roundAmount(subtotal * serviceFeeRate)
+
+	return &Quote{
+		ID:              uuid.New(),
+		RenterID:        renterID,
+		OwnerID:         ownerID,
+		RentalItemID:    rentalItemID,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		TotalDays:       totalDays,
+		DailyRate:       rates.DailyRate,
+		Days:            rates.Rental.Days,
+		AddOns:          addOns,
+		Lines:           lines,
+		AddOnsTotal:     addOnsTotal(lines),
+		Subtotal:        subtotal,
+		ServiceFee:      serviceFee,
+		SecurityDeposit: rates.SecurityDeposit,
+		TotalAmount:     roundAmount(subtotal + serviceFee + rates.SecurityDeposit),
+		ExpiresAt:       time.Now().Add(ttl),
+	}, nil
+}
+
+// RentalDays is the number of days charged for a date range, at least one
+func RentalDays(startDate, endDate time.Time) int {
+	days := int(endDate.Sub(startDate).Hours() / 24)
+	if days < 1 {
+		days = 1
+	}
+	return days
+}
+
+func sumLines(lines []PriceLine) float64 {
+	total := 0.0
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return total
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewQuote(t *testing.T) {
+	start := time.Date(2026, time.June, 15, 0, 0, 0, 0, time.UTC)
+	rates := ItemRates{
+		DailyRate:       100,
+		SecurityDeposit: 50,
+		Rental: RentalPrice{
+			Lines: []PriceLine{{Description: "days", Quantity: 3, UnitPrice: 100, Amount: 300}},
+			Total: 300,
+		},
+	}
+
+	issued := time.Now()
+	quote, err := NewQuote(uuid.New(), uuid.New(), uuid.New(), start, start.AddDate(0, 0, 3), rates, nil, 0.1, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("NewQuote: %v", err)
+	}
+	if quote.TotalDays != 3 || quote.Subtotal != 300 || quote.ServiceFee != 30 || quote.SecurityDeposit != 50 || quote.TotalAmount != 380 {
+		t.Errorf("quote = %+v", quote)
+	}
+	if quote.ExpiresAt.Before(issued.Add(15*time.Minute)) || quote.ExpiresAt.After(time.Now().Add(15*time.Minute)) {
+		t.Errorf("expires at %s, want 15 minutes after %s", quote.ExpiresAt, issued)
+	}
+
+	unpriced := rates
+	unpriced.Rental.Lines = nil
+	free := rates
+	free.DailyRate = 0
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		rates      ItemRates
+		want       error
+	}{
+		{"ends before it starts", start, start.AddDate(0, 0, -1), rates, ErrInvalidDates},
+		{"ends as it starts", start, start, rates, ErrInvalidDates},
+		{"no price lines", start, start.AddDate(0, 0, 3), unpriced, ErrInvalidPrice},
+		{"no daily rate", start, start.AddDate(0, 0, 3), free, ErrInvalidPrice},
+	}
+	for _, tt := range tests {
+		if _, err := NewQuote(uuid.New(), uuid.New(), uuid.New(), tt.start, tt.end, tt.rates, nil, 0.1, time.Minute); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SagaStatus is the overall state of a booking saga
+type SagaStatus string
+
+const (
+	SagaRunning      SagaStatus = "running"
+	SagaCompleted    SagaStatus = "completed"
+	SagaCompensating SagaStatus = "compensating"
+	SagaCompensated  SagaStatus = "compensated"
+	SagaFailed       SagaStatus = "failed"
+)
+
+// IsTerminal reports whether the saga needs no further work
+func (s SagaStatus) IsTerminal() bool {
+	return s == SagaCompleted || s == SagaCompensated || s == SagaFailed
+}
+
+// SagaStepName identifies a step of the booking saga
+type SagaStepName string
+
+const (
+	StepReserveDates      SagaStepName = "reserve_dates"
+	StepCreateBooking     SagaStepName = "create_booking"
+	StepInitializePayment SagaStepName = "initialize_payment"
+)
+
+// SagaSteps is the order in which steps run; compensation runs in reverse
+var SagaSteps = []SagaStepName{StepReserveDates, StepCreateBooking, StepInitializePayment}
+
+// StepStatus is the state of a single saga step
+type StepStatus string
+
+const (
+	StepPending     StepStatus = "pending"
+	StepDone        StepStatus = "done"
+	StepFailed      StepStatus = "failed"
+	StepCompensated StepStatus = "compensated"
+)
+
+type SagaStep struct {
+	Name      SagaStepName `json:"name" bson:"name"`
+	Status    StepStatus   `json:"status" bson:"status"`
+	Error     string       `json:"error,omitempty" bson:"error,omitempty"`
+	UpdatedAt time.Time    `json:"updated_at" bson:"updated_at"`
+}
+
+// Saga tracks a booking as it is carried through inventory, booking and payment.
+// The booking is snapshotted up front so the saga can be resumed after a restart.
+// Only the run holding ClaimToken may drive the saga, until ClaimedUntil.
+type Saga struct {
+	ID            uuid.UUID  `json:"id" bson:"_id"`
+	BookingID     uuid.UUID  `json:"booking_id" bson:"booking_id"`
+	Booking       Booking    `json:"booking" bson:"booking"`
+	PaymentMethod string     `json:"payment_method" bson:"payment_method"`
+	Status        SagaStatus `json:"status" bson:"status"`
+	Steps         []SagaStep `json:"steps" bson:"steps"`
+	Error         string     `json:"error,omitempty" bson:"error,omitempty"`
+	// CompensationFailures counts the failed attempts to undo a step
+	CompensationFailures int       `json:"compensation_failures,omitempty" bson:"compensation_failures,omitempty"`
+	Deadline             time.Time `json:"deadline" bson:"deadline"`
+	CreatedAt            time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" bson:"updated_at"`
+	ClaimToken           uuid.UUID `json:"-" bson:"claim_token"`
+	ClaimedUntil         time.Time `json:"-" bson:"claimed_until"`
+}
+
+func NewSaga(booking *Booking, paymentMethod string, timeout time.Duration) *Saga {
+	now := time.Now()
+	steps := make([]SagaStep, len(SagaSteps))
+	for i, name := range SagaSteps {
+		steps[i] = SagaStep{Name: name, Status: StepPending, UpdatedAt: now}
+	}
+
+	return &Saga{
+		ID:            uuid.New(),
+		BookingID:     booking.ID,
+		Booking:       *booking,
+		PaymentMethod: paymentMethod,
+		Status:        SagaRunning,
+		Steps:         steps,
+		Deadline:      now.Add(timeout),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+// Step returns the named step
+func (s *Saga) Step(name SagaStepName) *SagaStep {
+	for i := range s.Steps {
+		if s.Steps[i].Name == name {
+			return &s.Steps[i]
+		}
+	}
+	return nil
+}
+
+// SetStep records the outcome of a step
+func (s *Saga) SetStep(name SagaStepName, status StepStatus, err error) {
+	step := s.Step(name)
+	if step == nil {
+		return
+	}
+	step.Status = status
+	step.Error = ""
+	if err != nil {
+		step.Error = err.Error()
+	}
+	step.UpdatedAt = time.Now()
+}
+
+// Expired reports whether the saga ran past its deadline
+func (s *Saga) Expired() bool {
+	return time.Now().After(s.Deadline)
+}
+
+// StepsToCompensate lists the steps still to be undone, last run first
+func (s *Saga) StepsToCompensate() []SagaStepName {
+	var names []SagaStepName
+	for i := len(SagaSteps) - 1; i >= 0; i-- {
+		step := s.Step(SagaSteps[i])
+		if step.Status == StepDone || step.Status == StepFailed {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+// FailCompensation records a failed attempt to undo the named step. Once
+// maxAttempts attempts have failed the saga is given up on as failed, and
+// true is returned.
+func (s *Saga) FailCompensation(name SagaStepName, err error, maxAttempts int) bool {
+	if step := s.Step(name); step != nil {
+		step.Error = err.Error()
+		step.UpdatedAt = time.Now()
+	}
+	s.CompensationFailures++
+	if s.CompensationFailures < maxAttempts {
+		return false
+	}
+	s.Status = SagaFailed
+	return true
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestStepsToCompensateRunInReverse(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses map[SagaStepName]StepStatus
+		want     []SagaStepName
+	}{
+		{"nothing run", nil, nil},
+		{"first step failed", map[SagaStepName]StepStatus{StepReserveDates: StepFailed}, []SagaStepName{StepReserveDates}},
+		{
+			"payment failed",
+			map[SagaStepName]StepStatus{StepReserveDates: StepDone, StepCreateBooking: StepDone, StepInitializePayment: StepFailed},
+			[]SagaStepName{StepInitializePayment, StepCreateBooking, StepReserveDates},
+		},
+		{
+			"part way through compensating",
+			map[SagaStepName]StepStatus{StepReserveDates: StepDone, StepCreateBooking: StepCompensated, StepInitializePayment: StepCompensated},
+			[]SagaStepName{StepReserveDates},
+		},
+	}
+	for _, tt := range tests {
+		saga := NewSaga(&Booking{ID: uuid.New()}, "chapa", time.Minute)
+		for name, status := range tt.statuses {
+			saga.Step(name).Status = status
+		}
+
+		got := saga.StepsToCompensate()
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: steps = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for n := range got {
+			if got[n] != tt.want[n] {
+				t.Errorf("%s: steps = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFailCompensationGivesUpAfterMaxAttempts(t *testing.T) {
+	saga := NewSaga(&Booking{ID: uuid.New()}, "chapa", time.Minute)
+	saga.Status = SagaCompensating
+	undoErr := errors.New("inventory unavailable")
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		gaveUp := saga.FailCompensation(StepReserveDates, undoErr, 3)
+		if gaveUp != (attempt == 3) {
+			t.Errorf("attempt %d: gave up = %v", attempt, gaveUp)
+		}
+	}
+	if saga.Status != SagaFailed || !saga.Status.IsTerminal() {
+		t.Errorf("status = %s, want %s", saga.Status, SagaFailed)
+	}
+	if step := saga.Step(StepReserveDates); step.Error != undoErr.Error() {
+		t.Errorf("step error = %q, want %q", step.Error, undoErr)
+	}
+}
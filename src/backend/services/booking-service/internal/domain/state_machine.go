@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transitions lists the statuses each status may move to. Statuses without an
+// entry are terminal.
+var transitions = map[BookingStatus][]BookingStatus{
+	StatusPending:   {StatusConfirmed, StatusRejected, StatusCancelled, StatusExpired},
+	StatusConfirmed: {StatusActive, StatusCancelled, StatusExpired},
+	StatusActive:    {StatusCompleted},
+}
+
+// CanTransition reports whether a booking may move from one status to another
+func CanTransition(from, to BookingStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are possible
+func (s BookingStatus) IsTerminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// IsValid reports whether the status is one a booking can have
+func (s BookingStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusConfirmed, StatusActive, StatusCompleted,
+		StatusCancelled, StatusRejected, StatusExpired:
+		return true
+	}
+	return false
+}
+
+// StatusChange is one entry in a booking's status history. A nil ActorID means
+// the change was made by the system.
+type StatusChange struct {
+	From    BookingStatus `json:"from,omitempty" bson:"from,omitempty"`
+	To      BookingStatus `json:"to" bson:"to"`
+	ActorID *uuid.UUID    `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Reason  string        `json:"reason,omitempty" bson:"reason,omitempty"`
+	At      time.Time     `json:"at" bson:"at"`
+}
+
+// TransitionTo moves the booking to a new status and records the change in its
+// history. It returns ErrInvalidTransition if the move is not allowed, and
+// ErrAgreementNotSigned if the booking would go active unsigned.
+func (b *Booking) TransitionTo(to BookingStatus, actorID *uuid.UUID, reason string) error {
+	if !CanTransition(b.Status, to) {
+		return ErrInvalidTransition
+	}
+	// The item can't be handed over before both parties sign the agreement
+	if to == StatusActive && !b.AgreementSigned {
+		return ErrAgreementNotSigned
+	}
+
+	b.recordStatus(b.Status, to, actorID, reason)
+	b.Status = to
+	return nil
+}
+
+func (b *Booking) recordStatus(from, to BookingStatus, actorID *uuid.UUID, reason string) {
+	change := StatusChange{
+		From:    from,
+		To:      to,
+		ActorID: actorID,
+		Reason:  reason,
+		At:      time.Now(),
+	}
+	b.StatusHistory = append(b.StatusHistory, change)
+	b.newHistory = append(b.newHistory, change)
+}
+
+// PendingHistory returns status changes made since the booking was loaded, so
+// the repository can append them without rewriting earlier entries
+func (b *Booking) PendingHistory() []StatusChange {
+	return b.newHistory
+}
+
+// ClearPendingHistory marks recorded status changes as persisted
+func (b *Booking) ClearPendingHistory() {
+	b.newHistory = nil
+}
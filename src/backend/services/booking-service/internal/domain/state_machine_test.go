@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to BookingStatus
+		want     bool
+	}{
+		{StatusPending, StatusConfirmed, true},
+		{StatusPending, StatusRejected, true},
+		{StatusPending, StatusCancelled, true},
+		{StatusPending, StatusExpired, true},
+		{StatusPending, StatusActive, false},
+		{StatusConfirmed, StatusActive, true},
+		{StatusConfirmed, StatusCancelled, true},
+		{StatusConfirmed, StatusExpired, true},
+		{StatusConfirmed, StatusRejected, false},
+		{StatusActive, StatusCompleted, true},
+		{StatusActive, StatusCancelled, false},
+		{StatusCompleted, StatusCancelled, false},
+		{StatusCancelled, StatusConfirmed, false},
+		{StatusExpired, StatusPending, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	tests := []struct {
+		status BookingStatus
+		want   bool
+	}{
+		{StatusPending, false},
+		{StatusConfirmed, false},
+		{StatusActive, false},
+		{StatusCompleted, true},
+		{StatusCancelled, true},
+		{StatusRejected, true},
+		{StatusExpired, true},
+	}
+	for _, tt := range tests {
+		if got := tt.status.IsTerminal(); got != tt.want {
+			t.Errorf("%s.IsTerminal() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestTransitionToRecordsHistory(t *testing.T) {
+	owner := uuid.New()
+	booking := &Booking{Status: StatusConfirmed}
+
+	if err := booking.TransitionTo(StatusCompleted, &owner, ""); err != ErrInvalidTransition {
+		t.Errorf("confirmed to completed = %v, want ErrInvalidTransition", err)
+	}
+	if err := booking.TransitionTo(StatusActive, &owner, ""); err != ErrAgreementNotSigned {
+		t.Errorf("going active unsigned = %v, want ErrAgreementNotSigned", err)
+	}
+	if booking.Status != StatusConfirmed || len(booking.StatusHistory) != 0 {
+		t.Fatalf("refused transitions changed the booking: %s, %+v", booking.Status, booking.StatusHistory)
+	}
+
+	booking.AgreementSigned = true
+	if err := booking.TransitionTo(StatusActive, &owner, "picked up"); err != nil {
+		t.Fatalf("going active signed: %v", err)
+	}
+	if booking.Status != StatusActive {
+		t.Errorf("status = %s, want %s", booking.Status, StatusActive)
+	}
+	if len(booking.StatusHistory) != 1 || len(booking.PendingHistory()) != 1 {
+		t.Fatalf("history = %+v, pending %+v, want one change in each", booking.StatusHistory, booking.PendingHistory())
+	}
+	change := booking.StatusHistory[0]
+	if change.From != StatusConfirmed || change.To != StatusActive || change.ActorID == nil || *change.ActorID != owner || change.Reason != "picked up" {
+		t.Errorf("change = %+v", change)
+	}
+}
@@ -2,31 +2,55 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/clients"
 	"github.com/rentalflow/booking-service/internal/domain"
 	"github.com/rentalflow/booking-service/internal/service"
 )
 
 type HTTPHandler struct {
-	bookingService *service.BookingService
+	bookingService   *service.BookingService
+	agreementService *service.AgreementService
+	disputeService   *service.DisputeService
 }
 
-func NewHTTPHandler(bookingService *service.BookingService) *HTTPHandler {
-	return &HTTPHandler{bookingService: bookingService}
+func NewHTTPHandler(bookingService *service.BookingService, agreementService *service.AgreementService, disputeService *service.DisputeService) *HTTPHandler {
+	return &HTTPHandler{
+		bookingService:   bookingService,
+		agreementService: agreementService,
+		disputeService:   disputeService,
+	}
 }
 
 func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/health", h.Health)
 	mux.HandleFunc("/ready", h.Ready)
 	mux.HandleFunc("/api/bookings", h.HandleBookings)
+	mux.HandleFunc("/api/bookings/quote", h.CreateQuote)
 	mux.HandleFunc("/api/bookings/renter", h.GetRenterBookings)
 	mux.HandleFunc("/api/bookings/owner", h.GetOwnerBookings)
 	mux.HandleFunc("/api/bookings/confirm", h.ConfirmBooking)
+	mux.HandleFunc("/api/bookings/reject", h.RejectBooking)
 	mux.HandleFunc("/api/bookings/cancel", h.CancelBooking)
+	mux.HandleFunc("/api/bookings/cancel/preview", h.PreviewCancellation)
+	mux.HandleFunc("/api/bookings/checkout", h.CheckOut)
+	mux.HandleFunc("/api/bookings/checkin", h.CheckIn)
+	mux.HandleFunc("/api/bookings/history", h.GetStatusHistory)
+	mux.HandleFunc("/api/bookings/agreement", h.GetAgreement)
+	mux.HandleFunc("/api/bookings/agreement/sign", h.SignAgreement)
+	mux.HandleFunc("/api/bookings/modifications", h.HandleModifications)
+	mux.HandleFunc("/api/bookings/modifications/accept", h.AcceptModification)
+	mux.HandleFunc("/api/bookings/modifications/reject", h.RejectModification)
+	mux.HandleFunc("/api/bookings/disputes", h.GetDisputes)
+	mux.HandleFunc("/api/bookings/disputes/respond", h.RespondToDispute)
+	mux.HandleFunc("/api/bookings/disputes/resolve", h.ResolveDispute)
 }
 
 func (h *HTTPHandler) Health(w http.ResponseWriter, r *http.Request) {
@@ -57,15 +81,80 @@ func (h *HTTPHandler) HandleBookings(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CreateQuote prices a rental, day by day under the item's pricing rules, and
+// any add-ons chosen with it. The returned quote token is what CreateBooking
+// accepts.
+func (h *HTTPHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		RenterID     string                 `json:"renter_id"`
+		RentalItemID string                 `json:"rental_item_id"`
+		StartDate    string                 `json:"start_date"`
+		EndDate      string                 `json:"end_date"`
+		AddOns       []domain.SelectedAddOn `json:"add_ons"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	renterID, err := uuid.Parse(req.RenterID)
+	if err != nil {
+		http.Error(w, "Invalid renter_id", http.StatusBadRequest)
+		return
+	}
+	rentalItemID, err := uuid.Parse(req.RentalItemID)
+	if err != nil {
+		http.Error(w, "Invalid rental_item_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
+		return
+	}
+
+	quote, token, err := h.bookingService.CreateQuote(r.Context(), renterID, rentalItemID, startDate, endDate, req.AddOns)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"quote":            token,
+		"rental_item_id":   quote.RentalItemID.String(),
+		"start_date":       quote.StartDate,
+		"end_date":         quote.EndDate,
+		"total_days":       quote.TotalDays,
+		"days":             quote.Days,
+		"add_ons":          quote.AddOns,
+		"lines":            quote.Lines,
+		"add_ons_total":    quote.AddOnsTotal,
+		"subtotal":         quote.Subtotal,
+		"service_fee":      quote.ServiceFee,
+		"security_deposit": quote.SecurityDeposit,
+		"total_amount":     quote.TotalAmount,
+		"expires_at":       quote.ExpiresAt,
+	})
+}
+
 func (h *HTTPHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		RenterID        string  `json:"renter_id"`
-		OwnerID         string  `json:"owner_id"`
-		RentalItemID    string  `json:"rental_item_id"`
-		StartDate       string  `json:"start_date"`
-		EndDate         string  `json:"end_date"`
-		DailyRate       float64 `json:"daily_rate"`
-		SecurityDeposit float64 `json:"security_deposit"`
+		RenterID      string `json:"renter_id"`
+		Quote         string `json:"quote"`
+		PaymentMethod string `json:"payment_method"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -73,13 +162,21 @@ func (h *HTTPHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	renterID, _ := uuid.Parse(req.RenterID)
-	ownerID, _ := uuid.Parse(req.OwnerID)
-	rentalItemID, _ := uuid.Parse(req.RentalItemID)
-	startDate, _ := time.Parse("2006-01-02", req.StartDate)
-	endDate, _ := time.Parse("2006-01-02", req.EndDate)
+	renterID, err := uuid.Parse(req.RenterID)
+	if err != nil {
+		http.Error(w, "Invalid renter_id", http.StatusBadRequest)
+		return
+	}
+	if req.Quote == "" {
+		http.Error(w, "quote required", http.StatusBadRequest)
+		return
+	}
+
+	if req.PaymentMethod == "" {
+		req.PaymentMethod = "chapa"
+	}
 
-	booking, err := h.bookingService.CreateBooking(r.Context(), renterID, ownerID, rentalItemID, startDate, endDate, req.DailyRate, req.SecurityDeposit)
+	booking, err := h.bookingService.CreateBooking(r.Context(), renterID, req.Quote, req.PaymentMethod)
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -94,6 +191,8 @@ func (h *HTTPHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 		"total_amount":   booking.TotalAmount,
 		"start_date":     booking.StartDate,
 		"end_date":       booking.EndDate,
+		"payment_id":     booking.PaymentID,
+		"checkout_url":   booking.CheckoutURL,
 	})
 }
 
@@ -112,57 +211,119 @@ func (h *HTTPHandler) GetBooking(w http.ResponseWriter, r *http.Request, booking
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"id":               booking.ID.String(),
-		"booking_number":   booking.BookingNumber,
-		"renter_id":        booking.RenterID.String(),
-		"owner_id":         booking.OwnerID.String(),
-		"rental_item_id":   booking.RentalItemID.String(),
-		"status":           booking.Status,
-		"start_date":       booking.StartDate,
-		"end_date":         booking.EndDate,
-		"total_days":       booking.TotalDays,
-		"daily_rate":       booking.DailyRate,
-		"total_amount":     booking.TotalAmount,
-		"agreement_signed": booking.AgreementSigned,
+		"id":                booking.ID.String(),
+		"booking_number":    booking.BookingNumber,
+		"renter_id":         booking.RenterID.String(),
+		"owner_id":          booking.OwnerID.String(),
+		"rental_item_id":    booking.RentalItemID.String(),
+		"status":            booking.Status,
+		"start_date":        booking.StartDate,
+		"end_date":          booking.EndDate,
+		"total_days":        booking.TotalDays,
+		"daily_rate":        booking.DailyRate,
+		"add_ons":           booking.AddOns,
+		"price_breakdown":   booking.PriceBreakdown,
+		"subtotal":          booking.Subtotal,
+		"service_fee":       booking.ServiceFee,
+		"security_deposit":  booking.SecurityDeposit,
+		"total_amount":      booking.TotalAmount,
+		"agreement_signed":  booking.AgreementSigned,
+		"agreement_url":     booking.AgreementURL,
+		"response_deadline": booking.ResponseDeadline,
+		"payment_deadline":  booking.PaymentDeadline,
+		"pickup_time":       booking.PickupTime,
+		"unit_id":           booking.UnitID,
+		"return_time":       booking.ReturnTime,
+		"status_history":    booking.StatusHistory,
 	})
 }
 
+// GetRenterBookings lists a renter's bookings a page at a time. Pass the
+// returned next_cursor as cursor to fetch the following page.
 func (h *HTTPHandler) GetRenterBookings(w http.ResponseWriter, r *http.Request) {
-	renterID := r.URL.Query().Get("renter_id")
-	rid, _ := uuid.Parse(renterID)
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	rid, err := uuid.Parse(r.URL.Query().Get("renter_id"))
+	if err != nil {
+		http.Error(w, "Invalid renter_id", http.StatusBadRequest)
+		return
+	}
 
-	bookings, total, err := h.bookingService.GetRenterBookings(r.Context(), rid, page, pageSize)
+	query, err := parseBookingListQuery(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	page, err := h.bookingService.GetRenterBookings(r.Context(), rid, query)
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"bookings": bookings,
-		"total":    total,
-	})
+	json.NewEncoder(w).Encode(page)
 }
 
+// GetOwnerBookings lists bookings of an owner's items a page at a time
 func (h *HTTPHandler) GetOwnerBookings(w http.ResponseWriter, r *http.Request) {
-	ownerID := r.URL.Query().Get("owner_id")
-	oid, _ := uuid.Parse(ownerID)
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	oid, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parseBookingListQuery(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
 
-	bookings, total, err := h.bookingService.GetOwnerBookings(r.Context(), oid, page, pageSize)
+	page, err := h.bookingService.GetOwnerBookings(r.Context(), oid, query)
 	if err != nil {
 		h.handleError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"bookings": bookings,
-		"total":    total,
-	})
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseBookingListQuery reads the listing parameters: view, status (comma
+// separated), item_id, from and to for a date range, cursor and limit
+func parseBookingListQuery(r *http.Request) (service.BookingListQuery, error) {
+	params := r.URL.Query()
+	query := service.BookingListQuery{
+		View:   domain.BookingView(params.Get("view")),
+		Cursor: params.Get("cursor"),
+	}
+	query.Limit, _ = strconv.Atoi(params.Get("limit"))
+
+	if status := params.Get("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			query.Statuses = append(query.Statuses, domain.BookingStatus(strings.TrimSpace(s)))
+		}
+	}
+	if itemID := params.Get("item_id"); itemID != "" {
+		id, err := uuid.Parse(itemID)
+		if err != nil {
+			return query, domain.ErrInvalidFilter
+		}
+		query.RentalItemID = &id
+	}
+	if from := params.Get("from"); from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return query, domain.ErrInvalidDates
+		}
+		query.From = &t
+	}
+	if to := params.Get("to"); to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return query, domain.ErrInvalidDates
+		}
+		query.To = &t
+	}
+	return query, nil
 }
 
 func (h *HTTPHandler) ConfirmBooking(w http.ResponseWriter, r *http.Request) {
@@ -227,22 +388,634 @@ func (h *HTTPHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"id":     booking.ID.String(),
 		"status": booking.Status,
+		"refund": booking.Refund,
+	})
+}
+
+func (h *HTTPHandler) PreviewCancellation(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	userID, _ := uuid.Parse(r.URL.Query().Get("user_id"))
+
+	refund, err := h.bookingService.PreviewCancellation(r.Context(), bookingID, userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(refund)
+}
+
+func (h *HTTPHandler) RejectBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		BookingID string `json:"booking_id"`
+		OwnerID   string `json:"owner_id"`
+		Reason    string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, _ := uuid.Parse(req.BookingID)
+	ownerID, _ := uuid.Parse(req.OwnerID)
+
+	booking, err := h.bookingService.RejectBooking(r.Context(), bookingID, ownerID, req.Reason)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     booking.ID.String(),
+		"status": booking.Status,
+	})
+}
+
+// handoverRequest is the body for check-out (pickup) and check-in (return)
+type handoverRequest struct {
+	BookingID string `json:"booking_id"`
+	OwnerID   string `json:"owner_id"`
+	Notes     string `json:"notes"`
+}
+
+func (h *HTTPHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// unit_id optionally names the unit handed over, for items with several
+	var req struct {
+		handoverRequest
+		UnitID string `json:"unit_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, _ := uuid.Parse(req.BookingID)
+	ownerID, _ := uuid.Parse(req.OwnerID)
+
+	var unitID *uuid.UUID
+	if req.UnitID != "" {
+		id, err := uuid.Parse(req.UnitID)
+		if err != nil {
+			http.Error(w, "Invalid unit_id", http.StatusBadRequest)
+			return
+		}
+		unitID = &id
+	}
+
+	booking, err := h.bookingService.CheckOut(r.Context(), bookingID, ownerID, req.Notes, unitID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":          booking.ID.String(),
+		"status":      booking.Status,
+		"pickup_time": booking.PickupTime,
+		"unit_id":     booking.UnitID,
+	})
+}
+
+// CheckIn completes the rental. The owner may include a damage claim, which
+// opens a dispute over the security deposit.
+func (h *HTTPHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		handoverRequest
+		DamageClaim *service.DamageClaimRequest `json:"damage_claim"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, _ := uuid.Parse(req.BookingID)
+	ownerID, _ := uuid.Parse(req.OwnerID)
+
+	booking, dispute, err := h.bookingService.CheckIn(r.Context(), bookingID, ownerID, req.Notes, req.DamageClaim)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"id":             booking.ID.String(),
+		"status":         booking.Status,
+		"return_time":    booking.ReturnTime,
+		"deposit_status": booking.DepositStatus,
+	}
+	if dispute != nil {
+		resp["dispute"] = dispute
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *HTTPHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	userID, _ := uuid.Parse(r.URL.Query().Get("user_id"))
+
+	history, err := h.bookingService.GetStatusHistory(r.Context(), id, userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"booking_id": id.String(),
+		"history":    history,
+	})
+}
+
+// GetAgreement returns the rental agreement as JSON metadata, or as the
+// document itself with format=html or format=pdf. Like the other booking
+// routes it is only served to the renter or owner named by user_id, so
+// clients link to it with the viewer's own user_id.
+func (h *HTTPHandler) GetAgreement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	bookingID, err := uuid.Parse(r.URL.Query().Get("booking_id"))
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	userID, err := uuid.Parse(r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	agreement, err := h.agreementService.GetAgreement(r.Context(), bookingID, userID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(agreement.HTML))
+	case "pdf":
+		pdf, err := h.agreementService.RenderPDF(agreement)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", "inline; filename=\"agreement-"+agreement.Terms.BookingNumber+".pdf\"")
+		w.Write(pdf)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":           agreement.ID.String(),
+			"booking_id":   agreement.BookingID.String(),
+			"revision":     agreement.Revision,
+			"content_hash": agreement.ContentHash,
+			"terms":        agreement.Terms,
+			"signatures":   agreement.Signatures,
+			"fully_signed": agreement.FullySigned(),
+			"created_at":   agreement.CreatedAt,
+		})
+	}
+}
+
+func (h *HTTPHandler) SignAgreement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		BookingID   string `json:"booking_id"`
+		UserID      string `json:"user_id"`
+		ContentHash string `json:"content_hash"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+	if req.ContentHash == "" {
+		http.Error(w, "content_hash required", http.StatusBadRequest)
+		return
+	}
+
+	agreement, err := h.agreementService.SignAgreement(r.Context(), bookingID, userID, req.ContentHash, clientIP(r), r.UserAgent())
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":           agreement.ID.String(),
+		"booking_id":   agreement.BookingID.String(),
+		"content_hash": agreement.ContentHash,
+		"signatures":   agreement.Signatures,
+		"fully_signed": agreement.FullySigned(),
+	})
+}
+
+// clientIP is the caller's address, taken from the proxy headers set by the
+// gateway when present
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		first, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(first)
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (h *HTTPHandler) HandleModifications(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.ProposeModification(w, r)
+	case http.MethodGet:
+		h.GetModifications(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) ProposeModification(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		BookingID string `json:"booking_id"`
+		RenterID  string `json:"renter_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		Reason    string `json:"reason"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	renterID, err := uuid.Parse(req.RenterID)
+	if err != nil {
+		http.Error(w, "Invalid renter_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
+		return
+	}
+
+	modification, err := h.bookingService.ProposeModification(r.Context(), bookingID, renterID, startDate, endDate, req.Reason)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(modification)
+}
+
+func (h *HTTPHandler) GetModifications(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.URL.Query().Get("booking_id"))
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+
+	modifications, err := h.bookingService.GetModifications(r.Context(), id)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"booking_id":    id.String(),
+		"modifications": modifications,
+	})
+}
+
+// modificationResponse is the body for accepting or rejecting a modification
+type modificationResponse struct {
+	BookingID      string `json:"booking_id"`
+	ModificationID string `json:"modification_id"`
+	OwnerID        string `json:"owner_id"`
+	Reason         string `json:"reason"`
+}
+
+func (h *HTTPHandler) AcceptModification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req modificationResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, _ := uuid.Parse(req.BookingID)
+	modificationID, _ := uuid.Parse(req.ModificationID)
+	ownerID, _ := uuid.Parse(req.OwnerID)
+
+	booking, err := h.bookingService.AcceptModification(r.Context(), bookingID, modificationID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":           booking.ID.String(),
+		"status":       booking.Status,
+		"revision":     booking.Revision,
+		"start_date":   booking.StartDate,
+		"end_date":     booking.EndDate,
+		"total_amount": booking.TotalAmount,
+		"modification": booking.Modifications[len(booking.Modifications)-1],
+	})
+}
+
+func (h *HTTPHandler) RejectModification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req modificationResponse
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, _ := uuid.Parse(req.BookingID)
+	modificationID, _ := uuid.Parse(req.ModificationID)
+	ownerID, _ := uuid.Parse(req.OwnerID)
+
+	booking, err := h.bookingService.RejectModification(r.Context(), bookingID, modificationID, ownerID, req.Reason)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":       booking.ID.String(),
+		"status":   booking.Status,
+		"revision": booking.Revision,
 	})
 }
 
+// GetDisputes returns a dispute by id or booking_id to one of its parties or an
+// admin, or lists disputes by status for an admin
+func (h *HTTPHandler) GetDisputes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	userID, err := uuid.Parse(query.Get("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user_id", http.StatusBadRequest)
+		return
+	}
+
+	var dispute *domain.Dispute
+	switch {
+	case query.Get("id") != "":
+		id, err := uuid.Parse(query.Get("id"))
+		if err != nil {
+			http.Error(w, "Invalid id", http.StatusBadRequest)
+			return
+		}
+		dispute, err = h.disputeService.GetDispute(r.Context(), id, userID)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+	case query.Get("booking_id") != "":
+		bookingID, err := uuid.Parse(query.Get("booking_id"))
+		if err != nil {
+			http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+			return
+		}
+		dispute, err = h.disputeService.GetBookingDispute(r.Context(), bookingID, userID)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+	default:
+		page, _ := strconv.Atoi(query.Get("page"))
+		pageSize, _ := strconv.Atoi(query.Get("page_size"))
+		status := domain.DisputeStatus(query.Get("status"))
+
+		disputes, total, err := h.disputeService.ListDisputes(r.Context(), userID, status, page, pageSize)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"disputes": disputes,
+			"total":    total,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// RespondToDispute records the renter accepting or contesting a damage claim
+func (h *HTTPHandler) RespondToDispute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		DisputeID string   `json:"dispute_id"`
+		RenterID  string   `json:"renter_id"`
+		Message   string   `json:"message"`
+		PhotoURLs []string `json:"photo_urls"`
+		Accept    bool     `json:"accept"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	disputeID, err := uuid.Parse(req.DisputeID)
+	if err != nil {
+		http.Error(w, "Invalid dispute_id", http.StatusBadRequest)
+		return
+	}
+	renterID, err := uuid.Parse(req.RenterID)
+	if err != nil {
+		http.Error(w, "Invalid renter_id", http.StatusBadRequest)
+		return
+	}
+
+	dispute, err := h.disputeService.RespondToDispute(r.Context(), disputeID, renterID, req.Message, req.PhotoURLs, req.Accept)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// ResolveDispute records an admin's decision on how much of the deposit the
+// owner keeps
+func (h *HTTPHandler) ResolveDispute(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		DisputeID      string  `json:"dispute_id"`
+		AdminID        string  `json:"admin_id"`
+		CapturedAmount float64 `json:"captured_amount"`
+		Notes          string  `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	disputeID, err := uuid.Parse(req.DisputeID)
+	if err != nil {
+		http.Error(w, "Invalid dispute_id", http.StatusBadRequest)
+		return
+	}
+	adminID, err := uuid.Parse(req.AdminID)
+	if err != nil {
+		http.Error(w, "Invalid admin_id", http.StatusBadRequest)
+		return
+	}
+
+	dispute, err := h.disputeService.ResolveDispute(r.Context(), disputeID, adminID, req.CapturedAmount, req.Notes)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dispute)
+}
+
 func (h *HTTPHandler) handleError(w http.ResponseWriter, err error) {
 	w.Header().Set("Content-Type", "application/json")
 
+	var rulesErr *domain.RulesViolatedError
+	if errors.As(err, &rulesErr) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   err.Error(),
+			"reasons": rulesErr.Violations,
+		})
+		return
+	}
+
+	var statusErr *clients.StatusError
+	if errors.As(err, &statusErr) {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	switch err {
-	case domain.ErrBookingNotFound:
+	case domain.ErrBookingNotFound, domain.ErrItemNotFound, domain.ErrModificationNotFound,
+		domain.ErrUserNotFound, domain.ErrAgreementNotFound, domain.ErrDisputeNotFound:
 		w.WriteHeader(http.StatusNotFound)
 	case domain.ErrUnauthorized:
 		w.WriteHeader(http.StatusForbidden)
-	case domain.ErrInvalidStatus, domain.ErrInvalidDates:
+	case domain.ErrInvalidStatus, domain.ErrInvalidDates, domain.ErrInvalidQuote, domain.ErrInvalidModification,
+		domain.ErrInvalidAddOn, domain.ErrInvalidClaim, domain.ErrInvalidFilter, domain.ErrInvalidCursor:
 		w.WriteHeader(http.StatusBadRequest)
+	case domain.ErrQuoteExpired:
+		w.WriteHeader(http.StatusGone)
+	case domain.ErrItemUnavailable, domain.ErrInvalidPrice:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	case domain.ErrDateConflict, domain.ErrAddOnUnavailable, domain.ErrInvalidTransition, domain.ErrBookingChanged, domain.ErrAlreadyCancelled, domain.ErrCannotCancel,
+		domain.ErrCannotModify, domain.ErrModificationPending, domain.ErrModificationStale, domain.ErrPaymentNotCompleted,
+		domain.ErrAgreementNotSigned, domain.ErrAgreementOutdated, domain.ErrAlreadySigned, domain.ErrDisputeClosed, domain.ErrDepositNotHeld:
+		w.WriteHeader(http.StatusConflict)
+	case domain.ErrSagaTimedOut:
+		w.WriteHeader(http.StatusGatewayTimeout)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 
 	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 }
+
+// parseDate accepts either an RFC3339 timestamp or a plain YYYY-MM-DD date
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
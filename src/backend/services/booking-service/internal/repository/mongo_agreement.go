@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoAgreementRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoAgreementRepository(db *mongo.Database) *MongoAgreementRepository {
+	return &MongoAgreementRepository{
+		coll: db.Collection("rental_agreements"),
+	}
+}
+
+// EnsureIndexes creates the unique index that keeps concurrent first requests
+// from drawing up two agreements for the same booking revision
+func (r *MongoAgreementRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "booking_id", Value: 1}, {Key: "revision", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// GetOrCreate stores the agreement unless one already exists for the same
+// booking revision, and returns whichever is stored
+func (r *MongoAgreementRepository) GetOrCreate(ctx context.Context, agreement *domain.Agreement) (*domain.Agreement, error) {
+	filter := bson.M{"booking_id": agreement.BookingID, "revision": agreement.Revision}
+	update := bson.M{"$setOnInsert": agreement}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stored domain.Agreement
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&stored)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent upsert inserted it first
+		return r.GetByBooking(ctx, agreement.BookingID, agreement.Revision)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (r *MongoAgreementRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID, revision int) (*domain.Agreement, error) {
+	var agreement domain.Agreement
+	err := r.coll.FindOne(ctx, bson.M{"booking_id": bookingID, "revision": revision}).Decode(&agreement)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrAgreementNotFound
+		}
+		return nil, err
+	}
+	return &agreement, nil
+}
+
+// AddSignature appends a signature unless that party has already signed, which
+// keeps concurrent signing from recording the same party twice
+func (r *MongoAgreementRepository) AddSignature(ctx context.Context, agreementID uuid.UUID, signature domain.Signature) (*domain.Agreement, error) {
+	filter := bson.M{
+		"_id":             agreementID,
+		"signatures.role": bson.M{"$ne": signature.Role},
+	}
+	update := bson.M{"$push": bson.M{"signatures": signature}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var agreement domain.Agreement
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&agreement)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrAlreadySigned
+		}
+		return nil, err
+	}
+	return &agreement, nil
+}
@@ -22,11 +22,33 @@ func NewMongoBookingRepository(db *mongo.Database) *MongoBookingRepository {
 	}
 }
 
-func (r *MongoBookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
-	_, err := r.coll.InsertOne(ctx, booking)
+// EnsureIndexes creates the indexes behind the renter and owner listings. Each
+// party has one index for unfiltered lists and one for status views, both
+// ending in the (created_at, _id) sort the cursors page through.
+func (r *MongoBookingRepository) EnsureIndexes(ctx context.Context) error {
+	listOrder := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	withPrefix := func(prefix ...bson.E) bson.D {
+		return append(bson.D(prefix), listOrder...)
+	}
+
+	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: withPrefix(bson.E{Key: "renter_id", Value: 1})},
+		{Keys: withPrefix(bson.E{Key: "renter_id", Value: 1}, bson.E{Key: "status", Value: 1})},
+		{Keys: withPrefix(bson.E{Key: "owner_id", Value: 1})},
+		{Keys: withPrefix(bson.E{Key: "owner_id", Value: 1}, bson.E{Key: "status", Value: 1})},
+		{Keys: withPrefix(bson.E{Key: "owner_id", Value: 1}, bson.E{Key: "rental_item_id", Value: 1})},
+	})
 	return err
 }
 
+func (r *MongoBookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
+	if _, err := r.coll.InsertOne(ctx, booking); err != nil {
+		return err
+	}
+	booking.ClearPendingHistory()
+	return nil
+}
+
 func (r *MongoBookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Booking, error) {
 	var booking domain.Booking
 	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&booking)
@@ -39,74 +61,186 @@ func (r *MongoBookingRepository) GetByID(ctx context.Context, id uuid.UUID) (*do
 	return &booking, nil
 }
 
-func (r *MongoBookingRepository) GetByRenter(ctx context.Context, renterID uuid.UUID, offset, limit int) ([]*domain.Booking, int, error) {
-	filter := bson.M{"renter_id": renterID}
+// GetByRenter returns a page of the renter's bookings, newest first, starting
+// after the cursor
+func (r *MongoBookingRepository) GetByRenter(ctx context.Context, renterID uuid.UUID, filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error) {
+	return r.list(ctx, bson.M{"renter_id": renterID}, filter, after, limit)
+}
 
-	total, err := r.coll.CountDocuments(ctx, filter)
-	if err != nil {
-		return nil, 0, err
+// GetByOwner returns a page of the owner's bookings, newest first, starting
+// after the cursor
+func (r *MongoBookingRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID, filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error) {
+	return r.list(ctx, bson.M{"owner_id": ownerID}, filter, after, limit)
+}
+
+// list pages through bookings by (created_at, _id) rather than skipping, so
+// every page costs the same however deep the client has scrolled
+func (r *MongoBookingRepository) list(ctx context.Context, query bson.M, filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error) {
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if filter.RentalItemID != nil {
+		query["rental_item_id"] = *filter.RentalItemID
+	}
+	if filter.To != nil {
+		query["start_date"] = bson.M{"$lt": *filter.To}
+	}
+	if filter.From != nil {
+		query["end_date"] = bson.M{"$gt": *filter.From}
+	}
+	if after != nil {
+		query["$or"] = []bson.M{
+			{"created_at": bson.M{"$lt": after.CreatedAt}},
+			{"created_at": after.CreatedAt, "_id": bson.M{"$lt": after.ID}},
+		}
 	}
 
 	opts := options.Find().
-		SetSort(bson.M{"created_at": -1}).
-		SetSkip(int64(offset)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
 		SetLimit(int64(limit))
 
-	cursor, err := r.coll.Find(ctx, filter, opts)
+	cursor, err := r.coll.Find(ctx, query, opts)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer cursor.Close(ctx)
 
 	var bookings []*domain.Booking
 	if err := cursor.All(ctx, &bookings); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-	return bookings, int(total), nil
+	return bookings, nil
 }
 
-func (r *MongoBookingRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID, offset, limit int) ([]*domain.Booking, int, error) {
-	filter := bson.M{"owner_id": ownerID}
+// Update saves the booking only if nobody else has saved it since it was read,
+// returning domain.ErrBookingChanged otherwise
+func (r *MongoBookingRepository) Update(ctx context.Context, booking *domain.Booking) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":              booking.Status,
+			"start_date":          booking.StartDate,
+			"end_date":            booking.EndDate,
+			"total_days":          booking.TotalDays,
+			"price_breakdown":     booking.PriceBreakdown,
+			"subtotal":            booking.Subtotal,
+			"service_fee":         booking.ServiceFee,
+			"total_amount":        booking.TotalAmount,
+			"revision":            booking.Revision,
+			"modifications":       booking.Modifications,
+			"agreement_signed":    booking.AgreementSigned,
+			"agreement_url":       booking.AgreementURL,
+			"cancelled_by":        booking.CancelledBy,
+			"cancellation_reason": booking.CancellationReason,
+			"refund":              booking.Refund,
+			"deposit_status":      booking.DepositStatus,
+			"payment_status":      booking.PaymentStatus,
+			"payment_id":          booking.PaymentID,
+			"payment_method":      booking.PaymentMethod,
+			"checkout_url":        booking.CheckoutURL,
+			"response_deadline":   booking.ResponseDeadline,
+			"payment_deadline":    booking.PaymentDeadline,
+			"release_pending":     booking.ReleasePending,
+			"settlement_pending":  booking.SettlementPending,
+			"pickup_time":         booking.PickupTime,
+			"pickup_notes":        booking.PickupNotes,
+			"unit_id":             booking.UnitID,
+			"return_time":         booking.ReturnTime,
+			"return_notes":        booking.ReturnNotes,
+			"updated_at":          time.Now(),
+			"version":             booking.Version + 1,
+			// Add other updatable fields as needed based on logic
+		},
+	}
+	// Status history is append-only: only new entries are pushed
+	if history := booking.PendingHistory(); len(history) > 0 {
+		update["$push"] = bson.M{
+			"status_history": bson.M{"$each": history},
+		}
+	}
 
-	total, err := r.coll.CountDocuments(ctx, filter)
+	// Bookings saved before versions were kept have none
+	var version interface{} = booking.Version
+	if booking.Version == 0 {
+		version = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": booking.ID, "version": version}, update)
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+	if result.MatchedCount == 0 {
+		count, err := r.coll.CountDocuments(ctx, bson.M{"_id": booking.ID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrBookingNotFound
+		}
+		return domain.ErrBookingChanged
 	}
+	booking.Version++
+	booking.ClearPendingHistory()
+	return nil
+}
 
+// ListDueForExpiry returns pending bookings past their response deadline,
+// unpaid confirmed bookings past their payment deadline and expired bookings
+// still holding dates or payments, oldest first
+func (r *MongoBookingRepository) ListDueForExpiry(ctx context.Context, now time.Time, limit int) ([]*domain.Booking, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{
+				"status":            domain.StatusPending,
+				"response_deadline": bson.M{"$lt": now},
+			},
+			{
+				"status":           domain.StatusConfirmed,
+				"payment_status":   bson.M{"$ne": domain.PaymentCompleted},
+				"payment_deadline": bson.M{"$lt": now},
+			},
+			{
+				"status":          bson.M{"$in": []domain.BookingStatus{domain.StatusExpired, domain.StatusCancelled}},
+				"release_pending": true,
+			},
+		},
+	}
 	opts := options.Find().
-		SetSort(bson.M{"created_at": -1}).
-		SetSkip(int64(offset)).
+		SetSort(bson.M{"created_at": 1}).
 		SetLimit(int64(limit))
 
 	cursor, err := r.coll.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer cursor.Close(ctx)
 
 	var bookings []*domain.Booking
 	if err := cursor.All(ctx, &bookings); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-	return bookings, int(total), nil
+	return bookings, nil
 }
 
-func (r *MongoBookingRepository) Update(ctx context.Context, booking *domain.Booking) error {
-	update := bson.M{
-		"$set": bson.M{
-			"status":           booking.Status,
-			"agreement_signed": booking.AgreementSigned,
-			"updated_at":       time.Now(),
-			// Add other updatable fields as needed based on logic
-		},
+// ListPendingSettlement returns bookings with an accepted modification still
+// to be settled that nobody has saved since updatedBefore, oldest first
+func (r *MongoBookingRepository) ListPendingSettlement(ctx context.Context, updatedBefore time.Time, limit int) ([]*domain.Booking, error) {
+	filter := bson.M{
+		"settlement_pending": true,
+		"updated_at":         bson.M{"$lt": updatedBefore},
 	}
+	opts := options.Find().
+		SetSort(bson.M{"updated_at": 1}).
+		SetLimit(int64(limit))
 
-	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": booking.ID}, update)
+	cursor, err := r.coll.Find(ctx, filter, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if result.MatchedCount == 0 {
-		return domain.ErrBookingNotFound
+	defer cursor.Close(ctx)
+
+	var bookings []*domain.Booking
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, err
 	}
-	return nil
+	return bookings, nil
 }
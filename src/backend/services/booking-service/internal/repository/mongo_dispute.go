@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoDisputeRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoDisputeRepository(db *mongo.Database) *MongoDisputeRepository {
+	return &MongoDisputeRepository{
+		coll: db.Collection("booking_disputes"),
+	}
+}
+
+// GetOrCreate stores the dispute unless the booking already has one, and
+// returns whichever is stored. A booking is checked in once, so it has at most
+// one dispute even if check-in is retried.
+func (r *MongoDisputeRepository) GetOrCreate(ctx context.Context, dispute *domain.Dispute) (*domain.Dispute, error) {
+	filter := bson.M{"booking_id": dispute.BookingID}
+	update := bson.M{"$setOnInsert": dispute}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var stored domain.Dispute
+	if err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&stored); err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (r *MongoDisputeRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Dispute, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoDisputeRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID) (*domain.Dispute, error) {
+	return r.findOne(ctx, bson.M{"booking_id": bookingID})
+}
+
+// ListByStatus returns disputes in the given status, oldest first so the
+// longest-waiting claims are reviewed first
+func (r *MongoDisputeRepository) ListByStatus(ctx context.Context, status domain.DisputeStatus, offset, limit int) ([]*domain.Dispute, int, error) {
+	filter := bson.M{"status": status}
+
+	total, err := r.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": 1}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var disputes []*domain.Dispute
+	if err := cursor.All(ctx, &disputes); err != nil {
+		return nil, 0, err
+	}
+	return disputes, int(total), nil
+}
+
+func (r *MongoDisputeRepository) Update(ctx context.Context, dispute *domain.Dispute) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":     dispute.Status,
+			"response":   dispute.Response,
+			"resolution": dispute.Resolution,
+			"updated_at": dispute.UpdatedAt,
+		},
+	}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": dispute.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrDisputeNotFound
+	}
+	return nil
+}
+
+func (r *MongoDisputeRepository) findOne(ctx context.Context, filter bson.M) (*domain.Dispute, error) {
+	var dispute domain.Dispute
+	if err := r.coll.FindOne(ctx, filter).Decode(&dispute); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrDisputeNotFound
+		}
+		return nil, err
+	}
+	return &dispute, nil
+}
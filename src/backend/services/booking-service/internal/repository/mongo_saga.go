@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoSagaRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoSagaRepository(db *mongo.Database) *MongoSagaRepository {
+	return &MongoSagaRepository{
+		coll: db.Collection("booking_sagas"),
+	}
+}
+
+// EnsureIndexes creates the index recovery looks for stale sagas with
+func (r *MongoSagaRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}, {Key: "updated_at", Value: 1}},
+	})
+	return err
+}
+
+func (r *MongoSagaRepository) Create(ctx context.Context, saga *domain.Saga) error {
+	_, err := r.coll.InsertOne(ctx, saga)
+	return err
+}
+
+func (r *MongoSagaRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Saga, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *MongoSagaRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID) (*domain.Saga, error) {
+	return r.findOne(ctx, bson.M{"booking_id": bookingID})
+}
+
+func (r *MongoSagaRepository) findOne(ctx context.Context, filter bson.M) (*domain.Saga, error) {
+	var saga domain.Saga
+	err := r.coll.FindOne(ctx, filter).Decode(&saga)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSagaNotFound
+		}
+		return nil, err
+	}
+	return &saga, nil
+}
+
+func (r *MongoSagaRepository) Update(ctx context.Context, saga *domain.Saga) error {
+	saga.UpdatedAt = time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"booking":       saga.Booking,
+			"status":        saga.Status,
+			"steps":         saga.Steps,
+			"error":         saga.Error,
+			"updated_at":    saga.UpdatedAt,
+			"claimed_until": saga.ClaimedUntil,
+		},
+	}
+
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": saga.ID, "claim_token": saga.ClaimToken}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSagaClaimLost
+	}
+	return nil
+}
+
+func (r *MongoSagaRepository) ClaimStale(ctx context.Context, updatedBefore, leaseUntil time.Time) (*domain.Saga, error) {
+	filter := bson.M{
+		"status":     bson.M{"$in": []domain.SagaStatus{domain.SagaRunning, domain.SagaCompensating}},
+		"updated_at": bson.M{"$lt": updatedBefore},
+		"$or": []bson.M{
+			{"claimed_until": bson.M{"$exists": false}},
+			{"claimed_until": bson.M{"$lt": time.Now()}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"claim_token": uuid.New(), "claimed_until": leaseUntil}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"updated_at": 1}).
+		SetReturnDocument(options.After)
+
+	var saga domain.Saga
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&saga)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &saga, nil
+}
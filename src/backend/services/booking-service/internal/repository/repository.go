@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rentalflow/booking-service/internal/domain"
@@ -10,7 +11,36 @@ import (
 type BookingRepository interface {
 	Create(ctx context.Context, booking *domain.Booking) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Booking, error)
-	GetByRenter(ctx context.Context, renterID uuid.UUID, offset, limit int) ([]*domain.Booking, int, error)
-	GetByOwner(ctx context.Context, ownerID uuid.UUID, offset, limit int) ([]*domain.Booking, int, error)
+	GetByRenter(ctx context.Context, renterID uuid.UUID, filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error)
+	GetByOwner(ctx context.Context, ownerID uuid.UUID, filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error)
 	Update(ctx context.Context, booking *domain.Booking) error
+	ListDueForExpiry(ctx context.Context, now time.Time, limit int) ([]*domain.Booking, error)
+	ListPendingSettlement(ctx context.Context, updatedBefore time.Time, limit int) ([]*domain.Booking, error)
+}
+
+type SagaRepository interface {
+	Create(ctx context.Context, saga *domain.Saga) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Saga, error)
+	GetByBooking(ctx context.Context, bookingID uuid.UUID) (*domain.Saga, error)
+	// Update saves the saga and renews its claim, failing with
+	// ErrSagaClaimLost if another run has claimed it since
+	Update(ctx context.Context, saga *domain.Saga) error
+	// ClaimStale claims the oldest running or compensating saga untouched
+	// since updatedBefore whose claim has run out, holding it until
+	// leaseUntil. It returns nil if there is none.
+	ClaimStale(ctx context.Context, updatedBefore, leaseUntil time.Time) (*domain.Saga, error)
+}
+
+type AgreementRepository interface {
+	GetOrCreate(ctx context.Context, agreement *domain.Agreement) (*domain.Agreement, error)
+	GetByBooking(ctx context.Context, bookingID uuid.UUID, revision int) (*domain.Agreement, error)
+	AddSignature(ctx context.Context, agreementID uuid.UUID, signature domain.Signature) (*domain.Agreement, error)
+}
+
+type DisputeRepository interface {
+	GetOrCreate(ctx context.Context, dispute *domain.Dispute) (*domain.Dispute, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Dispute, error)
+	GetByBooking(ctx context.Context, bookingID uuid.UUID) (*domain.Dispute, error)
+	ListByStatus(ctx context.Context, status domain.DisputeStatus, offset, limit int) ([]*domain.Dispute, int, error)
+	Update(ctx context.Context, dispute *domain.Dispute) error
 }
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/agreement"
+	"github.com/rentalflow/booking-service/internal/clients"
+	"github.com/rentalflow/booking-service/internal/domain"
+	"github.com/rentalflow/booking-service/internal/repository"
+)
+
+// AgreementService produces rental agreements and records their signatures
+type AgreementService struct {
+	bookingRepo     repository.BookingRepository
+	agreementRepo   repository.AgreementRepository
+	inventoryClient *clients.InventoryClient
+	userClient      *clients.UserClient
+}
+
+func NewAgreementService(bookingRepo repository.BookingRepository, agreementRepo repository.AgreementRepository, inventoryClient *clients.InventoryClient, userClient *clients.UserClient) *AgreementService {
+	return &AgreementService{
+		bookingRepo:     bookingRepo,
+		agreementRepo:   agreementRepo,
+		inventoryClient: inventoryClient,
+		userClient:      userClient,
+	}
+}
+
+// GetAgreement returns the agreement for the booking's current revision,
+// generating it on first request. Only the renter and owner may see it.
+func (s *AgreementService) GetAgreement(ctx context.Context, bookingID, userID uuid.UUID) (*domain.Agreement, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.RenterID != userID && booking.OwnerID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	return s.currentAgreement(ctx, booking)
+}
+
+// SignAgreement records the user's signature on the current agreement. Once
+// both parties have signed, the booking is marked signed and may go active.
+func (s *AgreementService) SignAgreement(ctx context.Context, bookingID, signerID uuid.UUID, contentHash, ipAddress, userAgent string) (*domain.Agreement, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status.IsTerminal() {
+		return nil, domain.ErrInvalidStatus
+	}
+
+	current, err := s.currentAgreement(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := current.Sign(booking, signerID, contentHash, ipAddress, userAgent); err != nil {
+		return nil, err
+	}
+
+	signature := current.Signatures[len(current.Signatures)-1]
+	signed, err := s.agreementRepo.AddSignature(ctx, current.ID, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if signed.FullySigned() && !booking.AgreementSigned {
+		_, err := modifyBooking(ctx, s.bookingRepo, booking.ID, func(stored *domain.Booking) error {
+			if stored.Revision != signed.Revision {
+				return domain.ErrAgreementOutdated
+			}
+			stored.AgreementSigned = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return signed, nil
+}
+
+// RenderPDF renders an agreement as a PDF document
+func (s *AgreementService) RenderPDF(a *domain.Agreement) ([]byte, error) {
+	return agreement.RenderPDF(a)
+}
+
+func (s *AgreementService) currentAgreement(ctx context.Context, booking *domain.Booking) (*domain.Agreement, error) {
+	existing, err := s.agreementRepo.GetByBooking(ctx, booking.ID, booking.Revision)
+	if err == nil {
+		return existing, nil
+	}
+	if err != domain.ErrAgreementNotFound {
+		return nil, err
+	}
+
+	// Agreements are only drawn up for bookings that can still go ahead
+	if booking.Status.IsTerminal() {
+		return nil, domain.ErrAgreementNotFound
+	}
+
+	terms, err := s.agreementTerms(ctx, booking)
+	if err != nil {
+		return nil, err
+	}
+	html, err := agreement.RenderHTML(*terms)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.agreementRepo.GetOrCreate(ctx, domain.NewAgreement(booking, *terms, html))
+}
+
+// agreementTerms gathers the booking's item and both parties' profiles
+func (s *AgreementService) agreementTerms(ctx context.Context, booking *domain.Booking) (*domain.AgreementTerms, error) {
+	item, err := s.inventoryClient.GetItem(ctx, booking.RentalItemID)
+	if err != nil {
+		return nil, err
+	}
+	renter, err := s.userClient.GetUser(ctx, booking.RenterID)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.userClient.GetUser(ctx, booking.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AgreementTerms{
+		BookingNumber: booking.BookingNumber,
+		Renter:        agreementParty(renter),
+		Owner:         agreementParty(owner),
+		Item: domain.AgreementItem{
+			ID:          item.ID,
+			Title:       item.Title,
+			Description: item.Description,
+			Address:     item.Address,
+			City:        item.City,
+		},
+		Terms:              booking.Terms(),
+		CancellationPolicy: booking.CancellationPolicy,
+	}, nil
+}
+
+func agreementParty(u *clients.User) domain.AgreementParty {
+	return domain.AgreementParty{
+		ID:    u.ID,
+		Name:  strings.TrimSpace(u.FirstName + " " + u.LastName),
+		Email: u.Email,
+		Phone: u.Phone,
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+)
+
+const (
+	defaultBookingPageSize = 20
+	maxBookingPageSize     = 100
+)
+
+// BookingListQuery selects a page of a renter's or owner's bookings. View and
+// Statuses may be combined, in which case a booking must match both.
+type BookingListQuery struct {
+	View         domain.BookingView
+	Statuses     []domain.BookingStatus
+	RentalItemID *uuid.UUID
+	From         *time.Time
+	To           *time.Time
+	Cursor       string
+	Limit        int
+}
+
+// BookingPage is one page of bookings with the cursor for the next page
+type BookingPage struct {
+	Bookings   []*domain.Booking `json:"bookings"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	HasMore    bool              `json:"has_more"`
+}
+
+func (s *BookingService) GetRenterBookings(ctx context.Context, renterID uuid.UUID, query BookingListQuery) (*BookingPage, error) {
+	return s.listBookings(ctx, query, func(filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error) {
+		return s.bookingRepo.GetByRenter(ctx, renterID, filter, after, limit)
+	})
+}
+
+func (s *BookingService) GetOwnerBookings(ctx context.Context, ownerID uuid.UUID, query BookingListQuery) (*BookingPage, error) {
+	return s.listBookings(ctx, query, func(filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error) {
+		return s.bookingRepo.GetByOwner(ctx, ownerID, filter, after, limit)
+	})
+}
+
+type bookingLister func(filter domain.BookingFilter, after *domain.BookingCursor, limit int) ([]*domain.Booking, error)
+
+func (s *BookingService) listBookings(ctx context.Context, query BookingListQuery, list bookingLister) (*BookingPage, error) {
+	limit := query.Limit
+	if limit < 1 || limit > maxBookingPageSize {
+		limit = defaultBookingPageSize
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, domain.ErrInvalidDates
+	}
+
+	statuses, err := filterStatuses(query.View, query.Statuses)
+	if err != nil {
+		return nil, err
+	}
+	if statuses != nil && len(statuses) == 0 {
+		// The view and the requested statuses have nothing in common
+		return &BookingPage{Bookings: []*domain.Booking{}}, nil
+	}
+
+	var after *domain.BookingCursor
+	if query.Cursor != "" {
+		if after, err = domain.DecodeBookingCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	filter := domain.BookingFilter{
+		Statuses:     statuses,
+		RentalItemID: query.RentalItemID,
+		From:         query.From,
+		To:           query.To,
+	}
+
+	// Fetching one extra booking tells us whether there is another page
+	bookings, err := list(filter, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &BookingPage{Bookings: bookings}
+	if len(bookings) > limit {
+		page.Bookings = bookings[:limit]
+		page.HasMore = true
+		page.NextCursor = domain.CursorAfter(page.Bookings[limit-1]).Encode()
+	}
+	if page.Bookings == nil {
+		page.Bookings = []*domain.Booking{}
+	}
+	return page, nil
+}
+
+// filterStatuses combines a view with explicitly requested statuses. It
+// returns nil when neither restricts the status.
+func filterStatuses(view domain.BookingView, requested []domain.BookingStatus) ([]domain.BookingStatus, error) {
+	for _, status := range requested {
+		if !status.IsValid() {
+			return nil, domain.ErrInvalidFilter
+		}
+	}
+	if view == "" {
+		return requested, nil
+	}
+
+	viewStatuses, err := view.Statuses()
+	if err != nil {
+		return nil, err
+	}
+	if len(requested) == 0 {
+		return viewStatuses, nil
+	}
+
+	statuses := []domain.BookingStatus{}
+	for _, status := range requested {
+		for _, v := range viewStatuses {
+			if status == v {
+				statuses = append(statuses, status)
+			}
+		}
+	}
+	return statuses, nil
+}
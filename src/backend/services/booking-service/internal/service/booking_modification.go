@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+)
+
+// ProposeModification re-prices the booking for new dates, keeping the rates
+// its add-ons were booked at, and asks the owner to accept the change. The
+// new dates must meet the owner's rules, as a new booking would, and be free
+// apart from the booking's own.
+func (s *BookingService) ProposeModification(ctx context.Context, bookingID, renterID uuid.UUID, startDate, endDate time.Time, reason string) (*domain.Modification, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.RenterID != renterID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	quote, err := s.quotes.Reprice(ctx, booking, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	modification, err := booking.ProposeModification(renterID, quote, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	violations, err := s.inventoryClient.ValidateBooking(ctx, booking.RentalItemID, &booking.ID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if violations = domain.ModificationViolations(violations, startDate.Equal(booking.StartDate)); len(violations) > 0 {
+		return nil, &domain.RulesViolatedError{Violations: violations}
+	}
+
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.modification_requested", booking)
+	}
+
+	return modification, nil
+}
+
+// AcceptModification moves the booking's dates in inventory and saves the new
+// terms as the next revision before settling the price difference. A
+// settlement that fails leaves the booking marked for SettleModifications to
+// retry, so the accepted change stands either way.
+func (s *BookingService) AcceptModification(ctx context.Context, bookingID, modificationID, ownerID uuid.UUID) (*domain.Booking, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	modification, err := booking.PendingModification(modificationID)
+	if err != nil {
+		return nil, err
+	}
+
+	proposed, previous := modification.Proposed, modification.Previous
+	if err := s.inventoryClient.RescheduleDates(ctx, booking.ID, proposed.StartDate, proposed.EndDate); err != nil {
+		return nil, err
+	}
+
+	booking.AcceptModification(modification, ownerID)
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		// Put the dates back so inventory matches the unchanged booking
+		s.inventoryClient.RescheduleDates(context.WithoutCancel(ctx), booking.ID, previous.StartDate, previous.EndDate)
+		return nil, err
+	}
+
+	if booking.SettlementPending {
+		settled, err := s.settle(context.WithoutCancel(ctx), booking)
+		if err != nil {
+			s.log.Warn().Err(err).Str("booking_id", booking.ID.String()).Msg("Failed to settle modification, will retry")
+		} else {
+			booking = settled
+		}
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.modified", booking)
+	}
+
+	return booking, nil
+}
+
+// settlementGrace is how long a settlement is left to the request that
+// accepted the modification before SettleModifications takes it over
+const settlementGrace = 2 * time.Minute
+
+// settlementBatchSize caps how many settlements are retried per check
+const settlementBatchSize = 50
+
+// SettleModifications retries the settlements of accepted modifications now
+// and then every interval until ctx is done
+func (s *BookingService) SettleModifications(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.settlePending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *BookingService) settlePending(ctx context.Context) {
+	bookings, err := s.bookingRepo.ListPendingSettlement(ctx, time.Now().Add(-settlementGrace), settlementBatchSize)
+	if err != nil {
+		s.log.Error().Err(err).Msg("Failed to list bookings awaiting settlement")
+		return
+	}
+
+	for _, booking := range bookings {
+		// Saving before settling claims the booking: a replica retrying it at
+		// the same time is refused the stale save
+		if err := s.bookingRepo.Update(ctx, booking); err != nil {
+			if err != domain.ErrBookingChanged {
+				s.log.Error().Err(err).Str("booking_id", booking.ID.String()).Msg("Failed to claim settlement")
+			}
+			continue
+		}
+		if _, err := s.settle(ctx, booking); err != nil {
+			s.log.Error().Err(err).Str("booking_id", booking.ID.String()).Msg("Failed to settle modification")
+		}
+	}
+}
+
+// settle charges or refunds the price difference of the booking's accepted
+// modification, then records the outcome and clears the booking's mark
+func (s *BookingService) settle(ctx context.Context, booking *domain.Booking) (*domain.Booking, error) {
+	modification := booking.SettlingModification()
+	if modification == nil {
+		return booking, nil
+	}
+
+	record, err := s.settleModification(ctx, booking, modification)
+	if err != nil {
+		return nil, err
+	}
+
+	return modifyBooking(ctx, s.bookingRepo, booking.ID, func(stored *domain.Booking) error {
+		settling := stored.SettlingModification()
+		if settling == nil || settling.ID != modification.ID {
+			return domain.ErrModificationNotFound
+		}
+		record(stored, settling)
+		stored.SettlementPending = false
+		return nil
+	})
+}
+
+// settleModification charges or refunds the price difference and returns how
+// to record the outcome on the booking. An unpaid booking simply has its open
+// payment replaced by one for the new total.
+func (s *BookingService) settleModification(ctx context.Context, booking *domain.Booking, modification *domain.Modification) (func(*domain.Booking, *domain.Modification), error) {
+	method := booking.PaymentMethod
+	if method == "" {
+		method = "chapa"
+	}
+
+	paid, err := s.paymentClient.IsBookingPaid(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !paid:
+		if err := s.paymentClient.VoidBookingPayments(ctx, booking.ID); err != nil {
+			return nil, err
+		}
+		payment, err := s.paymentClient.InitializePayment(ctx, booking.ID, booking.RenterID, modification.Proposed.TotalAmount, modification.Proposed.SecurityDeposit, method,
+			modification.Proposed.Charges())
+		if err != nil {
+			return nil, err
+		}
+		return func(b *domain.Booking, m *domain.Modification) {
+			b.PaymentID = &payment.PaymentID
+			b.PaymentStatus = payment.Status
+			b.CheckoutURL = payment.CheckoutURL
+			m.PaymentID = &payment.PaymentID
+			m.CheckoutURL = payment.CheckoutURL
+		}, nil
+
+	case modification.AmountDue > 0:
+		payment, err := s.paymentClient.InitializePayment(ctx, booking.ID, booking.RenterID, modification.AmountDue, 0, method, nil)
+		if err != nil {
+			return nil, err
+		}
+		return func(b *domain.Booking, m *domain.Modification) {
+			m.PaymentID = &payment.PaymentID
+			m.CheckoutURL = payment.CheckoutURL
+		}, nil
+
+	default:
+		if booking.PaymentID == nil {
+			return nil, domain.ErrPaymentNotCompleted
+		}
+		refund := -modification.AmountDue
+		if err := s.paymentClient.RefundPayment(ctx, *booking.PaymentID, refund); err != nil {
+			return nil, err
+		}
+		return func(b *domain.Booking, m *domain.Modification) {
+			m.RefundedAmount = refund
+		}, nil
+	}
+}
+
+// RejectModification declines a proposed change, leaving the booking as it was
+func (s *BookingService) RejectModification(ctx context.Context, bookingID, modificationID, ownerID uuid.UUID, reason string) (*domain.Booking, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	modification := booking.OpenModification()
+	if modification == nil || modification.ID != modificationID {
+		return nil, domain.ErrModificationNotFound
+	}
+
+	booking.RejectModification(modification, ownerID, reason)
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.modification_rejected", booking)
+	}
+
+	return booking, nil
+}
+
+func (s *BookingService) GetModifications(ctx context.Context, bookingID uuid.UUID) ([]domain.Modification, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	return booking.Modifications, nil
+}
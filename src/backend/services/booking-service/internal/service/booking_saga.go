@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/clients"
+	"github.com/rentalflow/booking-service/internal/domain"
+	"github.com/rentalflow/booking-service/internal/repository"
+	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+// SagaOrchestrator carries a new booking through inventory, booking and payment.
+// Each step is persisted as it completes; when a step fails or the saga runs out
+// of time, the completed steps are compensated in reverse order. Every step and
+// compensation is idempotent, so an interrupted saga can simply be run again.
+// A run holds a claim on the saga, renewed with every save, so that no two
+// runs drive the same saga at once.
+type SagaOrchestrator struct {
+	sagaRepo        repository.SagaRepository
+	bookingRepo     repository.BookingRepository
+	inventoryClient *clients.InventoryClient
+	paymentClient   *clients.PaymentClient
+	sagaTimeout     time.Duration
+	stepTimeout     time.Duration
+	log             zerolog.Logger
+}
+
+func NewSagaOrchestrator(
+	sagaRepo repository.SagaRepository,
+	bookingRepo repository.BookingRepository,
+	inventoryClient *clients.InventoryClient,
+	paymentClient *clients.PaymentClient,
+	sagaTimeout, stepTimeout time.Duration,
+) *SagaOrchestrator {
+	return &SagaOrchestrator{
+		sagaRepo:        sagaRepo,
+		bookingRepo:     bookingRepo,
+		inventoryClient: inventoryClient,
+		paymentClient:   paymentClient,
+		sagaTimeout:     sagaTimeout,
+		stepTimeout:     stepTimeout,
+		log:             logger.NewLogger("saga"),
+	}
+}
+
+// Start persists a saga for the booking and runs it. The returned error is the
+// step failure that caused the saga to be compensated, if any.
+func (o *SagaOrchestrator) Start(ctx context.Context, booking *domain.Booking, paymentMethod string) (*domain.Saga, error) {
+	// The saga must finish even if the caller goes away
+	ctx = context.WithoutCancel(ctx)
+
+	saga := domain.NewSaga(booking, paymentMethod, o.sagaTimeout)
+	saga.ClaimToken = uuid.New()
+	saga.ClaimedUntil = time.Now().Add(o.claimLease())
+	if err := o.sagaRepo.Create(ctx, saga); err != nil {
+		return nil, err
+	}
+
+	return saga, o.run(ctx, saga)
+}
+
+// sagaRecoveryBatchSize caps how many stale sagas are resumed per check
+const sagaRecoveryBatchSize = 50
+
+// Recover resumes incomplete sagas now and then every interval until ctx is done.
+// Only sagas untouched for a while and no longer claimed are picked up, so sagas
+// still being driven by a request or another replica are left alone.
+func (o *SagaOrchestrator) Recover(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		o.resumeStale(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (o *SagaOrchestrator) resumeStale(ctx context.Context) {
+	for n := 0; n < sagaRecoveryBatchSize; n++ {
+		saga, err := o.sagaRepo.ClaimStale(ctx, time.Now().Add(-o.claimLease()), time.Now().Add(o.claimLease()))
+		if err != nil {
+			o.log.Error().Err(err).Msg("Failed to claim incomplete saga")
+			return
+		}
+		if saga == nil {
+			return
+		}
+
+		o.log.Info().Str("saga_id", saga.ID.String()).Str("status", string(saga.Status)).Msg("Resuming booking saga")
+		if err := o.run(ctx, saga); err != nil {
+			o.log.Warn().Err(err).Str("saga_id", saga.ID.String()).Msg("Resumed saga did not complete")
+		}
+	}
+}
+
+// claimLease is how long a run may go without saving before its claim runs out.
+// Every step is bounded by the step timeout and followed by a save.
+func (o *SagaOrchestrator) claimLease() time.Duration {
+	return 2 * o.stepTimeout
+}
+
+// save persists the saga and renews the run's claim on it
+func (o *SagaOrchestrator) save(ctx context.Context, saga *domain.Saga) error {
+	saga.ClaimedUntil = time.Now().Add(o.claimLease())
+	return o.sagaRepo.Update(ctx, saga)
+}
+
+// run drives the saga forward, or back once a step has failed. A run that
+// loses its claim stops at once and leaves the saga to the run that took it.
+func (o *SagaOrchestrator) run(ctx context.Context, saga *domain.Saga) error {
+	var failure error
+
+	if saga.Status == domain.SagaRunning {
+		failure = o.forward(ctx, saga)
+		if failure == nil {
+			saga.Status = domain.SagaCompleted
+			return o.save(ctx, saga)
+		}
+		if failure == domain.ErrSagaClaimLost {
+			return failure
+		}
+
+		saga.Status = domain.SagaCompensating
+		saga.Error = failure.Error()
+		if err := o.save(ctx, saga); err != nil {
+			if err == domain.ErrSagaClaimLost {
+				return err
+			}
+			o.log.Error().Err(err).Str("saga_id", saga.ID.String()).Msg("Failed to persist saga failure")
+		}
+	}
+
+	if saga.Status == domain.SagaCompensating {
+		if failure == nil {
+			failure = errors.New(saga.Error)
+		}
+		if err := o.compensate(ctx, saga); err != nil {
+			o.log.Error().Err(err).Str("saga_id", saga.ID.String()).Msg("Saga compensation incomplete, will retry")
+		}
+	}
+
+	return failure
+}
+
+func (o *SagaOrchestrator) forward(ctx context.Context, saga *domain.Saga) error {
+	for _, name := range domain.SagaSteps {
+		if saga.Step(name).Status == domain.StepDone {
+			continue
+		}
+		if saga.Expired() {
+			return domain.ErrSagaTimedOut
+		}
+
+		if err := o.execute(ctx, saga, name); err != nil {
+			saga.SetStep(name, domain.StepFailed, err)
+			return err
+		}
+
+		saga.SetStep(name, domain.StepDone, nil)
+		if err := o.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxUpdateAttempts bounds how often a change is applied again to a fresh copy
+// of a booking that others keep saving
+const maxUpdateAttempts = 3
+
+// modifyBooking reads the booking, applies the change and saves it, starting
+// over from a fresh read if someone else saved the booking in between
+func modifyBooking(ctx context.Context, repo repository.BookingRepository, id uuid.UUID, change func(*domain.Booking) error) (*domain.Booking, error) {
+	for attempt := 1; ; attempt++ {
+		booking, err := repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := change(booking); err != nil {
+			return nil, err
+		}
+		err = repo.Update(ctx, booking)
+		if err == domain.ErrBookingChanged && attempt < maxUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return booking, nil
+	}
+}
+
+func (o *SagaOrchestrator) execute(ctx context.Context, saga *domain.Saga, name domain.SagaStepName) error {
+	ctx, cancel := context.WithTimeout(ctx, o.stepTimeout)
+	defer cancel()
+
+	booking := &saga.Booking
+
+	switch name {
+	case domain.StepReserveDates:
+		return o.inventoryClient.BlockDates(ctx, booking.RentalItemID, booking.ID, booking.StartDate, booking.EndDate, booking.AddOns)
+
+	case domain.StepCreateBooking:
+		if _, err := o.bookingRepo.GetByID(ctx, booking.ID); err == nil {
+			return nil
+		} else if err != domain.ErrBookingNotFound {
+			return err
+		}
+		return o.bookingRepo.Create(ctx, booking)
+
+	case domain.StepInitializePayment:
+		payment, err := o.paymentClient.InitializePayment(ctx, booking.ID, booking.RenterID, booking.TotalAmount, booking.SecurityDeposit, saga.PaymentMethod,
+			booking.Terms().Charges())
+		if err != nil {
+			return err
+		}
+		// The saga's copy of the booking may be stale when it is resumed
+		stored, err := modifyBooking(ctx, o.bookingRepo, booking.ID, func(stored *domain.Booking) error {
+			stored.PaymentID = &payment.PaymentID
+			stored.PaymentStatus = payment.Status
+			stored.CheckoutURL = payment.CheckoutURL
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		*booking = *stored
+		return nil
+	}
+	return nil
+}
+
+// compensate undoes every step that ran, including the one that failed, since a
+// failed call may still have taken effect downstream
+// maxCompensationAttempts bounds how often undoing a saga's steps is tried
+// before the saga is marked failed and left for someone to clear up by hand
+const maxCompensationAttempts = 5
+
+func (o *SagaOrchestrator) compensate(ctx context.Context, saga *domain.Saga) error {
+	for _, name := range saga.StepsToCompensate() {
+		if err := o.undo(ctx, saga, name); err != nil {
+			if saga.FailCompensation(name, err, maxCompensationAttempts) {
+				o.log.Error().Err(err).Str("saga_id", saga.ID.String()).Str("step", string(name)).
+					Msg("Giving up on saga compensation, needs manual clean-up")
+			}
+			if saveErr := o.save(ctx, saga); saveErr != nil {
+				return saveErr
+			}
+			return err
+		}
+
+		saga.SetStep(name, domain.StepCompensated, nil)
+		if err := o.save(ctx, saga); err != nil {
+			return err
+		}
+	}
+
+	saga.Status = domain.SagaCompensated
+	return o.save(ctx, saga)
+}
+
+func (o *SagaOrchestrator) undo(ctx context.Context, saga *domain.Saga, name domain.SagaStepName) error {
+	ctx, cancel := context.WithTimeout(ctx, o.stepTimeout)
+	defer cancel()
+
+	booking := &saga.Booking
+
+	switch name {
+	case domain.StepReserveDates:
+		return o.inventoryClient.ReleaseDates(ctx, booking.ID)
+
+	case domain.StepCreateBooking:
+		stored, err := o.bookingRepo.GetByID(ctx, booking.ID)
+		if err == domain.ErrBookingNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if stored.Status == domain.StatusCancelled {
+			return nil
+		}
+		reason := "booking could not be completed: " + saga.Error
+		if err := stored.TransitionTo(domain.StatusCancelled, nil, reason); err != nil {
+			return err
+		}
+		stored.CancellationReason = reason
+		if err := o.bookingRepo.Update(ctx, stored); err != nil {
+			return err
+		}
+		*booking = *stored
+		return nil
+
+	case domain.StepInitializePayment:
+		return o.paymentClient.VoidBookingPayments(ctx, booking.ID)
+	}
+	return nil
+}
@@ -5,32 +5,75 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/clients"
 	"github.com/rentalflow/booking-service/internal/domain"
 	"github.com/rentalflow/booking-service/internal/repository"
+	"github.com/rentalflow/rentalflow/pkg/logger"
 	"github.com/rentalflow/rentalflow/pkg/messaging"
+	"github.com/rs/zerolog"
 )
 
 type BookingService struct {
-	bookingRepo repository.BookingRepository
-	broker      *messaging.MessageBroker
+	bookingRepo     repository.BookingRepository
+	inventoryClient *clients.InventoryClient
+	paymentClient   *clients.PaymentClient
+	quotes          *QuoteService
+	sagas           *SagaOrchestrator
+	disputes        *DisputeService
+	broker          *messaging.MessageBroker
+	responseWindow  time.Duration
+	paymentWindow   time.Duration
+	log             zerolog.Logger
 }
 
-func NewBookingService(bookingRepo repository.BookingRepository, broker *messaging.MessageBroker) *BookingService {
+func NewBookingService(bookingRepo repository.BookingRepository, inventoryClient *clients.InventoryClient, paymentClient *clients.PaymentClient, quotes *QuoteService, sagas *SagaOrchestrator, disputes *DisputeService, broker *messaging.MessageBroker, responseWindow, paymentWindow time.Duration) *BookingService {
 	return &BookingService{
-		bookingRepo: bookingRepo,
-		broker:      broker,
+		bookingRepo:     bookingRepo,
+		inventoryClient: inventoryClient,
+		paymentClient:   paymentClient,
+		quotes:          quotes,
+		sagas:           sagas,
+		disputes:        disputes,
+		broker:          broker,
+		responseWindow:  responseWindow,
+		paymentWindow:   paymentWindow,
+		log:             logger.NewLogger("booking"),
 	}
 }
 
-func (s *BookingService) CreateBooking(ctx context.Context, renterID, ownerID, rentalItemID uuid.UUID, startDate, endDate time.Time, dailyRate, securityDeposit float64) (*domain.Booking, error) {
-	if endDate.Before(startDate) {
-		return nil, domain.ErrInvalidDates
+// CreateQuote prices renting an item over a date range for a renter
+func (s *BookingService) CreateQuote(ctx context.Context, renterID, rentalItemID uuid.UUID, startDate, endDate time.Time,
+	addOns []domain.SelectedAddOn) (*domain.Quote, string, error) {
+	return s.quotes.CreateQuote(ctx, renterID, rentalItemID, startDate, endDate, addOns)
+}
+
+// CreateBooking books the item at the price of a quote issued to the renter
+func (s *BookingService) CreateBooking(ctx context.Context, renterID uuid.UUID, quoteToken, paymentMethod string) (*domain.Booking, error) {
+	quote, err := s.quotes.RedeemQuote(quoteToken, renterID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The owner's rules may have changed since the quote was issued
+	violations, err := s.inventoryClient.ValidateBooking(ctx, quote.RentalItemID, nil, quote.StartDate, quote.EndDate)
+	if err != nil {
+		return nil, err
 	}
+	if len(violations) > 0 {
+		return nil, &domain.RulesViolatedError{Violations: violations}
+	}
+
+	booking := domain.NewBooking(quote)
+	booking.PaymentMethod = paymentMethod
+	booking.SetResponseDeadline(time.Now(), s.responseWindow)
 
-	booking := domain.NewBooking(renterID, ownerID, rentalItemID, startDate, endDate, dailyRate, securityDeposit)
-	if err := s.bookingRepo.Create(ctx, booking); err != nil {
+	// Dates, booking and payment are set up by the saga, which undoes whatever
+	// it managed if any of them fails
+	saga, err := s.sagas.Start(ctx, booking, paymentMethod)
+	if err != nil {
 		return nil, err
 	}
+	booking = &saga.Booking
 
 	// Publish event
 	if s.broker != nil {
@@ -44,29 +87,40 @@ func (s *BookingService) GetBooking(ctx context.Context, bookingID uuid.UUID) (*
 	return s.bookingRepo.GetByID(ctx, bookingID)
 }
 
-func (s *BookingService) GetRenterBookings(ctx context.Context, renterID uuid.UUID, page, pageSize int) ([]*domain.Booking, int, error) {
-	if page < 1 {
-		page = 1
+func (s *BookingService) ConfirmBooking(ctx context.Context, bookingID, ownerID uuid.UUID) (*domain.Booking, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+
+	if booking.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	if err := booking.TransitionTo(domain.StatusConfirmed, &ownerID, "confirmed by owner"); err != nil {
+		return nil, err
+	}
+	if !booking.IsPaid() {
+		booking.SetPaymentDeadline(time.Now(), s.paymentWindow)
 	}
-	offset := (page - 1) * pageSize
-	return s.bookingRepo.GetByRenter(ctx, renterID, offset, pageSize)
-}
 
-func (s *BookingService) GetOwnerBookings(ctx context.Context, ownerID uuid.UUID, page, pageSize int) ([]*domain.Booking, int, error) {
-	if page < 1 {
-		page = 1
+	// Only confirmed bookings are published in the item's calendar
+	if err := s.inventoryClient.ConfirmDates(ctx, booking.ID); err != nil {
+		return nil, err
 	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 20
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.confirmed", booking)
 	}
-	offset := (page - 1) * pageSize
-	return s.bookingRepo.GetByOwner(ctx, ownerID, offset, pageSize)
+
+	return booking, nil
 }
 
-func (s *BookingService) ConfirmBooking(ctx context.Context, bookingID, ownerID uuid.UUID) (*domain.Booking, error) {
+func (s *BookingService) RejectBooking(ctx context.Context, bookingID, ownerID uuid.UUID, reason string) (*domain.Booking, error) {
 	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
 	if err != nil {
 		return nil, err
@@ -76,18 +130,21 @@ func (s *BookingService) ConfirmBooking(ctx context.Context, bookingID, ownerID
 		return nil, domain.ErrUnauthorized
 	}
 
-	if booking.Status != domain.StatusPending {
-		return nil, domain.ErrInvalidStatus
+	if err := booking.TransitionTo(domain.StatusRejected, &ownerID, reason); err != nil {
+		return nil, err
+	}
+
+	if err := s.inventoryClient.ReleaseDates(ctx, booking.ID); err != nil {
+		return nil, err
 	}
 
-	booking.Status = domain.StatusConfirmed
 	if err := s.bookingRepo.Update(ctx, booking); err != nil {
 		return nil, err
 	}
 
 	// Publish event
 	if s.broker != nil {
-		s.broker.Publish(ctx, "booking_events", "booking.confirmed", booking)
+		s.broker.Publish(ctx, "booking_events", "booking.rejected", booking)
 	}
 
 	return booking, nil
@@ -107,13 +164,19 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID, userID uu
 		return nil, domain.ErrAlreadyCancelled
 	}
 
-	if booking.Status == domain.StatusCompleted {
+	// The refund depends on the status before cancelling
+	refund := domain.CalculateRefund(booking, cancelledBy(booking, userID), time.Now())
+
+	if err := booking.TransitionTo(domain.StatusCancelled, &userID, reason); err != nil {
 		return nil, domain.ErrCannotCancel
 	}
 
-	booking.Status = domain.StatusCancelled
+	// Saving the cancellation before touching inventory claims it: a booking
+	// changed since it was read refuses the stale save and keeps its dates
 	booking.CancelledBy = &userID
 	booking.CancellationReason = reason
+	booking.Refund = refund
+	booking.ReleasePending = true
 	if err := s.bookingRepo.Update(ctx, booking); err != nil {
 		return nil, err
 	}
@@ -123,5 +186,147 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID, userID uu
 		s.broker.Publish(ctx, "booking_events", "booking.cancelled", booking)
 	}
 
+	// Dates left held are released by the expiry scheduler
+	if err := s.inventoryClient.ReleaseDates(ctx, booking.ID); err != nil {
+		s.log.Warn().Err(err).Str("booking_id", booking.ID.String()).Msg("Failed to release cancelled booking's dates, will retry")
+		return booking, nil
+	}
+	booking.ReleasePending = false
+	if err := s.bookingRepo.Update(ctx, booking); err != nil && err != domain.ErrBookingChanged {
+		s.log.Warn().Err(err).Str("booking_id", booking.ID.String()).Msg("Failed to record released dates")
+	}
+
 	return booking, nil
 }
+
+// PreviewCancellation returns the refund the user would get by cancelling now,
+// without cancelling
+func (s *BookingService) PreviewCancellation(ctx context.Context, bookingID, userID uuid.UUID) (*domain.Refund, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.RenterID != userID && booking.OwnerID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	if !domain.CanTransition(booking.Status, domain.StatusCancelled) {
+		return nil, domain.ErrCannotCancel
+	}
+
+	return domain.CalculateRefund(booking, cancelledBy(booking, userID), time.Now()), nil
+}
+
+func cancelledBy(booking *domain.Booking, userID uuid.UUID) domain.CancelledBy {
+	if userID == booking.RenterID {
+		return domain.CancelledByRenter
+	}
+	return domain.CancelledByOwner
+}
+
+// CheckOut records the owner handing the item over to the renter, which starts
+// the rental. For an item with several units the booking is assigned the unit
+// handed over: unitID if the owner names one, otherwise any free unit.
+func (s *BookingService) CheckOut(ctx context.Context, bookingID, ownerID uuid.UUID, notes string, unitID *uuid.UUID) (*domain.Booking, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	if err := booking.TransitionTo(domain.StatusActive, &ownerID, "item picked up"); err != nil {
+		return nil, err
+	}
+
+	assigned, err := s.inventoryClient.AssignUnit(ctx, booking.ID, unitID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	booking.UnitID = assigned
+	booking.PickupTime = &now
+	booking.PickupNotes = notes
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.active", booking)
+	}
+
+	return booking, nil
+}
+
+// CheckIn records the owner getting the item back, which completes the rental.
+// If the owner reports damage the deposit stays held until the dispute is
+// resolved, otherwise it is released to the renter.
+func (s *BookingService) CheckIn(ctx context.Context, bookingID, ownerID uuid.UUID, notes string, claim *DamageClaimRequest) (*domain.Booking, *domain.Dispute, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if booking.OwnerID != ownerID {
+		return nil, nil, domain.ErrUnauthorized
+	}
+
+	var dispute *domain.Dispute
+	if claim != nil {
+		dispute, err = domain.NewDispute(booking, claim.Description, claim.PhotoURLs, claim.Amount)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := booking.TransitionTo(domain.StatusCompleted, &ownerID, "item returned"); err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	booking.ReturnTime = &now
+	booking.ReturnNotes = notes
+
+	if dispute != nil {
+		dispute, err = s.disputes.openDispute(ctx, booking, dispute)
+	} else {
+		err = s.disputes.releaseDeposit(ctx, booking)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return nil, nil, err
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.completed", booking)
+		if dispute != nil {
+			s.broker.Publish(ctx, "booking_events", "booking.dispute_opened", dispute)
+		}
+	}
+
+	return booking, dispute, nil
+}
+
+// GetStatusHistory lists the booking's status changes. Only the renter and
+// owner may see them.
+func (s *BookingService) GetStatusHistory(ctx context.Context, bookingID, userID uuid.UUID) ([]domain.StatusChange, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.RenterID != userID && booking.OwnerID != userID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	return booking.StatusHistory, nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/clients"
+	"github.com/rentalflow/booking-service/internal/domain"
+	"github.com/rentalflow/booking-service/internal/repository"
+	"github.com/rentalflow/rentalflow/pkg/messaging"
+)
+
+// DamageClaimRequest is the owner's damage report filed with check-in
+type DamageClaimRequest struct {
+	Description string   `json:"description"`
+	PhotoURLs   []string `json:"photo_urls"`
+	Amount      float64  `json:"amount"`
+}
+
+// DisputeService handles damage claims against security deposits and turns
+// their outcome into a deposit capture or release in the payment service
+type DisputeService struct {
+	bookingRepo   repository.BookingRepository
+	disputeRepo   repository.DisputeRepository
+	paymentClient *clients.PaymentClient
+	userClient    *clients.UserClient
+	broker        *messaging.MessageBroker
+}
+
+func NewDisputeService(bookingRepo repository.BookingRepository, disputeRepo repository.DisputeRepository, paymentClient *clients.PaymentClient, userClient *clients.UserClient, broker *messaging.MessageBroker) *DisputeService {
+	return &DisputeService{
+		bookingRepo:   bookingRepo,
+		disputeRepo:   disputeRepo,
+		paymentClient: paymentClient,
+		userClient:    userClient,
+		broker:        broker,
+	}
+}
+
+// GetDispute returns a dispute to one of its parties or an admin
+func (s *DisputeService) GetDispute(ctx context.Context, disputeID, userID uuid.UUID) (*domain.Dispute, error) {
+	dispute, err := s.disputeRepo.GetByID(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeView(ctx, dispute, userID); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// GetBookingDispute returns the dispute filed against a booking, if any
+func (s *DisputeService) GetBookingDispute(ctx context.Context, bookingID, userID uuid.UUID) (*domain.Dispute, error) {
+	dispute, err := s.disputeRepo.GetByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeView(ctx, dispute, userID); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// ListDisputes lists disputes in a status for admin review
+func (s *DisputeService) ListDisputes(ctx context.Context, adminID uuid.UUID, status domain.DisputeStatus, page, pageSize int) ([]*domain.Dispute, int, error) {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, 0, err
+	}
+	if status == "" {
+		status = domain.DisputeUnderReview
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+	return s.disputeRepo.ListByStatus(ctx, status, offset, pageSize)
+}
+
+// RespondToDispute records the renter's answer to a claim. Accepting it settles
+// the deposit straight away; contesting it leaves the decision to an admin.
+func (s *DisputeService) RespondToDispute(ctx context.Context, disputeID, renterID uuid.UUID, message string, photoURLs []string, accept bool) (*domain.Dispute, error) {
+	dispute, err := s.disputeRepo.GetByID(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dispute.Respond(renterID, message, photoURLs, accept); err != nil {
+		return nil, err
+	}
+
+	if dispute.Status == domain.DisputeResolved {
+		if err := s.settle(ctx, dispute); err != nil {
+			return nil, err
+		}
+	} else if err := s.disputeRepo.Update(ctx, dispute); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.dispute_responded", dispute)
+		if dispute.Status == domain.DisputeResolved {
+			s.broker.Publish(ctx, "booking_events", "booking.dispute_resolved", dispute)
+		}
+	}
+
+	return dispute, nil
+}
+
+// ResolveDispute records an admin's decision on how much of the deposit the
+// owner keeps, and settles the deposit accordingly
+func (s *DisputeService) ResolveDispute(ctx context.Context, disputeID, adminID uuid.UUID, capturedAmount float64, notes string) (*domain.Dispute, error) {
+	if err := s.requireAdmin(ctx, adminID); err != nil {
+		return nil, err
+	}
+
+	dispute, err := s.disputeRepo.GetByID(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dispute.Resolve(adminID, capturedAmount, notes); err != nil {
+		return nil, err
+	}
+	if err := s.settle(ctx, dispute); err != nil {
+		return nil, err
+	}
+
+	// Publish event
+	if s.broker != nil {
+		s.broker.Publish(ctx, "booking_events", "booking.dispute_resolved", dispute)
+	}
+
+	return dispute, nil
+}
+
+// openDispute stores a claim filed at check-in and keeps the deposit held
+// until it is resolved
+func (s *DisputeService) openDispute(ctx context.Context, booking *domain.Booking, dispute *domain.Dispute) (*domain.Dispute, error) {
+	stored, err := s.disputeRepo.GetOrCreate(ctx, dispute)
+	if err != nil {
+		return nil, err
+	}
+	booking.DepositStatus = domain.DepositDisputed
+	return stored, nil
+}
+
+// releaseDeposit returns the whole deposit to the renter
+func (s *DisputeService) releaseDeposit(ctx context.Context, booking *domain.Booking) error {
+	if booking.SecurityDeposit <= 0 {
+		return nil
+	}
+	settlement, err := s.paymentClient.SettleDeposit(ctx, booking.ID, 0)
+	if err != nil {
+		return err
+	}
+	if settlement.Settled {
+		booking.DepositStatus = settlement.DepositStatus
+	}
+	return nil
+}
+
+// settle captures the resolved amount of the deposit and releases the rest,
+// then stores the outcome on the dispute and the booking. A retry finds the
+// deposit already settled for the same amount and just finishes the job.
+func (s *DisputeService) settle(ctx context.Context, dispute *domain.Dispute) error {
+	resolution := dispute.Resolution
+
+	settlement, err := s.paymentClient.SettleDeposit(ctx, dispute.BookingID, resolution.CapturedAmount)
+	if err != nil {
+		return err
+	}
+	if !settlement.Settled && !settledEarlier(settlement, resolution.CapturedAmount) {
+		return domain.ErrDepositNotHeld
+	}
+	if err := s.disputeRepo.Update(ctx, dispute); err != nil {
+		return err
+	}
+
+	_, err = modifyBooking(ctx, s.bookingRepo, dispute.BookingID, func(booking *domain.Booking) error {
+		booking.DepositStatus = settlement.DepositStatus
+		return nil
+	})
+	return err
+}
+
+// settledEarlier reports whether a settlement that found no deposit held is
+// the trace of an earlier settlement capturing the same amount
+func settledEarlier(settlement *clients.DepositSettlement, captured float64) bool {
+	return settlement.DepositStatus != "" && math.Abs(settlement.CapturedAmount-captured) < 0.005
+}
+
+func (s *DisputeService) authorizeView(ctx context.Context, dispute *domain.Dispute, userID uuid.UUID) error {
+	if userID == dispute.OwnerID || userID == dispute.RenterID {
+		return nil
+	}
+	return s.requireAdmin(ctx, userID)
+}
+
+func (s *DisputeService) requireAdmin(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userClient.GetUser(ctx, userID)
+	if err != nil {
+		if err == domain.ErrUserNotFound {
+			return domain.ErrUnauthorized
+		}
+		return err
+	}
+	if !user.IsAdmin() {
+		return domain.ErrUnauthorized
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rentalflow/booking-service/internal/clients"
+	"github.com/rentalflow/booking-service/internal/domain"
+	"github.com/rentalflow/booking-service/internal/repository"
+	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rentalflow/rentalflow/pkg/messaging"
+	"github.com/rs/zerolog"
+)
+
+// expiryBatchSize caps how many bookings are expired per check
+const expiryBatchSize = 100
+
+// ExpiryScheduler expires pending bookings the owner never answered and
+// confirmed bookings the renter never paid for, releasing their dates. It also
+// finishes releasing the dates of cancelled bookings.
+type ExpiryScheduler struct {
+	bookingRepo     repository.BookingRepository
+	inventoryClient *clients.InventoryClient
+	paymentClient   *clients.PaymentClient
+	broker          *messaging.MessageBroker
+	log             zerolog.Logger
+}
+
+func NewExpiryScheduler(bookingRepo repository.BookingRepository, inventoryClient *clients.InventoryClient, paymentClient *clients.PaymentClient, broker *messaging.MessageBroker) *ExpiryScheduler {
+	return &ExpiryScheduler{
+		bookingRepo:     bookingRepo,
+		inventoryClient: inventoryClient,
+		paymentClient:   paymentClient,
+		broker:          broker,
+		log:             logger.NewLogger("expiry"),
+	}
+}
+
+// Run expires overdue bookings now and then every interval until ctx is done
+func (e *ExpiryScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		e.expireDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *ExpiryScheduler) expireDue(ctx context.Context) {
+	bookings, err := e.bookingRepo.ListDueForExpiry(ctx, time.Now(), expiryBatchSize)
+	if err != nil {
+		e.log.Error().Err(err).Msg("Failed to list bookings due for expiry")
+		return
+	}
+
+	for _, booking := range bookings {
+		if err := e.expire(ctx, booking); err != nil {
+			e.log.Error().Err(err).Str("booking_id", booking.ID.String()).Msg("Failed to expire booking")
+		}
+	}
+}
+
+func (e *ExpiryScheduler) expire(ctx context.Context, booking *domain.Booking) error {
+	if booking.Status == domain.StatusExpired || booking.Status == domain.StatusCancelled {
+		return e.release(ctx, booking)
+	}
+
+	// The payment may have gone through without the booking hearing about it
+	if booking.Status == domain.StatusConfirmed {
+		paid, err := e.paymentClient.IsBookingPaid(ctx, booking.ID)
+		if err != nil {
+			return err
+		}
+		if paid {
+			booking.PaymentStatus = domain.PaymentCompleted
+			return e.save(ctx, booking)
+		}
+	}
+
+	reason := booking.ExpiryReason(time.Now())
+	if reason == "" {
+		return nil
+	}
+	if err := booking.TransitionTo(domain.StatusExpired, nil, reason); err != nil {
+		return err
+	}
+
+	// Saving the expiry before touching inventory or payments claims it: a
+	// booking confirmed or paid since it was listed refuses the stale save
+	// and keeps its dates
+	booking.ReleasePending = true
+	if err := e.bookingRepo.Update(ctx, booking); err != nil {
+		if err == domain.ErrBookingChanged {
+			e.log.Debug().Str("booking_id", booking.ID.String()).Msg("Booking changed before it could expire")
+			return nil
+		}
+		return err
+	}
+
+	e.log.Info().Str("booking_id", booking.ID.String()).Str("reason", reason).Msg("Booking expired")
+
+	// Publish event
+	if e.broker != nil {
+		e.broker.Publish(ctx, "booking_events", "booking.expired", booking)
+	}
+
+	return e.release(ctx, booking)
+}
+
+// release frees an expired or cancelled booking's dates and voids an expired
+// booking's payments, cancelled ones being refunded by the payment service.
+// Until it succeeds the booking stays due, so later checks retry it.
+func (e *ExpiryScheduler) release(ctx context.Context, booking *domain.Booking) error {
+	if err := e.inventoryClient.ReleaseDates(ctx, booking.ID); err != nil {
+		return err
+	}
+	if booking.Status == domain.StatusExpired {
+		if err := e.paymentClient.VoidBookingPayments(ctx, booking.ID); err != nil {
+			return err
+		}
+	}
+
+	booking.ReleasePending = false
+	return e.save(ctx, booking)
+}
+
+// save writes the booking, leaving it be if it changed since it was listed
+func (e *ExpiryScheduler) save(ctx context.Context, booking *domain.Booking) error {
+	err := e.bookingRepo.Update(ctx, booking)
+	if err == domain.ErrBookingChanged {
+		return nil
+	}
+	return err
+}
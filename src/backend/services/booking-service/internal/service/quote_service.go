@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/clients"
+	"github.com/rentalflow/booking-service/internal/domain"
+)
+
+// QuoteService prices rentals from the item's current inventory rates and
+// signs the result, so the price a booking is created with cannot be chosen by
+// the client
+type QuoteService struct {
+	inventoryClient *clients.InventoryClient
+	secret          []byte
+	serviceFeeRate  float64
+	ttl             time.Duration
+}
+
+func NewQuoteService(inventoryClient *clients.InventoryClient, secret string, serviceFeeRate float64, ttl time.Duration) *QuoteService {
+	return &QuoteService{
+		inventoryClient: inventoryClient,
+		secret:          []byte(secret),
+		serviceFeeRate:  serviceFeeRate,
+		ttl:             ttl,
+	}
+}
+
+// CreateQuote prices the rental and returns the quote with its signed token
+func (s *QuoteService) CreateQuote(ctx context.Context, renterID, rentalItemID uuid.UUID, startDate, endDate time.Time,
+	addOns []domain.SelectedAddOn) (*domain.Quote, string, error) {
+	quote, err := s.Price(ctx, renterID, rentalItemID, startDate, endDate, addOns)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := s.sign(quote)
+	if err != nil {
+		return nil, "", err
+	}
+	return quote, token, nil
+}
+
+// Price prices the rental with inventory's pricing engine and its add-ons at
+// their current rates, without signing it
+func (s *QuoteService) Price(ctx context.Context, renterID, rentalItemID uuid.UUID, startDate, endDate time.Time,
+	addOns []domain.SelectedAddOn) (*domain.Quote, error) {
+	item, rates, err := s.rates(ctx, renterID, rentalItemID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, addOn := range item.AddOns {
+		rates.AddOns = append(rates.AddOns, domain.AddOnRate{
+			ID:      addOn.ID,
+			Name:    addOn.Name,
+			Pricing: addOn.Pricing,
+			Price:   addOn.Price,
+		})
+	}
+	return domain.NewQuote(renterID, item.OwnerID, item.ID, startDate, endDate, rates, addOns, s.serviceFeeRate, s.ttl)
+}
+
+// Reprice prices new dates for a booking. The rental is priced afresh, but
+// the add-ons keep the rates they were booked at, per-day ones charged for
+// the new number of days, even if the owner has since changed or dropped them.
+func (s *QuoteService) Reprice(ctx context.Context, booking *domain.Booking, startDate, endDate time.Time) (*domain.Quote, error) {
+	item, rates, err := s.rates(ctx, booking.RenterID, booking.RentalItemID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	rates.AddOns = booking.BookedAddOnRates()
+	return domain.NewQuote(booking.RenterID, item.OwnerID, item.ID, startDate, endDate, rates, booking.AddOns, s.serviceFeeRate, s.ttl)
+}
+
+// rates reads the item and prices the rental, without its add-ons
+func (s *QuoteService) rates(ctx context.Context, renterID, rentalItemID uuid.UUID, startDate, endDate time.Time) (*clients.Item, domain.ItemRates, error) {
+	item, err := s.inventoryClient.GetItem(ctx, rentalItemID)
+	if err != nil {
+		return nil, domain.ItemRates{}, err
+	}
+	if !item.IsActive {
+		return nil, domain.ItemRates{}, domain.ErrItemUnavailable
+	}
+	if item.OwnerID == renterID {
+		return nil, domain.ItemRates{}, domain.ErrUnauthorized
+	}
+
+	rental, err := s.inventoryClient.PriceRental(ctx, rentalItemID, startDate, endDate)
+	if err != nil {
+		return nil, domain.ItemRates{}, err
+	}
+
+	return item, domain.ItemRates{
+		DailyRate:       item.DailyRate,
+		SecurityDeposit: item.SecurityDeposit,
+		Rental:          *rental,
+	}, nil
+}
+
+// RedeemQuote checks a quote token's signature and expiry and that it was
+// issued to the renter presenting it
+func (s *QuoteService) RedeemQuote(token string, renterID uuid.UUID) (*domain.Quote, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, domain.ErrInvalidQuote
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, s.mac(payload)) {
+		return nil, domain.ErrInvalidQuote
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, domain.ErrInvalidQuote
+	}
+	var quote domain.Quote
+	if err := json.Unmarshal(data, &quote); err != nil {
+		return nil, domain.ErrInvalidQuote
+	}
+
+	if quote.RenterID != renterID {
+		return nil, domain.ErrInvalidQuote
+	}
+	if time.Now().After(quote.ExpiresAt) {
+		return nil, domain.ErrQuoteExpired
+	}
+	return &quote, nil
+}
+
+// sign encodes the quote as base64url JSON followed by its HMAC-SHA256
+func (s *QuoteService) sign(quote *domain.Quote) (string, error) {
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload)), nil
+}
+
+func (s *QuoteService) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/booking-service/internal/domain"
+)
+
+func TestRedeemQuote(t *testing.T) {
+	s := NewQuoteService(nil, "quote-secret", 0.1, time.Minute)
+	renter := uuid.New()
+
+	sign := func(quote *domain.Quote) string {
+		t.Helper()
+		token, err := s.sign(quote)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+	quote := &domain.Quote{ID: uuid.New(), RenterID: renter, TotalAmount: 380, ExpiresAt: time.Now().Add(time.Minute)}
+	token := sign(quote)
+
+	got, err := s.RedeemQuote(token, renter)
+	if err != nil {
+		t.Fatalf("RedeemQuote: %v", err)
+	}
+	if got.ID != quote.ID || got.TotalAmount != quote.TotalAmount {
+		t.Errorf("redeemed %+v, want %+v", got, quote)
+	}
+
+	// A cheaper quote's payload under the original quote's signature
+	cheaper := *quote
+	cheaper.TotalAmount = 1
+	payload, _, _ := strings.Cut(sign(&cheaper), ".")
+	_, signature, _ := strings.Cut(token, ".")
+
+	expired := *quote
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+
+	tests := []struct {
+		name    string
+		service *QuoteService
+		token   string
+		renter  uuid.UUID
+		want    error
+	}{
+		{"another renter", s, token, uuid.New(), domain.ErrInvalidQuote},
+		{"altered price", s, payload + "." + signature, renter, domain.ErrInvalidQuote},
+		{"signed with another secret", NewQuoteService(nil, "other-secret", 0.1, time.Minute), token, renter, domain.ErrInvalidQuote},
+		{"not a token", s, "quote", renter, domain.ErrInvalidQuote},
+		{"expired", s, sign(&expired), renter, domain.ErrQuoteExpired},
+	}
+	for _, tt := range tests {
+		if _, err := tt.service.RedeemQuote(tt.token, tt.renter); err != tt.want {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -9,12 +9,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/rentalflow/inventory-service/internal/clients"
 	"github.com/rentalflow/inventory-service/internal/config"
 	"github.com/rentalflow/inventory-service/internal/handler"
 	"github.com/rentalflow/inventory-service/internal/repository"
 	"github.com/rentalflow/inventory-service/internal/service"
 	"github.com/rentalflow/rentalflow/pkg/database"
 	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rentalflow/rentalflow/pkg/media"
 )
 
 func main() {
@@ -47,17 +49,76 @@ func main() {
 	itemRepo := repository.NewMongoItemRepository(client.DB)
 	availabilityRepo := repository.NewMongoAvailabilityRepository(client.DB)
 	maintenanceRepo := repository.NewMongoMaintenanceRepository(client.DB)
+	feedRepo := repository.NewMongoCalendarFeedRepository(client.DB)
+	schemaRepo := repository.NewMongoSpecSchemaRepository(client.DB)
+	categoryRepo := repository.NewMongoCategoryRepository(client.DB)
+	importJobRepo := repository.NewMongoImportJobRepository(client.DB)
+	planRepo := repository.NewMongoMaintenancePlanRepository(client.DB)
+
+	if err := itemRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create item indexes")
+	}
+	if err := availabilityRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create availability indexes")
+	}
+	if err := schemaRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create schema indexes")
+	}
+	if err := categoryRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create category indexes")
+	}
+	if err := importJobRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create import job indexes")
+	}
+	if err := planRepo.EnsureIndexes(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to create maintenance plan indexes")
+	}
+
+	// Initialize clients
+	userClient := clients.NewUserClient(cfg.AuthServiceURL)
+
+	// Initialize media storage for item images
+	storage, err := media.NewStorage(cfg.Config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize media storage")
+	}
+	uploader := media.NewUploader(storage)
+
+	// Initialize services
+	inventoryService := service.NewInventoryService(itemRepo, availabilityRepo, maintenanceRepo, schemaRepo, categoryRepo, uploader)
+	calendarService := service.NewCalendarService(itemRepo, availabilityRepo, feedRepo)
+	schemaService := service.NewSchemaService(schemaRepo, categoryRepo, userClient)
+	categoryService := service.NewCategoryService(categoryRepo, itemRepo, userClient)
+	bulkService := service.NewBulkService(inventoryService, importJobRepo, itemRepo, availabilityRepo, maintenanceRepo)
+	planService := service.NewMaintenancePlanService(inventoryService, planRepo, maintenanceRepo, availabilityRepo)
+
+	if err := categoryService.EnsureDefaults(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to seed default categories")
+	}
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	// Keep imported calendars in step with the platforms they come from
+	calendarSync := service.NewCalendarSyncScheduler(calendarService)
+	go calendarSync.Run(backgroundCtx, cfg.CalendarSyncInterval)
+
+	// Work through bulk item imports in the background
+	importWorker := service.NewImportWorker(bulkService)
+	go importWorker.Run(backgroundCtx, cfg.ImportPollInterval)
 
-	// Initialize service
-	inventoryService := service.NewInventoryService(itemRepo, availabilityRepo, maintenanceRepo)
+	// Schedule preventive maintenance as it falls due
+	maintenanceScheduler := service.NewMaintenanceScheduler(planService)
+	go maintenanceScheduler.Run(backgroundCtx, cfg.MaintenancePlanInterval)
 
 	// Initialize HTTP handler
-	httpHandler := handler.NewHTTPHandler(inventoryService)
+	httpHandler := handler.NewHTTPHandler(inventoryService, calendarService, schemaService, categoryService, bulkService, planService)
 
 	// Start HTTP server
 	httpAddr := fmt.Sprintf(":%d", cfg.HTTPPort)
 	mux := http.NewServeMux()
 	httpHandler.RegisterRoutes(mux)
+	media.Mount(mux, "/media/", storage)
 
 	httpServer := &http.Server{
 		Addr:    httpAddr,
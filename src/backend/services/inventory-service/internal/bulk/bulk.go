@@ -0,0 +1,405 @@
+// Package bulk reads item imports and writes catalogue exports as CSV or
+// newline-delimited JSON. Exported items use the same columns and fields the
+// importer reads, so a catalogue can be moved between accounts unchanged.
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+)
+
+// specPrefix marks CSV columns holding a specification, as in "spec.seats"
+const specPrefix = "spec."
+
+// imageSeparator joins an item's image URLs in a CSV cell
+const imageSeparator = "|"
+
+// itemColumns are the CSV columns an import may have, in export order
+var itemColumns = []string{
+	"title", "description", "category", "subcategory",
+	"daily_rate", "weekly_rate", "monthly_rate", "security_deposit",
+	"address", "city", "latitude", "longitude", "images",
+}
+
+// requiredColumns must be present in a CSV import
+var requiredColumns = []string{"title", "category", "daily_rate"}
+
+// exportOnlyColumns are written by exports and ignored by imports
+var exportOnlyColumns = map[string]bool{"id": true, "is_active": true, "created_at": true}
+
+// ParseItems reads the rows of an import. Problems with the file as a whole
+// are returned as a *domain.ImportFileError; problems with single rows are
+// recorded on the row.
+func ParseItems(format domain.ImportFormat, r io.Reader) ([]domain.ImportRow, error) {
+	var rows []domain.ImportRow
+	var err error
+	switch format {
+	case domain.FormatCSV:
+		rows, err = parseCSV(r)
+	case domain.FormatNDJSON:
+		rows, err = parseNDJSON(r)
+	default:
+		return nil, fileError("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fileError("the file has no items")
+	}
+	if len(rows) > domain.MaxImportRows {
+		return nil, fileError("the file has %d items, more than the limit of %d", len(rows), domain.MaxImportRows)
+	}
+	return rows, nil
+}
+
+func parseCSV(r io.Reader) ([]domain.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fileError("the file is empty")
+	}
+	if err != nil {
+		return nil, fileError("%v", err)
+	}
+
+	known := map[string]bool{}
+	for _, column := range itemColumns {
+		known[column] = true
+	}
+	var problems []string
+	seen := map[string]bool{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		header[i] = column
+		switch {
+		case seen[column]:
+			problems = append(problems, "column "+strconv.Quote(column)+" appears more than once")
+		case strings.HasPrefix(column, specPrefix):
+			if domain.NormalizeAttributeKey(strings.TrimPrefix(column, specPrefix)) == "" {
+				problems = append(problems, "column "+strconv.Quote(column)+" names no specification")
+			}
+		case !known[column] && !exportOnlyColumns[column]:
+			problems = append(problems, "unknown column "+strconv.Quote(column))
+		}
+		seen[column] = true
+	}
+	for _, column := range requiredColumns {
+		if !seen[column] {
+			problems = append(problems, "missing column "+strconv.Quote(column))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &domain.ImportFileError{Problems: problems}
+	}
+
+	var rows []domain.ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fileError("%v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if blank(record) {
+			continue
+		}
+		if len(rows) == domain.MaxImportRows {
+			return nil, fileError("the file has more than %d items", domain.MaxImportRows)
+		}
+		rows = append(rows, csvRow(line, header, record))
+	}
+	return rows, nil
+}
+
+func csvRow(line int, header, record []string) domain.ImportRow {
+	row := domain.ImportRow{Line: line, Item: domain.ImportItem{Specifications: map[string]string{}}}
+	if len(record) != len(header) {
+		row.Problems = append(row.Problems, fmt.Sprintf("expected %d fields, got %d", len(header), len(record)))
+		return row
+	}
+
+	item := &row.Item
+	number := func(column, value string) float64 {
+		if value == "" {
+			return 0
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			row.Problems = append(row.Problems, column+": "+strconv.Quote(value)+" is not a number")
+		}
+		return v
+	}
+
+	for i, column := range header {
+		value := strings.TrimSpace(record[i])
+		switch column {
+		case "title":
+			item.Title = value
+		case "description":
+			item.Description = value
+		case "category":
+			item.Category = value
+		case "subcategory":
+			item.Subcategory = value
+		case "daily_rate":
+			item.DailyRate = number(column, value)
+		case "weekly_rate":
+			item.WeeklyRate = number(column, value)
+		case "monthly_rate":
+			item.MonthlyRate = number(column, value)
+		case "security_deposit":
+			item.SecurityDeposit = number(column, value)
+		case "address":
+			item.Address = value
+		case "city":
+			item.City = value
+		case "latitude":
+			item.Latitude = number(column, value)
+		case "longitude":
+			item.Longitude = number(column, value)
+		case "images":
+			for _, url := range strings.Split(value, imageSeparator) {
+				if url = strings.TrimSpace(url); url != "" {
+					item.Images = append(item.Images, url)
+				}
+			}
+		default:
+			if strings.HasPrefix(column, specPrefix) && value != "" {
+				item.Specifications[strings.TrimPrefix(column, specPrefix)] = value
+			}
+		}
+	}
+	return row
+}
+
+func parseNDJSON(r io.Reader) ([]domain.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	var rows []domain.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if len(rows) == domain.MaxImportRows {
+			return nil, fileError("the file has more than %d items", domain.MaxImportRows)
+		}
+		row := domain.ImportRow{Line: line}
+		if err := json.Unmarshal(text, &row.Item); err != nil {
+			row.Problems = []string{"invalid JSON: " + err.Error()}
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fileError("%v", err)
+	}
+	return rows, nil
+}
+
+// exportItem is an item as exported, with the fields an import reads and
+// the ones it ignores
+type exportItem struct {
+	ID uuid.UUID `json:"id"`
+	domain.ImportItem
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WriteItems exports items. CSV exports get a "spec." column for every
+// specification any of the items has.
+func WriteItems(w io.Writer, format domain.ImportFormat, items []*domain.RentalItem) error {
+	records := make([]exportItem, len(items))
+	specKeys := map[string]bool{}
+	for i, item := range items {
+		records[i] = exportItem{
+			ID: item.ID,
+			ImportItem: domain.ImportItem{
+				Title:           item.Title,
+				Description:     item.Description,
+				Category:        string(item.Category),
+				Subcategory:     item.Subcategory,
+				DailyRate:       item.DailyRate,
+				WeeklyRate:      item.WeeklyRate,
+				MonthlyRate:     item.MonthlyRate,
+				SecurityDeposit: item.SecurityDeposit,
+				Address:         item.Address,
+				City:            item.City,
+				Latitude:        item.Latitude,
+				Longitude:       item.Longitude,
+				Specifications:  item.Specifications,
+				Images:          item.Images,
+			},
+			IsActive:  item.IsActive,
+			CreatedAt: item.CreatedAt,
+		}
+		for key := range item.Specifications {
+			specKeys[key] = true
+		}
+	}
+
+	if format == domain.FormatNDJSON {
+		return writeNDJSON(w, len(records), func(i int) interface{} { return records[i] })
+	}
+
+	keys := make([]string, 0, len(specKeys))
+	for key := range specKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	header := append([]string{"id"}, itemColumns...)
+	header = append(header, "is_active", "created_at")
+	for _, key := range keys {
+		header = append(header, specPrefix+key)
+	}
+
+	return writeCSV(w, header, len(records), func(i int) []string {
+		r := records[i]
+		record := []string{
+			r.ID.String(), r.Title, r.Description, r.Category, r.Subcategory,
+			formatFloat(r.DailyRate), formatFloat(r.WeeklyRate), formatFloat(r.MonthlyRate), formatFloat(r.SecurityDeposit),
+			r.Address, r.City, formatFloat(r.Latitude), formatFloat(r.Longitude),
+			strings.Join(r.Images, imageSeparator),
+			strconv.FormatBool(r.IsActive), r.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		for _, key := range keys {
+			record = append(record, r.Specifications[key])
+		}
+		return record
+	})
+}
+
+// maintenanceRecord is a maintenance log with the title of its item
+type maintenanceRecord struct {
+	*domain.MaintenanceLog
+	ItemTitle string `json:"item_title"`
+}
+
+// WriteMaintenance exports maintenance logs; titles names their items
+func WriteMaintenance(w io.Writer, format domain.ImportFormat, logs []*domain.MaintenanceLog, titles map[uuid.UUID]string) error {
+	if format == domain.FormatNDJSON {
+		return writeNDJSON(w, len(logs), func(i int) interface{} {
+			return maintenanceRecord{MaintenanceLog: logs[i], ItemTitle: titles[logs[i].RentalItemID]}
+		})
+	}
+
+	header := []string{"id", "item_id", "item_title", "unit_id", "maintenance_type", "description", "start_date", "end_date", "cost", "status", "started_at", "completed_at", "created_at"}
+	return writeCSV(w, header, len(logs), func(i int) []string {
+		log := logs[i]
+		return []string{
+			log.ID.String(), log.RentalItemID.String(), titles[log.RentalItemID], formatID(log.UnitID),
+			log.MaintenanceType, log.Description,
+			formatTime(&log.StartDate), formatTime(log.EndDate),
+			formatFloat(log.Cost), string(log.Status), formatTime(log.StartedAt), formatTime(log.CompletedAt),
+			formatTime(&log.CreatedAt),
+		}
+	})
+}
+
+// availabilityRecord is an availability slot with the title of its item
+type availabilityRecord struct {
+	*domain.AvailabilitySlot
+	ItemTitle string `json:"item_title"`
+}
+
+// WriteAvailability exports availability slots; titles names their items
+func WriteAvailability(w io.Writer, format domain.ImportFormat, slots []*domain.AvailabilitySlot, titles map[uuid.UUID]string) error {
+	if format == domain.FormatNDJSON {
+		return writeNDJSON(w, len(slots), func(i int) interface{} {
+			return availabilityRecord{AvailabilitySlot: slots[i], ItemTitle: titles[slots[i].RentalItemID]}
+		})
+	}
+
+	header := []string{"id", "item_id", "item_title", "start_date", "end_date", "status", "booking_id", "unit_id", "external_uid"}
+	return writeCSV(w, header, len(slots), func(i int) []string {
+		slot := slots[i]
+		return []string{
+			slot.ID.String(), slot.RentalItemID.String(), titles[slot.RentalItemID],
+			formatTime(&slot.StartDate), formatTime(&slot.EndDate),
+			string(slot.Status), formatID(slot.BookingID), formatID(slot.UnitID), slot.ExternalUID,
+		}
+	})
+}
+
+// WriteErrorReport lists the rows of an import that failed, one per line
+func WriteErrorReport(w io.Writer, results []domain.ImportRowResult) error {
+	return writeCSV(w, []string{"line", "errors"}, len(results), func(i int) []string {
+		return []string{strconv.Itoa(results[i].Line), strings.Join(results[i].Errors, "; ")}
+	})
+}
+
+func writeCSV(w io.Writer, header []string, n int, record func(i int) []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := writer.Write(record(i)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeNDJSON(w io.Writer, n int, record func(i int) interface{}) error {
+	encoder := json.NewEncoder(w)
+	for i := 0; i < n; i++ {
+		if err := encoder.Encode(record(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatFloat(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func blank(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func fileError(format string, args ...interface{}) error {
+	return &domain.ImportFileError{Problems: []string{fmt.Sprintf(format, args...)}}
+}
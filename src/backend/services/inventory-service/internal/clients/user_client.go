@@ -0,0 +1,53 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+)
+
+// UserClient calls the auth service HTTP API for user profiles
+type UserClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// User is the part of a user profile that inventory needs
+type User struct {
+	ID   uuid.UUID `json:"id"`
+	Role string    `json:"role"`
+}
+
+// IsAdmin reports whether the user is a platform admin
+func (u *User) IsAdmin() bool {
+	return u.Role == "admin"
+}
+
+// NewUserClient creates a new auth service client
+func NewUserClient(baseURL string) *UserClient {
+	return &UserClient{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// GetUser fetches a user's profile
+func (c *UserClient) GetUser(ctx context.Context, userID uuid.UUID) (*User, error) {
+	var user User
+	url := c.baseURL + "/api/auth/profile?user_id=" + userID.String()
+	err := doJSON(ctx, c.client, "auth service", http.MethodGet, url, nil, &user)
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
@@ -1,12 +1,25 @@
 package config
 
 import (
+	"os"
+	"time"
+
 	"github.com/rentalflow/rentalflow/pkg/config"
 )
 
 // Config extends the base config with inventory-specific settings
 type Config struct {
 	*config.Config
+	AuthServiceURL string
+
+	// Imported calendar feeds are re-fetched every CalendarSyncInterval
+	CalendarSyncInterval time.Duration
+
+	// Queued bulk imports are checked for every ImportPollInterval
+	ImportPollInterval time.Duration
+
+	// Preventive maintenance plans are checked every MaintenancePlanInterval
+	MaintenancePlanInterval time.Duration
 }
 
 // Load loads the inventory service configuration
@@ -22,6 +35,17 @@ func Load() (*Config, error) {
 	}
 
 	return &Config{
-		Config: baseConfig,
+		Config:                  baseConfig,
+		AuthServiceURL:          "http://" + baseConfig.Services.AuthServiceAddr,
+		CalendarSyncInterval:    getEnvDuration("INVENTORY_CALENDAR_SYNC_INTERVAL", 15*time.Minute),
+		ImportPollInterval:      getEnvDuration("INVENTORY_IMPORT_POLL_INTERVAL", 5*time.Second),
+		MaintenancePlanInterval: getEnvDuration("INVENTORY_MAINTENANCE_PLAN_INTERVAL", time.Hour),
 	}, nil
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
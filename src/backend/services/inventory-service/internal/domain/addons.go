@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AddOnPricing is how an add-on is charged
+type AddOnPricing string
+
+const (
+	AddOnPerDay AddOnPricing = "per_day"
+	AddOnFlat   AddOnPricing = "flat"
+)
+
+// AddOnKind tells extras, such as a helmet or delivery, from bundles of
+// equipment rented along with the item, such as a set of lenses
+type AddOnKind string
+
+const (
+	AddOnExtra  AddOnKind = "extra"
+	AddOnBundle AddOnKind = "bundle"
+)
+
+// Add-on limits
+const (
+	MaxItemAddOns    = 20
+	MaxAddOnQuantity = 10
+)
+
+// AddOnSettings are the parts of an add-on the owner sets. Stock is how many
+// can be out at once across bookings; zero leaves it unlimited, as for
+// delivery or insurance. Includes lists what a bundle is made up of.
+type AddOnSettings struct {
+	Name        string       `json:"name" bson:"name"`
+	Description string       `json:"description,omitempty" bson:"description,omitempty"`
+	Kind        AddOnKind    `json:"kind" bson:"kind"`
+	Includes    []string     `json:"includes,omitempty" bson:"includes,omitempty"`
+	Pricing     AddOnPricing `json:"pricing" bson:"pricing"`
+	Price       float64      `json:"price" bson:"price"`
+	Stock       int          `json:"stock" bson:"stock"`
+	IsActive    bool         `json:"is_active" bson:"is_active"`
+}
+
+// Validate checks the settings, making an add-on without a kind an extra
+func (s *AddOnSettings) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Kind == "" {
+		s.Kind = AddOnExtra
+	}
+
+	includes := make([]string, 0, len(s.Includes))
+	for _, part := range s.Includes {
+		if part = strings.TrimSpace(part); part != "" {
+			includes = append(includes, part)
+		}
+	}
+	s.Includes = includes
+
+	switch {
+	case s.Name == "":
+		return ErrInvalidAddOn
+	case s.Kind != AddOnExtra && s.Kind != AddOnBundle:
+		return ErrInvalidAddOn
+	case s.Kind == AddOnExtra && len(s.Includes) > 0:
+		return ErrInvalidAddOn
+	case s.Pricing != AddOnPerDay && s.Pricing != AddOnFlat:
+		return ErrInvalidAddOn
+	case s.Price < 0:
+		return ErrInvalidPrice
+	case s.Stock < 0:
+		return ErrInvalidAddOn
+	}
+	return nil
+}
+
+// AddOn is something the owner offers alongside the item for a charge
+type AddOn struct {
+	ID            uuid.UUID `json:"id" bson:"id"`
+	AddOnSettings `bson:",inline"`
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`
+}
+
+// BookedAddOn is an add-on reserved with a booking's dates
+type BookedAddOn struct {
+	AddOnID  uuid.UUID `json:"add_on_id" bson:"add_on_id"`
+	Quantity int       `json:"quantity" bson:"quantity"`
+}
+
+// AddOn finds one of the item's add-ons
+func (i *RentalItem) AddOn(addOnID uuid.UUID) (*AddOn, error) {
+	for n := range i.AddOns {
+		if i.AddOns[n].ID == addOnID {
+			return &i.AddOns[n], nil
+		}
+	}
+	return nil, ErrAddOnNotFound
+}
+
+// ActiveAddOns lists the add-ons renters can choose
+func (i *RentalItem) ActiveAddOns() []AddOn {
+	addOns := []AddOn{}
+	for _, addOn := range i.AddOns {
+		if addOn.IsActive {
+			addOns = append(addOns, addOn)
+		}
+	}
+	return addOns
+}
+
+// AddAddOn offers a new add-on with the item
+func (i *RentalItem) AddAddOn(settings AddOnSettings) (*AddOn, error) {
+	if len(i.AddOns) >= MaxItemAddOns {
+		return nil, ErrTooManyAddOns
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	i.AddOns = append(i.AddOns, AddOn{
+		ID:            uuid.New(),
+		AddOnSettings: settings,
+		CreatedAt:     time.Now(),
+	})
+	i.UpdatedAt = time.Now()
+	return &i.AddOns[len(i.AddOns)-1], nil
+}
+
+// UpdateAddOn replaces an add-on's settings. Bookings already made keep the
+// add-on at the price they were quoted.
+func (i *RentalItem) UpdateAddOn(addOnID uuid.UUID, settings AddOnSettings) (*AddOn, error) {
+	addOn, err := i.AddOn(addOnID)
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	addOn.AddOnSettings = settings
+	i.UpdatedAt = time.Now()
+	return addOn, nil
+}
+
+// RemoveAddOn stops offering an add-on. Bookings that already have it keep it.
+func (i *RentalItem) RemoveAddOn(addOnID uuid.UUID) error {
+	for n, addOn := range i.AddOns {
+		if addOn.ID == addOnID {
+			i.AddOns = append(i.AddOns[:n:n], i.AddOns[n+1:]...)
+			i.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return ErrAddOnNotFound
+}
+
+// CheckAddOns checks a booking's choice of add-ons: each must be on offer,
+// chosen once, in a quantity the item could ever supply
+func (i *RentalItem) CheckAddOns(booked []BookedAddOn) error {
+	seen := make(map[uuid.UUID]bool, len(booked))
+	for _, choice := range booked {
+		addOn, err := i.AddOn(choice.AddOnID)
+		if err != nil {
+			return err
+		}
+		if !addOn.IsActive {
+			return ErrAddOnNotFound
+		}
+		if seen[choice.AddOnID] || choice.Quantity < 1 || choice.Quantity > MaxAddOnQuantity {
+			return ErrInvalidAddOn
+		}
+		if addOn.Stock > 0 && choice.Quantity > addOn.Stock {
+			return ErrAddOnUnavailable
+		}
+		seen[choice.AddOnID] = true
+	}
+	return nil
+}
+
+// ReservationLimits are how much of an item can be reserved at once: its
+// units, and the stock of each add-on that keeps one
+type ReservationLimits struct {
+	Capacity int
+	Stock    map[uuid.UUID]int
+}
+
+// ReservationLimits returns the item's current limits
+func (i *RentalItem) ReservationLimits() ReservationLimits {
+	limits := ReservationLimits{Capacity: i.UnitCapacity(), Stock: map[uuid.UUID]int{}}
+	for _, addOn := range i.AddOns {
+		if addOn.Stock > 0 {
+			limits.Stock[addOn.ID] = addOn.Stock
+		}
+	}
+	return limits
+}
+
+// Stocked picks out the add-ons among booked that have limited stock
+func (l ReservationLimits) Stocked(booked []BookedAddOn) []BookedAddOn {
+	var stocked []BookedAddOn
+	for _, choice := range booked {
+		if l.Stock[choice.AddOnID] > 0 {
+			stocked = append(stocked, choice)
+		}
+	}
+	return stocked
+}
+
+// AddOnsFit reports whether the add-ons can be reserved over [start, end)
+// alongside those the slots already hold without running out of stock
+func (l ReservationLimits) AddOnsFit(slots []*AvailabilitySlot, start, end time.Time, booked []BookedAddOn) bool {
+	for _, choice := range l.Stocked(booked) {
+		held := peakUsage(slots, start, end, func(slot *AvailabilitySlot) int {
+			for _, other := range slot.AddOns {
+				if other.AddOnID == choice.AddOnID {
+					return other.Quantity
+				}
+			}
+			return 0
+		})
+		if held+choice.Quantity > l.Stock[choice.AddOnID] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func holding(from, to int, addOns ...BookedAddOn) *AvailabilitySlot {
+	slot := slotOn(from, to, StatusBooked)
+	slot.AddOns = addOns
+	return slot
+}
+
+func TestAddOnsFit(t *testing.T) {
+	helmet, gps, delivery := uuid.New(), uuid.New(), uuid.New()
+	limits := ReservationLimits{Capacity: 5, Stock: map[uuid.UUID]int{helmet: 3, gps: 1}}
+
+	tests := []struct {
+		name   string
+		slots  []*AvailabilitySlot
+		booked []BookedAddOn
+		want   bool
+	}{
+		{"nothing held", nil, []BookedAddOn{{helmet, 3}}, true},
+		{"more than the stock", nil, []BookedAddOn{{helmet, 4}}, false},
+		{"unlimited add-on", []*AvailabilitySlot{holding(0, 4, BookedAddOn{delivery, 9})}, []BookedAddOn{{delivery, 10}}, true},
+		{"room left", []*AvailabilitySlot{holding(1, 3, BookedAddOn{helmet, 2})}, []BookedAddOn{{helmet, 1}}, true},
+		{"held by overlapping slots", []*AvailabilitySlot{holding(0, 3, BookedAddOn{helmet, 2}), holding(2, 4, BookedAddOn{helmet, 1})},
+			[]BookedAddOn{{helmet, 1}}, false},
+		{"held in turn", []*AvailabilitySlot{holding(0, 2, BookedAddOn{helmet, 2}), holding(2, 4, BookedAddOn{helmet, 2})},
+			[]BookedAddOn{{helmet, 1}}, true},
+		{"other add-ons don't count", []*AvailabilitySlot{holding(0, 4, BookedAddOn{gps, 1})}, []BookedAddOn{{helmet, 3}}, true},
+		{"one of several runs out", []*AvailabilitySlot{holding(0, 4, BookedAddOn{gps, 1})}, []BookedAddOn{{helmet, 1}, {gps, 1}}, false},
+		{"outside the range", []*AvailabilitySlot{holding(4, 6, BookedAddOn{gps, 1})}, []BookedAddOn{{gps, 1}}, true},
+	}
+	for _, tt := range tests {
+		if got := limits.AddOnsFit(tt.slots, day(0), day(4), tt.booked); got != tt.want {
+			t.Errorf("%s: AddOnsFit = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckAddOns(t *testing.T) {
+	item := &RentalItem{}
+	helmet, _ := item.AddAddOn(AddOnSettings{Name: "Helmet", Pricing: AddOnPerDay, Price: 5, Stock: 2, IsActive: true})
+	retired, _ := item.AddAddOn(AddOnSettings{Name: "Old GPS", Pricing: AddOnFlat, Price: 10})
+
+	tests := []struct {
+		name   string
+		booked []BookedAddOn
+		want   error
+	}{
+		{"on offer", []BookedAddOn{{helmet.ID, 2}}, nil},
+		{"unknown", []BookedAddOn{{uuid.New(), 1}}, ErrAddOnNotFound},
+		{"no longer offered", []BookedAddOn{{retired.ID, 1}}, ErrAddOnNotFound},
+		{"chosen twice", []BookedAddOn{{helmet.ID, 1}, {helmet.ID, 1}}, ErrInvalidAddOn},
+		{"no quantity", []BookedAddOn{{helmet.ID, 0}}, ErrInvalidAddOn},
+		{"more than in stock", []BookedAddOn{{helmet.ID, 3}}, ErrAddOnUnavailable},
+	}
+	for _, tt := range tests {
+		if err := item.CheckAddOns(tt.booked); err != tt.want {
+			t.Errorf("%s: CheckAddOns = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCalendarDays caps how many days a single availability calendar covers
+const MaxCalendarDays = 366
+
+// DayAvailability is the state of one calendar day. Blacked-out days show as
+// blocked. A free day is Bookable when it falls within the lead time and
+// advance window and belongs to a run of such days at least as long as the
+// minimum stay.
+type DayAvailability struct {
+	Date     time.Time          `json:"date"`
+	Status   AvailabilityStatus `json:"status"`
+	Bookable bool               `json:"bookable"`
+}
+
+// AvailabilityRange is a run of consecutive days sharing the same state. End
+// is exclusive.
+type AvailabilityRange struct {
+	Start    time.Time          `json:"start"`
+	End      time.Time          `json:"end"`
+	Status   AvailabilityStatus `json:"status"`
+	Bookable bool               `json:"bookable"`
+}
+
+// AvailabilityCalendar is an item's free/busy calendar over [From, To). An
+// item with several units is busy on a day only once all Capacity units are
+// reserved.
+type AvailabilityCalendar struct {
+	ItemID   uuid.UUID           `json:"item_id"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Capacity int                 `json:"capacity"`
+	Rules    RentalRules         `json:"rules"`
+	Days     []DayAvailability   `json:"days"`
+	Ranges   []AvailabilityRange `json:"ranges"`
+}
+
+// busyPrecedence decides which status a day shows when several slots cover it
+var busyPrecedence = map[AvailabilityStatus]int{
+	StatusBlocked:     1,
+	StatusMaintenance: 2,
+	StatusBooked:      3,
+}
+
+// StartOfDay truncates t to midnight UTC
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// BuildAvailabilityCalendar lays the slots and rules over the days in
+// [from, to) as seen at now. Slots should cover the wider window from
+// CalendarWindow, so that free runs cut off by the edges of the range are
+// measured at their full length.
+func BuildAvailabilityCalendar(itemID uuid.UUID, from, to time.Time, capacity int, rules RentalRules, slots []*AvailabilitySlot, now time.Time) *AvailabilityCalendar {
+	windowFrom, windowTo := CalendarWindow(from, to, rules)
+
+	var days []DayAvailability
+	for day := windowFrom; day.Before(windowTo); day = day.AddDate(0, 0, 1) {
+		status := dayStatus(day, slots, capacity)
+		if status == StatusAvailable && rules.IsBlackedOut(day) {
+			status = StatusBlocked
+		}
+		days = append(days, DayAvailability{Date: day, Status: status})
+	}
+	markBookable(days, rules, now)
+
+	cal := &AvailabilityCalendar{
+		ItemID:   itemID,
+		From:     from,
+		To:       to,
+		Capacity: capacity,
+		Rules:    rules,
+		Days:     []DayAvailability{},
+		Ranges:   []AvailabilityRange{},
+	}
+	for _, day := range days {
+		if day.Date.Before(from) || !day.Date.Before(to) {
+			continue
+		}
+		cal.Days = append(cal.Days, day)
+
+		next := day.Date.AddDate(0, 0, 1)
+		if n := len(cal.Ranges); n > 0 && cal.Ranges[n-1].Status == day.Status && cal.Ranges[n-1].Bookable == day.Bookable {
+			cal.Ranges[n-1].End = next
+			continue
+		}
+		cal.Ranges = append(cal.Ranges, AvailabilityRange{Start: day.Date, End: next, Status: day.Status, Bookable: day.Bookable})
+	}
+	return cal
+}
+
+// CalendarWindow widens [from, to) by the minimum stay on each side
+func CalendarWindow(from, to time.Time, rules RentalRules) (time.Time, time.Time) {
+	return from.AddDate(0, 0, -rules.MinDays), to.AddDate(0, 0, rules.MinDays)
+}
+
+// dayStatus is the busiest status of any slot overlapping the day, or
+// available while a unit is still free for the whole day
+func dayStatus(day time.Time, slots []*AvailabilitySlot, capacity int) AvailabilityStatus {
+	status := StatusAvailable
+	end := day.AddDate(0, 0, 1)
+	if capacity > 1 && PeakReservations(slots, day, end) < capacity {
+		return status
+	}
+	for _, slot := range slots {
+		if !slot.StartDate.Before(end) || !slot.EndDate.After(day) {
+			continue
+		}
+		if busyPrecedence[slot.Status] > busyPrecedence[status] {
+			status = slot.Status
+		}
+	}
+	return status
+}
+
+// markBookable flags free days inside the booking window that are in runs of
+// at least the minimum stay. Days past the advance window are left unbookable
+// even though a rental starting just inside it could run into them.
+func markBookable(days []DayAvailability, rules RentalRules, now time.Time) {
+	minDays := rules.MinDays
+	if minDays < 1 {
+		minDays = 1
+	}
+	earliest, latest := rules.EarliestStart(now), rules.LatestStart(now)
+	open := func(day DayAvailability) bool {
+		if day.Status != StatusAvailable || day.Date.Before(earliest) {
+			return false
+		}
+		return latest.IsZero() || !day.Date.After(latest)
+	}
+
+	for start := 0; start < len(days); {
+		if !open(days[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(days) && open(days[end]) {
+			end++
+		}
+		if end-start >= minDays {
+			for i := start; i < end; i++ {
+				days[i].Bookable = true
+			}
+		}
+		start = end
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed is an external iCalendar URL whose events block an item's dates,
+// such as the item's listing on another platform or the owner's own calendar
+type CalendarFeed struct {
+	ID           uuid.UUID  `json:"id" bson:"_id"`
+	RentalItemID uuid.UUID  `json:"rental_item_id" bson:"rental_item_id"`
+	OwnerID      uuid.UUID  `json:"owner_id" bson:"owner_id"`
+	Name         string     `json:"name" bson:"name"`
+	URL          string     `json:"url" bson:"url"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty" bson:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	EventCount   int        `json:"event_count" bson:"event_count"`
+
+	// SkippedEvents are the events of the last sync that couldn't be read
+	SkippedEvents []SkippedEvent `json:"skipped_events,omitempty" bson:"skipped_events,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+
+	// SyncToken and SyncingUntil hold the feed for the sync that claimed it,
+	// so two syncs never import the same feed at once
+	SyncToken    uuid.UUID  `json:"-" bson:"sync_token,omitempty"`
+	SyncingUntil *time.Time `json:"-" bson:"syncing_until,omitempty"`
+}
+
+// SkippedEvent is a feed event left out of a sync, with the reason why
+type SkippedEvent struct {
+	UID    string `json:"uid" bson:"uid"`
+	Reason string `json:"reason" bson:"reason"`
+}
+
+// NewCalendarFeed creates a feed import for an item
+func NewCalendarFeed(rentalItemID, ownerID uuid.UUID, name, feedURL string) (*CalendarFeed, error) {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return nil, ErrInvalidFeedURL
+	}
+	if err := CheckFeedURL(u); err != nil {
+		return nil, err
+	}
+
+	return &CalendarFeed{
+		ID:           uuid.New(),
+		RentalItemID: rentalItemID,
+		OwnerID:      ownerID,
+		Name:         name,
+		URL:          u.String(),
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// RecordSync notes the outcome of a sync attempt
+func (f *CalendarFeed) RecordSync(at time.Time, eventCount int, skipped []SkippedEvent, err error) {
+	f.LastSyncedAt = &at
+	if err != nil {
+		f.LastError = err.Error()
+		return
+	}
+	f.LastError = ""
+	f.EventCount = eventCount
+	f.SkippedEvents = skipped
+}
+
+// CheckFeedURL accepts http and https URLs whose host isn't obviously
+// internal. Hostnames can only be judged once resolved, so the addresses a
+// feed is fetched from must be checked with IsPublicAddress as well.
+func CheckFeedURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidFeedURL
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !IsPublicAddress(addr) {
+			return ErrFeedAddress
+		}
+		return nil
+	}
+	if !strings.Contains(host, ".") {
+		return ErrFeedAddress
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".cluster.local"} {
+		if strings.HasSuffix(host, suffix) {
+			return ErrFeedAddress
+		}
+	}
+	return nil
+}
+
+// nonPublicPrefixes are ranges that aren't reachable from the internet, on
+// top of the loopback, private and link-local ranges netip knows about
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// IsPublicAddress reports whether a feed may be fetched from the address. It
+// refuses loopback, private, link-local, shared (carrier-grade NAT, often
+// used for cluster networks) and other reserved addresses, so a feed URL
+// can't reach the services running next to us.
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewCalendarFeedChecksURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://calendar.example.com/feed.ics", nil},
+		{"http://93.184.216.34/feed.ics", nil},
+		{"ftp://calendar.example.com/feed.ics", ErrInvalidFeedURL},
+		{"https:///feed.ics", ErrInvalidFeedURL},
+		{"http://localhost/feed.ics", ErrFeedAddress},
+		{"http://auth-service:8080/api/users", ErrFeedAddress},
+		{"http://api.default.svc.cluster.local/", ErrFeedAddress},
+		{"http://metadata.google.internal/", ErrFeedAddress},
+		{"http://127.0.0.1:9000/", ErrFeedAddress},
+		{"http://[::1]/", ErrFeedAddress},
+		{"http://10.0.0.5/", ErrFeedAddress},
+		{"http://169.254.169.254/latest/meta-data/", ErrFeedAddress},
+	}
+	for _, tt := range tests {
+		_, err := NewCalendarFeed(uuid.New(), uuid.New(), "feed", tt.url)
+		if err != tt.want {
+			t.Errorf("NewCalendarFeed(%q) = %v, want %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"0.0.0.0", false},
+		{"10.96.0.1", false},
+		{"172.16.4.2", false},
+		{"192.168.1.1", false},
+		{"100.64.0.10", false},
+		{"169.254.169.254", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// slugPattern is lower-case words joined by single hyphens or underscores
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+
+// Category is a node in the admin-managed category tree. Items refer to
+// categories by slug, which never changes once created.
+type Category struct {
+	ID     uuid.UUID `json:"id" bson:"_id"`
+	Slug   string    `json:"slug" bson:"slug"`
+	Name   string    `json:"name" bson:"name"`
+	Parent string    `json:"parent,omitempty" bson:"parent,omitempty"`
+
+	// Ancestors are the slugs from the root down to the parent, so a subtree
+	// can be found with one query
+	Ancestors []string `json:"ancestors" bson:"ancestors"`
+
+	IsActive  bool      `json:"is_active" bson:"is_active"`
+	SortOrder int       `json:"sort_order" bson:"sort_order"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// NewCategory creates an active category under the parent, or a root if
+// parent is nil
+func NewCategory(slug, name string, parent *Category, sortOrder int) (*Category, error) {
+	if !slugPattern.MatchString(slug) || name == "" {
+		return nil, ErrInvalidCategory
+	}
+	now := time.Now()
+	category := &Category{
+		ID:        uuid.New(),
+		Slug:      slug,
+		Name:      name,
+		Ancestors: []string{},
+		IsActive:  true,
+		SortOrder: sortOrder,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if parent != nil {
+		category.Parent = parent.Slug
+		category.Ancestors = parent.Path()
+	}
+	return category, nil
+}
+
+// Path is the category's ancestors followed by its own slug
+func (c *Category) Path() []string {
+	path := make([]string, 0, len(c.Ancestors)+1)
+	path = append(path, c.Ancestors...)
+	return append(path, c.Slug)
+}
+
+// DefaultCategory is a category the tree is seeded with
+type DefaultCategory struct {
+	Slug     string
+	Name     string
+	Children []DefaultCategory
+}
+
+// DefaultCategories seed the tree the first time the service starts
+var DefaultCategories = []DefaultCategory{
+	{string(CategoryVehicle), "Vehicles", []DefaultCategory{
+		{"sedan", "Sedans", nil},
+		{"suv", "SUVs", nil},
+		{"van", "Vans", nil},
+		{"truck", "Trucks", nil},
+		{"motorcycle", "Motorcycles", nil},
+	}},
+	{string(CategoryEquipment), "Equipment", []DefaultCategory{
+		{"camera", "Cameras", nil},
+		{"camera_gear", "Camera Gear", nil},
+		{"tools", "Tools", nil},
+		{"audio", "Audio", nil},
+	}},
+	{string(CategoryProperty), "Property", []DefaultCategory{
+		{"apartment", "Apartments", nil},
+		{"house", "Houses", nil},
+		{"venue", "Venues", nil},
+	}},
+}
+
+// CategoryNode is a category with its children, for browsing the tree
+type CategoryNode struct {
+	*Category
+	Children []*CategoryNode `json:"children"`
+}
+
+// CategoryTree indexes the categories by slug
+type CategoryTree struct {
+	bySlug   map[string]*Category
+	children map[string][]*Category
+}
+
+// NewCategoryTree builds a tree from every stored category
+func NewCategoryTree(categories []*Category) *CategoryTree {
+	tree := &CategoryTree{
+		bySlug:   make(map[string]*Category, len(categories)),
+		children: make(map[string][]*Category),
+	}
+	for _, c := range categories {
+		tree.bySlug[c.Slug] = c
+		tree.children[c.Parent] = append(tree.children[c.Parent], c)
+	}
+	for _, siblings := range tree.children {
+		sort.Slice(siblings, func(i, j int) bool {
+			if siblings[i].SortOrder != siblings[j].SortOrder {
+				return siblings[i].SortOrder < siblings[j].SortOrder
+			}
+			return siblings[i].Name < siblings[j].Name
+		})
+	}
+	return tree
+}
+
+// Get finds a category by slug
+func (t *CategoryTree) Get(slug string) (*Category, bool) {
+	c, ok := t.bySlug[slug]
+	return c, ok
+}
+
+// IsActive reports whether the category and all its ancestors are active
+func (t *CategoryTree) IsActive(slug string) bool {
+	c, ok := t.bySlug[slug]
+	if !ok {
+		return false
+	}
+	for _, ancestor := range c.Path() {
+		if a, ok := t.bySlug[ancestor]; !ok || !a.IsActive {
+			return false
+		}
+	}
+	return true
+}
+
+// IsDescendant reports whether slug lies under ancestor
+func (t *CategoryTree) IsDescendant(slug, ancestor string) bool {
+	c, ok := t.bySlug[slug]
+	if !ok {
+		return false
+	}
+	for _, a := range c.Ancestors {
+		if a == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// HasChildren reports whether any category sits under slug
+func (t *CategoryTree) HasChildren(slug string) bool {
+	return len(t.children[slug]) > 0
+}
+
+// Resolve checks an item's category and optional subcategory, which must be an
+// active descendant of it, and returns the path to the most specific one
+func (t *CategoryTree) Resolve(category ItemCategory, subcategory string) ([]string, error) {
+	slug := string(category)
+	if !t.IsActive(slug) {
+		return nil, ErrInvalidCategory
+	}
+	if subcategory == "" || subcategory == slug {
+		return t.bySlug[slug].Path(), nil
+	}
+	if !t.IsActive(subcategory) || !t.IsDescendant(subcategory, slug) {
+		return nil, ErrInvalidCategory
+	}
+	return t.bySlug[subcategory].Path(), nil
+}
+
+// Nodes nests the categories under their parents, starting from the roots.
+// Inactive branches are left out unless includeInactive is set.
+func (t *CategoryTree) Nodes(includeInactive bool) []*CategoryNode {
+	return t.nodes("", includeInactive)
+}
+
+func (t *CategoryTree) nodes(parent string, includeInactive bool) []*CategoryNode {
+	nodes := []*CategoryNode{}
+	for _, c := range t.children[parent] {
+		if !includeInactive && !c.IsActive {
+			continue
+		}
+		nodes = append(nodes, &CategoryNode{Category: c, Children: t.nodes(c.Slug, includeInactive)})
+	}
+	return nodes
+}
+
+// Name is the display name for a slug, or the slug itself if it isn't in the tree
+func (t *CategoryTree) Name(slug string) string {
+	if c, ok := t.bySlug[slug]; ok {
+		return c.Name
+	}
+	return slug
+}
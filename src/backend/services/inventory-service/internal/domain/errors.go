@@ -6,16 +6,77 @@ import "errors"
 var (
 	// Item errors
 	ErrItemNotFound    = errors.New("rental item not found")
+	ErrItemChanged     = errors.New("rental item was changed by another request, please retry")
+	ErrItemExists      = errors.New("rental item already exists")
 	ErrUnauthorized    = errors.New("unauthorized to perform this action")
 	ErrInvalidCategory = errors.New("invalid item category")
 	ErrInvalidPrice    = errors.New("invalid pricing information")
+	ErrMissingTitle    = errors.New("item title is required")
+	ErrInvalidLocation = errors.New("invalid location or search area")
+	ErrTooManyImages   = errors.New("item already has the maximum number of images")
+	ErrImageNotFound   = errors.New("image not found on item")
+
+	// Unit errors
+	ErrUnitNotFound = errors.New("unit not found on item")
+	ErrInvalidUnit  = errors.New("invalid unit: a label is required and a serial number only fits a single unit")
+	ErrTooManyUnits = errors.New("item already has the maximum number of units")
+	ErrLastUnit     = errors.New("an item with units needs at least one active unit")
+	ErrUnitInUse    = errors.New("unit is reserved or needed for upcoming reservations")
+	ErrNoFreeUnit   = errors.New("no unit is free for the booking's dates")
+
+	// Add-on errors
+	ErrAddOnNotFound    = errors.New("add-on not offered with this item")
+	ErrInvalidAddOn     = errors.New("invalid add-on")
+	ErrTooManyAddOns    = errors.New("item already has the maximum number of add-ons")
+	ErrAddOnUnavailable = errors.New("not enough of the add-on is free for these dates")
+
+	// Pricing rule errors
+	ErrPricingRuleNotFound = errors.New("pricing rule not found on item")
+	ErrInvalidPricingRule  = errors.New("invalid pricing rule")
+	ErrTooManyPricingRules = errors.New("item already has the maximum number of pricing rules")
+
+	// Category errors
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryExists   = errors.New("a category with this slug already exists")
+	ErrCategoryInUse    = errors.New("category still has subcategories or items")
+
+	// Specification schema errors
+	ErrSchemaNotFound         = errors.New("specification schema not found")
+	ErrInvalidSchema          = errors.New("invalid specification schema")
+	ErrInvalidAttributeFilter = errors.New("invalid specification filter")
+	ErrUserNotFound           = errors.New("user not found")
+
+	// Rental rule errors
+	ErrInvalidRentalRules = errors.New("invalid rental rules")
 
 	// Availability errors
 	ErrSlotNotFound     = errors.New("availability slot not found")
 	ErrDateConflict     = errors.New("date range conflicts with existing bookings")
 	ErrInvalidDateRange = errors.New("invalid date range")
+	ErrReservationBusy  = errors.New("item is being reserved by another request, please retry")
+	ErrRangeTooLong     = errors.New("date range is too long")
+
+	// Calendar errors
+	ErrFeedNotFound   = errors.New("calendar feed not found")
+	ErrInvalidFeedURL = errors.New("calendar feed URL must be an http or https URL")
+	ErrFeedFetch      = errors.New("failed to fetch calendar feed")
+	ErrFeedAddress    = errors.New("calendar feed URL must point to a public internet address")
+	ErrFeedSyncing    = errors.New("calendar feed is already being synced, please retry")
+	ErrFeedClaimLost  = errors.New("calendar feed sync was taken over or the feed removed")
 
 	// Maintenance errors
-	ErrMaintenanceNotFound = errors.New("maintenance log not found")
-	ErrInvalidStatus       = errors.New("invalid status")
+	ErrMaintenanceNotFound    = errors.New("maintenance log not found")
+	ErrInvalidStatus          = errors.New("invalid status")
+	ErrMaintenanceStatus      = errors.New("maintenance can't move to that status from its current one")
+	ErrMissingMaintenanceType = errors.New("maintenance type is required")
+	ErrPlanNotFound           = errors.New("maintenance plan not found")
+	ErrInvalidMaintenancePlan = errors.New("maintenance plan needs an interval or usage limit, and a duration and lead time within limits")
+	ErrPlanClaimLost          = errors.New("maintenance plan check was taken over by another scheduler")
+
+	// Bulk import errors
+	ErrImportNotFound      = errors.New("import job not found")
+	ErrInvalidImportFormat = errors.New("import format must be csv or ndjson")
+	ErrInvalidDataset      = errors.New("export dataset must be items, maintenance or availability")
+	ErrImportTooLarge      = errors.New("import file is too large")
+	ErrImportClaimLost     = errors.New("import job was taken over by another worker")
 )
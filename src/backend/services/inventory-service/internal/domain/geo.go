@@ -0,0 +1,89 @@
+package domain
+
+// MaxSearchRadiusKm caps the radius of a distance search
+const MaxSearchRadiusKm = 500
+
+// GeoPoint is a GeoJSON point. Coordinates are [longitude, latitude], the
+// order MongoDB's 2dsphere index expects.
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+// NewGeoPoint builds a point from a latitude and longitude. (0, 0) is read as
+// no location, since that is what items without coordinates carry.
+func NewGeoPoint(latitude, longitude float64) (*GeoPoint, error) {
+	if latitude == 0 && longitude == 0 {
+		return nil, nil
+	}
+	if !validCoordinates(latitude, longitude) {
+		return nil, ErrInvalidLocation
+	}
+	return &GeoPoint{Type: "Point", Coordinates: []float64{longitude, latitude}}, nil
+}
+
+// SetCoordinates updates the item's latitude and longitude together with the
+// indexed point
+func (i *RentalItem) SetCoordinates(latitude, longitude float64) error {
+	point, err := NewGeoPoint(latitude, longitude)
+	if err != nil {
+		return err
+	}
+	i.Latitude = latitude
+	i.Longitude = longitude
+	i.GeoLocation = point
+	return nil
+}
+
+// GeoRadius matches items within RadiusKm of a point
+type GeoRadius struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+}
+
+// Validate checks the point and radius
+func (g GeoRadius) Validate() error {
+	if !validCoordinates(g.Latitude, g.Longitude) || g.RadiusKm <= 0 || g.RadiusKm > MaxSearchRadiusKm {
+		return ErrInvalidLocation
+	}
+	return nil
+}
+
+// BoundingBox matches items inside a latitude/longitude box. A box whose
+// MinLongitude is greater than its MaxLongitude crosses the antimeridian.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// Validate checks the corners
+func (b BoundingBox) Validate() error {
+	if !validCoordinates(b.MinLatitude, b.MinLongitude) || !validCoordinates(b.MaxLatitude, b.MaxLongitude) {
+		return ErrInvalidLocation
+	}
+	if b.MinLatitude >= b.MaxLatitude || b.MinLongitude == b.MaxLongitude {
+		return ErrInvalidLocation
+	}
+	return nil
+}
+
+// Center is the midpoint of the box, which distances are measured from
+func (b BoundingBox) Center() (latitude, longitude float64) {
+	latitude = (b.MinLatitude + b.MaxLatitude) / 2
+	maxLongitude := b.MaxLongitude
+	if b.MinLongitude > maxLongitude {
+		maxLongitude += 360
+	}
+	longitude = (b.MinLongitude + maxLongitude) / 2
+	if longitude > 180 {
+		longitude -= 360
+	}
+	return latitude, longitude
+}
+
+func validCoordinates(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
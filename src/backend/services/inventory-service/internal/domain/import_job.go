@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bulk import limits. Rows are kept on the job until it finishes, so the
+// whole file has to fit in one document.
+const (
+	MaxImportBytes = 5 << 20
+	MaxImportRows  = 2000
+)
+
+// ImportFormat is the file format of a bulk import or export
+type ImportFormat string
+
+const (
+	FormatCSV    ImportFormat = "csv"
+	FormatNDJSON ImportFormat = "ndjson"
+)
+
+// IsValid checks if the format is supported
+func (f ImportFormat) IsValid() bool {
+	return f == FormatCSV || f == FormatNDJSON
+}
+
+// ImportJobStatus tracks a bulk import through its life
+type ImportJobStatus string
+
+const (
+	ImportPending   ImportJobStatus = "pending"
+	ImportRunning   ImportJobStatus = "running"
+	ImportCompleted ImportJobStatus = "completed"
+)
+
+// ImportItem is one row of a bulk import, with the same fields as a request
+// to create an item
+type ImportItem struct {
+	Title           string            `json:"title" bson:"title"`
+	Description     string            `json:"description" bson:"description"`
+	Category        string            `json:"category" bson:"category"`
+	Subcategory     string            `json:"subcategory" bson:"subcategory"`
+	DailyRate       float64           `json:"daily_rate" bson:"daily_rate"`
+	WeeklyRate      float64           `json:"weekly_rate" bson:"weekly_rate"`
+	MonthlyRate     float64           `json:"monthly_rate" bson:"monthly_rate"`
+	SecurityDeposit float64           `json:"security_deposit" bson:"security_deposit"`
+	Address         string            `json:"address" bson:"address"`
+	City            string            `json:"city" bson:"city"`
+	Latitude        float64           `json:"latitude" bson:"latitude"`
+	Longitude       float64           `json:"longitude" bson:"longitude"`
+	Specifications  map[string]string `json:"specifications" bson:"specifications"`
+	Images          []string          `json:"images" bson:"images"`
+}
+
+// ImportRow is a parsed row waiting to be imported. Problems lists what made
+// the row unreadable, in which case it is reported without being imported.
+type ImportRow struct {
+	Line     int        `json:"line" bson:"line"`
+	Item     ImportItem `json:"item" bson:"item"`
+	Problems []string   `json:"problems,omitempty" bson:"problems,omitempty"`
+}
+
+// ImportRowResult is the outcome of importing one row
+type ImportRowResult struct {
+	Line   int        `json:"line" bson:"line"`
+	ItemID *uuid.UUID `json:"item_id,omitempty" bson:"item_id,omitempty"`
+	Errors []string   `json:"errors,omitempty" bson:"errors,omitempty"`
+}
+
+// OK reports whether the row was imported
+func (r ImportRowResult) OK() bool {
+	return r.ItemID != nil
+}
+
+// ImportJob is a bulk import of an owner's items, run in the background one
+// row at a time. Results are saved as each row finishes, so an interrupted
+// job resumes after the last saved row.
+type ImportJob struct {
+	ID        uuid.UUID         `json:"id" bson:"_id"`
+	OwnerID   uuid.UUID         `json:"owner_id" bson:"owner_id"`
+	Format    ImportFormat      `json:"format" bson:"format"`
+	Status    ImportJobStatus   `json:"status" bson:"status"`
+	Total     int               `json:"total" bson:"total"`
+	Succeeded int               `json:"succeeded" bson:"succeeded"`
+	Failed    int               `json:"failed" bson:"failed"`
+	Rows      []ImportRow       `json:"-" bson:"rows"`
+	Results   []ImportRowResult `json:"results" bson:"results"`
+
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+
+	// HeartbeatAt is refreshed as rows finish; a running job whose heartbeat
+	// stops is taken over by another worker
+	HeartbeatAt time.Time `json:"-" bson:"heartbeat_at"`
+	// ClaimToken changes with every claim, so a worker whose job was taken
+	// over can no longer save results for it
+	ClaimToken uuid.UUID `json:"-" bson:"claim_token,omitempty"`
+}
+
+// NewImportJob creates a pending job for the parsed rows
+func NewImportJob(ownerID uuid.UUID, format ImportFormat, rows []ImportRow) *ImportJob {
+	now := time.Now()
+	return &ImportJob{
+		ID:          uuid.New(),
+		OwnerID:     ownerID,
+		Format:      format,
+		Status:      ImportPending,
+		Total:       len(rows),
+		Rows:        rows,
+		Results:     []ImportRowResult{},
+		CreatedAt:   now,
+		HeartbeatAt: now,
+	}
+}
+
+// Remaining lists the rows without a result yet
+func (j *ImportJob) Remaining() []ImportRow {
+	done := make(map[int]bool, len(j.Results))
+	for _, result := range j.Results {
+		done[result.Line] = true
+	}
+	var rows []ImportRow
+	for _, row := range j.Rows {
+		if !done[row.Line] {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// RowItemID is the ID the item imported from a row gets. It is the same
+// every time the row is run, so a row imported again after an interrupted
+// run finds its item already there.
+func (j *ImportJob) RowItemID(line int) uuid.UUID {
+	return uuid.NewSHA1(j.ID, []byte(strconv.Itoa(line)))
+}
+
+// FailedResults lists the rows that were not imported
+func (j *ImportJob) FailedResults() []ImportRowResult {
+	var failed []ImportRowResult
+	for _, result := range j.Results {
+		if !result.OK() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// ImportFileError is an import file that can't be read at all, such as one
+// with unknown CSV columns
+type ImportFileError struct {
+	Problems []string
+}
+
+func (e *ImportFileError) Error() string {
+	return "invalid import file: " + strings.Join(e.Problems, "; ")
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Start marks scheduled maintenance as under way
+func (m *MaintenanceLog) Start(now time.Time) error {
+	if m.Status != MaintenanceScheduled {
+		return ErrMaintenanceStatus
+	}
+	m.Status = MaintenanceInProgress
+	m.StartedAt = &now
+	return nil
+}
+
+// Complete marks the maintenance done, recording the final cost if given.
+// Maintenance can be completed without being started first.
+func (m *MaintenanceLog) Complete(now time.Time, cost *float64) error {
+	if m.Status == MaintenanceCompleted {
+		return ErrMaintenanceStatus
+	}
+	if cost != nil {
+		if *cost < 0 {
+			return ErrInvalidPrice
+		}
+		m.Cost = *cost
+	}
+	if m.StartedAt == nil {
+		m.StartedAt = &now
+	}
+	m.Status = MaintenanceCompleted
+	m.CompletedAt = &now
+	return nil
+}
+
+// MaintenanceCostRow is the number and cost of an item's maintenance of one
+// type and status
+type MaintenanceCostRow struct {
+	MaintenanceType string            `bson:"maintenance_type"`
+	Status          MaintenanceStatus `bson:"status"`
+	Count           int               `bson:"count"`
+	Cost            float64           `bson:"cost"`
+}
+
+// MaintenanceCostLine totals the maintenance of one type
+type MaintenanceCostLine struct {
+	MaintenanceType string  `json:"maintenance_type"`
+	Count           int     `json:"count"`
+	Cost            float64 `json:"cost"`
+}
+
+// MaintenanceCosts totals maintenance spending. Spent counts completed work
+// and Planned counts work scheduled or under way.
+type MaintenanceCosts struct {
+	Count   int                   `json:"count"`
+	Total   float64               `json:"total"`
+	Spent   float64               `json:"spent"`
+	Planned float64               `json:"planned"`
+	ByType  []MaintenanceCostLine `json:"by_type"`
+}
+
+// NewMaintenanceCosts adds up the cost rows, listing types by cost, highest
+// first
+func NewMaintenanceCosts(rows []MaintenanceCostRow) *MaintenanceCosts {
+	costs := &MaintenanceCosts{ByType: []MaintenanceCostLine{}}
+	index := make(map[string]int)
+	for _, row := range rows {
+		costs.Count += row.Count
+		costs.Total += row.Cost
+		if row.Status == MaintenanceCompleted {
+			costs.Spent += row.Cost
+		} else {
+			costs.Planned += row.Cost
+		}
+
+		n, ok := index[row.MaintenanceType]
+		if !ok {
+			n = len(costs.ByType)
+			index[row.MaintenanceType] = n
+			costs.ByType = append(costs.ByType, MaintenanceCostLine{MaintenanceType: row.MaintenanceType})
+		}
+		costs.ByType[n].Count += row.Count
+		costs.ByType[n].Cost += row.Cost
+	}
+
+	sort.SliceStable(costs.ByType, func(i, j int) bool {
+		return costs.ByType[i].Cost > costs.ByType[j].Cost
+	})
+	return costs
+}
@@ -0,0 +1,296 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Maintenance plan limits
+const (
+	DefaultMaintenanceLeadDays = 14
+	MaxMaintenanceDays         = 30
+)
+
+// MaintenancePlanSettings are the parts of a maintenance plan the owner sets.
+// Maintenance is due every IntervalMonths months or every UsageDays rented
+// days since the item was last serviced, whichever comes first; either can
+// be zero to leave that trigger off.
+type MaintenancePlanSettings struct {
+	MaintenanceType string  `json:"maintenance_type" bson:"maintenance_type"`
+	Description     string  `json:"description" bson:"description"`
+	IntervalMonths  int     `json:"interval_months" bson:"interval_months"`
+	UsageDays       int     `json:"usage_days" bson:"usage_days"`
+	EstimatedCost   float64 `json:"estimated_cost" bson:"estimated_cost"`
+
+	// DurationDays is how many days the item is taken out of service, and
+	// LeadDays how far ahead of the due date those days are blocked
+	DurationDays int `json:"duration_days" bson:"duration_days"`
+	LeadDays     int `json:"lead_days" bson:"lead_days"`
+
+	IsActive bool `json:"is_active" bson:"is_active"`
+}
+
+// Validate checks the settings, filling in the default duration and lead time
+func (s *MaintenancePlanSettings) Validate() error {
+	s.MaintenanceType = strings.TrimSpace(s.MaintenanceType)
+	if s.MaintenanceType == "" {
+		return ErrMissingMaintenanceType
+	}
+	if s.IntervalMonths < 0 || s.UsageDays < 0 || (s.IntervalMonths == 0 && s.UsageDays == 0) {
+		return ErrInvalidMaintenancePlan
+	}
+	if s.EstimatedCost < 0 {
+		return ErrInvalidPrice
+	}
+
+	if s.DurationDays == 0 {
+		s.DurationDays = 1
+	}
+	if s.LeadDays == 0 {
+		s.LeadDays = DefaultMaintenanceLeadDays
+	}
+	if s.DurationDays < 0 || s.DurationDays > MaxMaintenanceDays || s.LeadDays < 0 || s.LeadDays > MaxCalendarDays {
+		return ErrInvalidMaintenancePlan
+	}
+	return nil
+}
+
+// MaintenancePlan is recurring preventive maintenance on an item, or on each
+// unit of an item with units. Each time maintenance falls due a
+// MaintenanceLog is scheduled for it; the next one isn't scheduled until
+// that log is completed.
+type MaintenancePlan struct {
+	ID                      uuid.UUID `json:"id" bson:"_id"`
+	RentalItemID            uuid.UUID `json:"rental_item_id" bson:"rental_item_id"`
+	OwnerID                 uuid.UUID `json:"owner_id" bson:"owner_id"`
+	MaintenancePlanSettings `bson:",inline"`
+
+	// LastServicedAt is when the plan's maintenance was last completed, or
+	// when the plan was created; usage is counted from it. Units serviced
+	// since are listed in ServicedUnits.
+	LastServicedAt time.Time      `json:"last_serviced_at" bson:"last_serviced_at"`
+	ServicedUnits  []UnitServiced `json:"serviced_units,omitempty" bson:"serviced_units,omitempty"`
+	PendingLogID   *uuid.UUID     `json:"pending_log_id,omitempty" bson:"pending_log_id,omitempty"`
+
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty" bson:"last_checked_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" bson:"updated_at"`
+
+	// CheckToken and CheckingUntil hold the plan for the check that claimed
+	// it, so two schedulers never check the same plan at once
+	CheckToken    uuid.UUID  `json:"-" bson:"check_token,omitempty"`
+	CheckingUntil *time.Time `json:"-" bson:"checking_until,omitempty"`
+}
+
+// NewMaintenancePlan creates a plan for an item that counts as serviced now
+func NewMaintenancePlan(rentalItemID, ownerID uuid.UUID, settings MaintenancePlanSettings) (*MaintenancePlan, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &MaintenancePlan{
+		ID:                      uuid.New(),
+		RentalItemID:            rentalItemID,
+		OwnerID:                 ownerID,
+		MaintenancePlanSettings: settings,
+		LastServicedAt:          now,
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}, nil
+}
+
+// UnitServiced is when the plan's maintenance was last completed on a unit
+type UnitServiced struct {
+	UnitID     uuid.UUID `json:"unit_id" bson:"unit_id"`
+	ServicedAt time.Time `json:"serviced_at" bson:"serviced_at"`
+}
+
+// ServicedAt is when the plan's maintenance was last completed on the unit,
+// or on the item for a nil unit
+func (p *MaintenancePlan) ServicedAt(unitID *uuid.UUID) time.Time {
+	if unitID != nil {
+		for _, unit := range p.ServicedUnits {
+			if unit.UnitID == *unitID {
+				return unit.ServicedAt
+			}
+		}
+	}
+	return p.LastServicedAt
+}
+
+// RecordService notes that the plan's maintenance was completed on the unit,
+// or on the item for a nil unit
+func (p *MaintenancePlan) RecordService(unitID *uuid.UUID, at time.Time) {
+	if unitID == nil {
+		p.LastServicedAt = at
+		return
+	}
+	for n := range p.ServicedUnits {
+		if p.ServicedUnits[n].UnitID == *unitID {
+			p.ServicedUnits[n].ServicedAt = at
+			return
+		}
+	}
+	p.ServicedUnits = append(p.ServicedUnits, UnitServiced{UnitID: *unitID, ServicedAt: at})
+}
+
+// EarliestService is the earliest the item or any of the units was last
+// serviced, which is as far back as usage has to be counted
+func (p *MaintenancePlan) EarliestService(units []ItemUnit) time.Time {
+	if len(units) == 0 {
+		return p.LastServicedAt
+	}
+	earliest := p.ServicedAt(&units[0].ID)
+	for _, unit := range units[1:] {
+		if at := p.ServicedAt(&unit.ID); at.Before(earliest) {
+			earliest = at
+		}
+	}
+	return earliest
+}
+
+// MaintenanceForecast is how much an item, or one of its units, has been
+// used since its last service and when its next maintenance is due. DueAt is
+// nil while the bookings on the calendar don't reach the usage limit and
+// there is no calendar interval.
+type MaintenanceForecast struct {
+	UnitID     *uuid.UUID `json:"unit_id,omitempty"`
+	RentedDays int        `json:"rented_days"`
+	DueAt      *time.Time `json:"due_at,omitempty"`
+}
+
+// Forecasts works out when maintenance is next due on each of the active
+// units, or on the item as a whole if it has none. A unit's usage only
+// counts the bookings assigned to it, which happens at pickup.
+func (p *MaintenancePlan) Forecasts(units []ItemUnit, slots []*AvailabilitySlot, now time.Time) []MaintenanceForecast {
+	if len(units) == 0 {
+		return []MaintenanceForecast{p.forecast(slots, p.LastServicedAt, now)}
+	}
+
+	forecasts := make([]MaintenanceForecast, len(units))
+	for n := range units {
+		unitID := units[n].ID
+		var assigned []*AvailabilitySlot
+		for _, slot := range slots {
+			if slot.UnitID != nil && *slot.UnitID == unitID {
+				assigned = append(assigned, slot)
+			}
+		}
+		forecasts[n] = p.forecast(assigned, p.ServicedAt(&unitID), now)
+		forecasts[n].UnitID = &unitID
+	}
+	return forecasts
+}
+
+// NextDue picks the forecast whose maintenance is due first, or nil if none
+// is due
+func NextDue(forecasts []MaintenanceForecast) *MaintenanceForecast {
+	var next *MaintenanceForecast
+	for n := range forecasts {
+		if due := forecasts[n].DueAt; due != nil && (next == nil || due.Before(*next.DueAt)) {
+			next = &forecasts[n]
+		}
+	}
+	return next
+}
+
+// forecast works out when maintenance is next due from the slots, counting
+// usage from since. Only confirmed bookings count, each once: those that
+// have ended count towards RentedDays, and those still ahead towards the due
+// date, which falls at the end of the booking that takes usage past the limit.
+func (p *MaintenancePlan) forecast(slots []*AvailabilitySlot, since, now time.Time) MaintenanceForecast {
+	var forecast MaintenanceForecast
+	var due time.Time
+	if p.IntervalMonths > 0 {
+		due = since.AddDate(0, p.IntervalMonths, 0)
+	}
+
+	booked := make([]*AvailabilitySlot, 0, len(slots))
+	seen := make(map[uuid.UUID]bool, len(slots))
+	for _, slot := range slots {
+		if slot.Status != StatusBooked || slot.BookingID == nil || !slot.Confirmed || seen[*slot.BookingID] {
+			continue
+		}
+		seen[*slot.BookingID] = true
+		booked = append(booked, slot)
+	}
+	sort.Slice(booked, func(i, j int) bool {
+		return booked[i].StartDate.Before(booked[j].StartDate)
+	})
+
+	total := 0
+	for _, slot := range booked {
+		start := slot.StartDate
+		if start.Before(since) {
+			start = since
+		}
+		if !slot.EndDate.After(start) {
+			continue
+		}
+		days := int(math.Ceil(slot.EndDate.Sub(start).Hours() / 24))
+		total += days
+		if !slot.EndDate.After(now) {
+			forecast.RentedDays += days
+		}
+		if p.UsageDays > 0 && total >= p.UsageDays {
+			if due.IsZero() || slot.EndDate.Before(due) {
+				due = slot.EndDate
+			}
+			break
+		}
+	}
+
+	if !due.IsZero() {
+		forecast.DueAt = &due
+	}
+	return forecast
+}
+
+// NextFreeUnitWindow finds the first run of days, starting on or after from,
+// over which the unit is free and taking it out of service still leaves room
+// for the item's other reservations
+func NextFreeUnitWindow(from time.Time, days int, slots []*AvailabilitySlot, capacity int, unitID uuid.UUID) time.Time {
+	start := StartOfDay(from)
+	for n := 0; n < MaxCalendarDays; n++ {
+		if Fits(slots, start, start.AddDate(0, 0, days), capacity, &unitID) {
+			break
+		}
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// NextFreeWindow finds the first run of days, starting on or after from,
+// that none of the slots block
+func NextFreeWindow(from time.Time, days int, slots []*AvailabilitySlot) time.Time {
+	blocking := make([]*AvailabilitySlot, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Status != StatusAvailable {
+			blocking = append(blocking, slot)
+		}
+	}
+	sort.Slice(blocking, func(i, j int) bool {
+		return blocking[i].StartDate.Before(blocking[j].StartDate)
+	})
+
+	start := StartOfDay(from)
+	for _, slot := range blocking {
+		end := start.AddDate(0, 0, days)
+		if !slot.StartDate.Before(end) {
+			break
+		}
+		if slot.EndDate.After(start) {
+			// Move past the slot to the start of the next whole day
+			start = StartOfDay(slot.EndDate)
+			if start.Before(slot.EndDate) {
+				start = start.AddDate(0, 0, 1)
+			}
+		}
+	}
+	return start
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func bookedSlot(from, to int, confirmed bool) *AvailabilitySlot {
+	slot := slotOn(from, to, StatusBooked)
+	bookingID := uuid.New()
+	slot.BookingID = &bookingID
+	slot.Confirmed = confirmed
+	return slot
+}
+
+func TestForecastsCountConfirmedBookings(t *testing.T) {
+	plan := &MaintenancePlan{LastServicedAt: day(0), MaintenancePlanSettings: MaintenancePlanSettings{UsageDays: 5}}
+	now := day(10)
+
+	split := bookedSlot(14, 16, true)
+	duplicate := *split
+	duplicate.ID = uuid.New()
+	slots := []*AvailabilitySlot{
+		bookedSlot(-2, 2, true),     // 2 days since the service
+		bookedSlot(3, 4, false),     // never confirmed
+		slotOn(5, 7, StatusBlocked), // not a booking
+		split, &duplicate,           // one booking, counted once
+		bookedSlot(20, 23, true), // takes usage past the limit
+	}
+
+	forecasts := plan.Forecasts(nil, slots, now)
+	if len(forecasts) != 1 {
+		t.Fatalf("got %d forecasts, want 1", len(forecasts))
+	}
+	got := forecasts[0]
+	if got.UnitID != nil || got.RentedDays != 2 {
+		t.Errorf("forecast = %+v, want 2 rented days for the item", got)
+	}
+	if got.DueAt == nil || !got.DueAt.Equal(day(23)) {
+		t.Errorf("due at %v, want %v", got.DueAt, day(23))
+	}
+}
+
+func TestForecastsIntervalComesFirst(t *testing.T) {
+	plan := &MaintenancePlan{LastServicedAt: day(0), MaintenancePlanSettings: MaintenancePlanSettings{IntervalMonths: 1, UsageDays: 60}}
+	forecasts := plan.Forecasts(nil, []*AvailabilitySlot{bookedSlot(1, 5, true)}, day(10))
+	if due := forecasts[0].DueAt; due == nil || !due.Equal(day(0).AddDate(0, 1, 0)) {
+		t.Errorf("due at %v, want a month after the service", due)
+	}
+}
+
+func TestForecastsPerUnit(t *testing.T) {
+	units := []ItemUnit{{ID: uuid.New(), IsActive: true}, {ID: uuid.New(), IsActive: true}}
+	plan := &MaintenancePlan{LastServicedAt: day(0), MaintenancePlanSettings: MaintenancePlanSettings{UsageDays: 4}}
+	plan.RecordService(&units[1].ID, day(6))
+
+	first := bookedSlot(1, 4, true)
+	first.UnitID = &units[0].ID
+	second := bookedSlot(5, 8, true)
+	second.UnitID = &units[1].ID
+	third := bookedSlot(9, 11, true)
+	third.UnitID = &units[0].ID
+	unassigned := bookedSlot(2, 9, true)
+
+	forecasts := plan.Forecasts(units, []*AvailabilitySlot{first, second, third, unassigned}, day(20))
+	if len(forecasts) != 2 {
+		t.Fatalf("got %d forecasts, want one per unit", len(forecasts))
+	}
+	if f := forecasts[0]; *f.UnitID != units[0].ID || f.RentedDays != 5 || f.DueAt == nil || !f.DueAt.Equal(day(11)) {
+		t.Errorf("first unit forecast = %+v, want 5 days due %v", f, day(11))
+	}
+	// Usage on the second unit only counts from its own service on day 6
+	if f := forecasts[1]; *f.UnitID != units[1].ID || f.RentedDays != 2 || f.DueAt != nil {
+		t.Errorf("second unit forecast = %+v, want 2 days and nothing due", f)
+	}
+
+	next := NextDue(forecasts)
+	if next == nil || *next.UnitID != units[0].ID {
+		t.Errorf("NextDue = %+v, want the first unit", next)
+	}
+	if earliest := plan.EarliestService(units); !earliest.Equal(day(0)) {
+		t.Errorf("EarliestService = %v, want %v", earliest, day(0))
+	}
+}
+
+func TestNextFreeUnitWindow(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	slots := []*AvailabilitySlot{
+		unitSlot(0, 3, a),          // the unit itself is out
+		slotOn(3, 5, StatusBooked), // with the other unit booked, neither is spare
+		unitSlot(3, 5, b),
+	}
+	if got := NextFreeUnitWindow(day(0), 2, slots, 2, a); !got.Equal(day(5)) {
+		t.Errorf("NextFreeUnitWindow = %v, want %v", got, day(5))
+	}
+	if got := NextFreeUnitWindow(day(0), 2, slots, 3, a); !got.Equal(day(3)) {
+		t.Errorf("NextFreeUnitWindow with a spare unit = %v, want %v", got, day(3))
+	}
+}
@@ -0,0 +1,462 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PricingRuleKind says when a pricing rule applies. Weekend and holiday rules
+// and seasons adjust the days they cover; last-minute and long-stay rules
+// adjust every day of a rental that qualifies.
+type PricingRuleKind string
+
+const (
+	PricingWeekend    PricingRuleKind = "weekend"
+	PricingHoliday    PricingRuleKind = "holiday"
+	PricingSeasonal   PricingRuleKind = "seasonal"
+	PricingLastMinute PricingRuleKind = "last_minute"
+	PricingLongStay   PricingRuleKind = "long_stay"
+)
+
+// Pricing rule limits. Adjustments are percentages of the day's price.
+const (
+	MaxPricingRules    = 30
+	MaxHolidayDates    = 100
+	MinPriceAdjustment = -90.0
+	MaxPriceAdjustment = 300.0
+	daysPerWeek        = 7
+	daysPerMonth       = 30
+)
+
+// pricingRuleNames name rules the owner left unnamed
+var pricingRuleNames = map[PricingRuleKind]string{
+	PricingWeekend:    "Weekend",
+	PricingHoliday:    "Holiday",
+	PricingSeasonal:   "Season",
+	PricingLastMinute: "Last minute",
+	PricingLongStay:   "Long stay",
+}
+
+// PricingRuleSettings are the parts of a pricing rule the owner sets.
+// Adjustment is the percentage added to the price of each day the rule
+// covers; a negative adjustment is a discount. Only the fields for the
+// rule's kind are kept.
+type PricingRuleSettings struct {
+	Name       string          `json:"name" bson:"name"`
+	Kind       PricingRuleKind `json:"kind" bson:"kind"`
+	Adjustment float64         `json:"adjustment_percent" bson:"adjustment_percent"`
+
+	// Weekdays are the days a weekend rule covers, Saturday and Sunday if none
+	// are given
+	Weekdays []time.Weekday `json:"weekdays,omitempty" bson:"weekdays,omitempty"`
+
+	// Dates are the days a holiday rule covers
+	Dates []time.Time `json:"dates,omitempty" bson:"dates,omitempty"`
+
+	// StartDate and EndDate (exclusive) bound a season
+	StartDate *time.Time `json:"start_date,omitempty" bson:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty" bson:"end_date,omitempty"`
+
+	// Yearly repeats a holiday's dates or a season on the same calendar days
+	// every year
+	Yearly bool `json:"yearly,omitempty" bson:"yearly,omitempty"`
+
+	// WithinDays is how soon a rental must start for a last-minute rule
+	WithinDays int `json:"within_days,omitempty" bson:"within_days,omitempty"`
+
+	// MinDays is how long a rental must be for a long-stay rule
+	MinDays int `json:"min_days,omitempty" bson:"min_days,omitempty"`
+
+	IsActive bool `json:"is_active" bson:"is_active"`
+}
+
+// Validate checks the settings for the rule's kind, naming an unnamed rule
+// after its kind and dropping fields other kinds use
+func (s *PricingRuleSettings) Validate() error {
+	name, ok := pricingRuleNames[s.Kind]
+	if !ok {
+		return ErrInvalidPricingRule
+	}
+	if s.Name = strings.TrimSpace(s.Name); s.Name == "" {
+		s.Name = name
+	}
+	if s.Adjustment == 0 || s.Adjustment < MinPriceAdjustment || s.Adjustment > MaxPriceAdjustment {
+		return ErrInvalidPricingRule
+	}
+
+	given := *s
+	*s = PricingRuleSettings{Name: given.Name, Kind: given.Kind, Adjustment: given.Adjustment, IsActive: given.IsActive}
+
+	switch s.Kind {
+	case PricingWeekend:
+		s.Weekdays = given.Weekdays
+		if len(s.Weekdays) == 0 {
+			s.Weekdays = []time.Weekday{time.Saturday, time.Sunday}
+		}
+		for _, day := range s.Weekdays {
+			if day < time.Sunday || day > time.Saturday {
+				return ErrInvalidPricingRule
+			}
+		}
+
+	case PricingHoliday:
+		if len(given.Dates) == 0 || len(given.Dates) > MaxHolidayDates {
+			return ErrInvalidPricingRule
+		}
+		for _, date := range given.Dates {
+			s.Dates = append(s.Dates, StartOfDay(date))
+		}
+		s.Yearly = given.Yearly
+
+	case PricingSeasonal:
+		if given.StartDate == nil || given.EndDate == nil {
+			return ErrInvalidPricingRule
+		}
+		s.StartDate, s.EndDate, s.Yearly = given.StartDate, given.EndDate, given.Yearly
+		if err := s.season().validate(); err != nil {
+			return ErrInvalidPricingRule
+		}
+
+	case PricingLastMinute:
+		if given.WithinDays < 1 || given.WithinDays > MaxCalendarDays {
+			return ErrInvalidPricingRule
+		}
+		s.WithinDays = given.WithinDays
+
+	case PricingLongStay:
+		if given.MinDays < 2 || given.MinDays > MaxCalendarDays {
+			return ErrInvalidPricingRule
+		}
+		s.MinDays = given.MinDays
+	}
+	return nil
+}
+
+// season is a seasonal rule's date range as a pattern, which matches days the
+// same way blackouts do
+func (s PricingRuleSettings) season() BlackoutPattern {
+	pattern := BlackoutPattern{Repeat: BlackoutOnce, StartDate: *s.StartDate, EndDate: *s.EndDate}
+	if s.Yearly {
+		pattern.Repeat = BlackoutYearly
+	}
+	return pattern
+}
+
+// covers reports whether a weekend, holiday or seasonal rule covers the day
+// starting at the given midnight UTC
+func (s PricingRuleSettings) covers(day time.Time) bool {
+	switch s.Kind {
+	case PricingWeekend:
+		for _, weekday := range s.Weekdays {
+			if day.Weekday() == weekday {
+				return true
+			}
+		}
+	case PricingHoliday:
+		for _, date := range s.Dates {
+			if date.Equal(day) || (s.Yearly && monthDay(date) == monthDay(day)) {
+				return true
+			}
+		}
+	case PricingSeasonal:
+		return s.season().Covers(day)
+	}
+	return false
+}
+
+// PricingRule is an owner's adjustment to the item's rates for certain days
+// or rentals
+type PricingRule struct {
+	ID                  uuid.UUID `json:"id" bson:"id"`
+	PricingRuleSettings `bson:",inline"`
+	CreatedAt           time.Time `json:"created_at" bson:"created_at"`
+}
+
+// PricingRule finds one of the item's pricing rules
+func (i *RentalItem) PricingRule(ruleID uuid.UUID) (*PricingRule, error) {
+	for n := range i.PricingRules {
+		if i.PricingRules[n].ID == ruleID {
+			return &i.PricingRules[n], nil
+		}
+	}
+	return nil, ErrPricingRuleNotFound
+}
+
+// AddPricingRule adds a pricing rule to the item
+func (i *RentalItem) AddPricingRule(settings PricingRuleSettings) (*PricingRule, error) {
+	if len(i.PricingRules) >= MaxPricingRules {
+		return nil, ErrTooManyPricingRules
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	i.PricingRules = append(i.PricingRules, PricingRule{
+		ID:                  uuid.New(),
+		PricingRuleSettings: settings,
+		CreatedAt:           time.Now(),
+	})
+	i.UpdatedAt = time.Now()
+	return &i.PricingRules[len(i.PricingRules)-1], nil
+}
+
+// UpdatePricingRule replaces a pricing rule's settings. Bookings already made
+// keep the price they were quoted.
+func (i *RentalItem) UpdatePricingRule(ruleID uuid.UUID, settings PricingRuleSettings) (*PricingRule, error) {
+	rule, err := i.PricingRule(ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	rule.PricingRuleSettings = settings
+	i.UpdatedAt = time.Now()
+	return rule, nil
+}
+
+// RemovePricingRule drops one of the item's pricing rules
+func (i *RentalItem) RemovePricingRule(ruleID uuid.UUID) error {
+	for n, rule := range i.PricingRules {
+		if rule.ID == ruleID {
+			i.PricingRules = append(i.PricingRules[:n:n], i.PricingRules[n+1:]...)
+			i.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return ErrPricingRuleNotFound
+}
+
+// PriceLine is one line of what a rental costs
+type PriceLine struct {
+	Description string  `json:"description"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	Amount      float64 `json:"amount"`
+}
+
+// PriceAdjustment is what a pricing rule changed a day's price by
+type PriceAdjustment struct {
+	RuleID  uuid.UUID `json:"rule_id"`
+	Name    string    `json:"name"`
+	Percent float64   `json:"percent"`
+	Amount  float64   `json:"amount"`
+}
+
+// DayPrice is the price of one day of a rental. BaseRate is the day's share
+// of the item's rates before any pricing rules.
+type DayPrice struct {
+	Date        time.Time         `json:"date"`
+	BaseRate    float64           `json:"base_rate"`
+	Adjustments []PriceAdjustment `json:"adjustments,omitempty"`
+	Price       float64           `json:"price"`
+}
+
+// RentalPrice is what renting an item over a date range costs, day by day and
+// as the lines it is charged in. The lines are what add up to the total; the
+// days, each rounded on its own, may differ from it by a few cents.
+type RentalPrice struct {
+	ItemID    uuid.UUID   `json:"item_id"`
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date"`
+	TotalDays int         `json:"total_days"`
+	Days      []DayPrice  `json:"days"`
+	Lines     []PriceLine `json:"lines"`
+	Total     float64     `json:"total"`
+}
+
+// Price works out what renting the item over [startDate, endDate) costs when
+// booked at the given time. The rental is first charged in whole months,
+// weeks and days, and that charge spread evenly over its days. Each day is
+// then adjusted by the holiday, season or weekend rule covering it, in that
+// order of precedence, then by the longest long-stay rule the rental meets
+// and the tightest last-minute rule it falls within. Adjustments compound,
+// and each rule's total is charged as a line of its own.
+func (i *RentalItem) Price(startDate, endDate, now time.Time) *RentalPrice {
+	days := RentalDays(startDate, endDate)
+	lines := priceDays(days, i.DailyRate, i.WeeklyRate, i.MonthlyRate)
+	baseRate := sumLines(lines) / float64(days)
+
+	stayRules := i.stayRules(startDate, days, now)
+
+	type ruleTotal struct {
+		rule   *PricingRule
+		days   int
+		amount float64
+	}
+	var totals []*ruleTotal
+	byRule := map[uuid.UUID]*ruleTotal{}
+
+	result := &RentalPrice{
+		ItemID:    i.ID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		TotalDays: days,
+		Days:      make([]DayPrice, 0, days),
+	}
+	for n := 0; n < days; n++ {
+		day := StartOfDay(startDate.AddDate(0, 0, n))
+		price := baseRate
+		dayPrice := DayPrice{Date: day, BaseRate: roundAmount(baseRate)}
+
+		rules := stayRules
+		if rule := i.dayRule(day); rule != nil {
+			rules = append([]*PricingRule{rule}, stayRules...)
+		}
+		for _, rule := range rules {
+			amount := price * rule.Adjustment / 100
+			price += amount
+			dayPrice.Adjustments = append(dayPrice.Adjustments, PriceAdjustment{
+				RuleID:  rule.ID,
+				Name:    rule.Name,
+				Percent: rule.Adjustment,
+				Amount:  roundAmount(amount),
+			})
+
+			total, ok := byRule[rule.ID]
+			if !ok {
+				total = &ruleTotal{rule: rule}
+				byRule[rule.ID] = total
+				totals = append(totals, total)
+			}
+			total.days++
+			total.amount += amount
+		}
+
+		dayPrice.Price = roundAmount(price)
+		result.Days = append(result.Days, dayPrice)
+	}
+
+	for _, total := range totals {
+		lines = append(lines, PriceLine{
+			Description: fmt.Sprintf("%s (%+g%%)", total.rule.Name, total.rule.Adjustment),
+			Quantity:    total.days,
+			UnitPrice:   roundAmount(total.amount / float64(total.days)),
+			Amount:      roundAmount(total.amount),
+		})
+	}
+	result.Lines = lines
+	result.Total = roundAmount(sumLines(lines))
+	return result
+}
+
+// dayRule picks the active rule adjusting a day: a holiday before a season
+// before a weekend, and the first listed among rules of the same kind
+func (i *RentalItem) dayRule(day time.Time) *PricingRule {
+	for _, kind := range []PricingRuleKind{PricingHoliday, PricingSeasonal, PricingWeekend} {
+		for n := range i.PricingRules {
+			rule := &i.PricingRules[n]
+			if rule.IsActive && rule.Kind == kind && rule.covers(day) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// stayRules picks the active rules adjusting the whole rental: the long-stay
+// rule with the highest minimum the rental meets, then the last-minute rule
+// with the shortest window the start falls within
+func (i *RentalItem) stayRules(startDate time.Time, days int, now time.Time) []*PricingRule {
+	var longStay, lastMinute *PricingRule
+	notice := startDate.Sub(now)
+	for n := range i.PricingRules {
+		rule := &i.PricingRules[n]
+		if !rule.IsActive {
+			continue
+		}
+		switch rule.Kind {
+		case PricingLongStay:
+			if days >= rule.MinDays && (longStay == nil || rule.MinDays > longStay.MinDays) {
+				longStay = rule
+			}
+		case PricingLastMinute:
+			window := time.Duration(rule.WithinDays) * 24 * time.Hour
+			if notice < window && (lastMinute == nil || rule.WithinDays < lastMinute.WithinDays) {
+				lastMinute = rule
+			}
+		}
+	}
+
+	var rules []*PricingRule
+	for _, rule := range []*PricingRule{longStay, lastMinute} {
+		if rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// priceDays charges whole months, then whole weeks, then single days. A
+// leftover that would cost more than the next tier up is charged as one more
+// unit of that tier instead, so a longer rental is never cheaper.
+func priceDays(days int, dailyRate, weeklyRate, monthlyRate float64) []PriceLine {
+	if monthlyRate <= 0 {
+		return priceBelowMonth(days, dailyRate, weeklyRate)
+	}
+
+	months := days / daysPerMonth
+	rest := priceBelowMonth(days%daysPerMonth, dailyRate, weeklyRate)
+	if sumLines(rest) > monthlyRate {
+		months++
+		rest = nil
+	}
+
+	var lines []PriceLine
+	if months > 0 {
+		lines = append(lines, newPriceLine("month", months, monthlyRate))
+	}
+	return append(lines, rest...)
+}
+
+func priceBelowMonth(days int, dailyRate, weeklyRate float64) []PriceLine {
+	weeks := 0
+	if weeklyRate > 0 {
+		weeks = days / daysPerWeek
+		days = days % daysPerWeek
+		if float64(days)*dailyRate > weeklyRate {
+			weeks++
+			days = 0
+		}
+	}
+
+	var lines []PriceLine
+	if weeks > 0 {
+		lines = append(lines, newPriceLine("week", weeks, weeklyRate))
+	}
+	if days > 0 {
+		lines = append(lines, newPriceLine("day", days, dailyRate))
+	}
+	return lines
+}
+
+func newPriceLine(unit string, quantity int, unitPrice float64) PriceLine {
+	description := unit
+	if quantity != 1 {
+		description += "s"
+	}
+	return PriceLine{
+		Description: description,
+		Quantity:    quantity,
+		UnitPrice:   unitPrice,
+		Amount:      roundAmount(float64(quantity) * unitPrice),
+	}
+}
+
+func sumLines(lines []PriceLine) float64 {
+	total := 0.0
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return total
+}
+
+// roundAmount rounds to the nearest cent
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
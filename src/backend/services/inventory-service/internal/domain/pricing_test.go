@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func pricedItem(t *testing.T, dailyRate, weeklyRate float64, rules ...PricingRuleSettings) *RentalItem {
+	t.Helper()
+	item := &RentalItem{DailyRate: dailyRate, WeeklyRate: weeklyRate}
+	for _, settings := range rules {
+		if _, err := item.AddPricingRule(settings); err != nil {
+			t.Fatalf("AddPricingRule(%+v): %v", settings, err)
+		}
+	}
+	return item
+}
+
+func date(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func timeRef(t time.Time) *time.Time {
+	return &t
+}
+
+// day(0) is a Sunday, so day(6) and day(7) make up the first full weekend
+func TestPriceDayRulePrecedence(t *testing.T) {
+	item := pricedItem(t, 100, 0,
+		PricingRuleSettings{Name: "Weekend", Kind: PricingWeekend, Adjustment: 10, IsActive: true},
+		PricingRuleSettings{Name: "Summer", Kind: PricingSeasonal, Adjustment: 20, StartDate: timeRef(day(5)), EndDate: timeRef(day(8)), IsActive: true},
+		PricingRuleSettings{Name: "High summer", Kind: PricingSeasonal, Adjustment: 40, StartDate: timeRef(day(5)), EndDate: timeRef(day(8)), IsActive: true},
+		PricingRuleSettings{Name: "Fair", Kind: PricingHoliday, Adjustment: 50, Dates: []time.Time{day(6)}, IsActive: true},
+		PricingRuleSettings{Name: "Closed", Kind: PricingHoliday, Adjustment: -50, Dates: []time.Time{day(6), day(13)}},
+	)
+
+	tests := []struct {
+		name  string
+		day   int
+		rule  string
+		price float64
+	}{
+		{"weekday", 1, "", 100},
+		{"first season listed", 5, "Summer", 120},
+		{"holiday over season and weekend", 6, "Fair", 150},
+		{"season over weekend", 7, "Summer", 120},
+		{"season ends before its end date", 8, "", 100},
+		{"weekend, inactive holiday ignored", 13, "Weekend", 110},
+	}
+	for _, tt := range tests {
+		got := item.Price(day(tt.day), day(tt.day+1), day(-60)).Days[0]
+		rule := ""
+		if len(got.Adjustments) == 1 {
+			rule = got.Adjustments[0].Name
+		} else if len(got.Adjustments) > 1 {
+			t.Errorf("%s: got %d adjustments, want at most one", tt.name, len(got.Adjustments))
+			continue
+		}
+		if rule != tt.rule || got.Price != tt.price {
+			t.Errorf("%s: adjusted by %q to %v, want %q to %v", tt.name, rule, got.Price, tt.rule, tt.price)
+		}
+	}
+}
+
+func TestPriceYearlyRules(t *testing.T) {
+	item := pricedItem(t, 100, 0,
+		PricingRuleSettings{Kind: PricingHoliday, Adjustment: 50, Dates: []time.Time{date(2020, time.July, 4)}, Yearly: true, IsActive: true},
+		PricingRuleSettings{Kind: PricingHoliday, Adjustment: 30, Dates: []time.Time{date(2020, time.May, 1)}, IsActive: true},
+		PricingRuleSettings{Kind: PricingSeasonal, Adjustment: 20, StartDate: timeRef(date(2020, time.December, 20)), EndDate: timeRef(date(2021, time.January, 5)), Yearly: true, IsActive: true},
+		PricingRuleSettings{Kind: PricingSeasonal, Adjustment: -20, StartDate: timeRef(date(2020, time.February, 1)), EndDate: timeRef(date(2020, time.March, 1)), IsActive: true},
+	)
+
+	tests := []struct {
+		day   time.Time
+		price float64
+	}{
+		{date(2025, time.July, 4), 150},      // yearly holiday
+		{date(2025, time.July, 5), 100},      // the day after
+		{date(2025, time.May, 1), 100},       // one-off holiday in another year
+		{date(2020, time.May, 1), 130},       // one-off holiday itself
+		{date(2025, time.December, 24), 120}, // yearly season
+		{date(2026, time.January, 2), 120},   // yearly season over new year
+		{date(2026, time.January, 5), 100},   // yearly season's end
+		{date(2025, time.February, 10), 100}, // one-off season in another year
+		{date(2020, time.February, 10), 80},  // one-off season itself
+	}
+	for _, tt := range tests {
+		got := item.Price(tt.day, tt.day.AddDate(0, 0, 1), tt.day.AddDate(-1, 0, 0)).Total
+		if got != tt.price {
+			t.Errorf("price on %s = %v, want %v", tt.day.Format("2006-01-02"), got, tt.price)
+		}
+	}
+}
+
+func TestPriceStayRuleSelection(t *testing.T) {
+	item := pricedItem(t, 100, 0,
+		PricingRuleSettings{Name: "Week", Kind: PricingLongStay, Adjustment: -10, MinDays: 7, IsActive: true},
+		PricingRuleSettings{Name: "Fortnight", Kind: PricingLongStay, Adjustment: -20, MinDays: 14, IsActive: true},
+		PricingRuleSettings{Name: "Month", Kind: PricingLongStay, Adjustment: -40, MinDays: 28},
+		PricingRuleSettings{Name: "This week", Kind: PricingLastMinute, Adjustment: -5, WithinDays: 7, IsActive: true},
+		PricingRuleSettings{Name: "Tomorrow", Kind: PricingLastMinute, Adjustment: -15, WithinDays: 2, IsActive: true},
+	)
+
+	tests := []struct {
+		name  string
+		start int
+		days  int
+		want  []string
+	}{
+		{"short and far off", 30, 3, nil},
+		{"meets the shorter stay", 30, 7, []string{"Week"}},
+		{"longest stay met wins", 30, 28, []string{"Fortnight"}},
+		{"inside the wider window", 5, 3, []string{"This week"}},
+		{"window ends before its last day", 7, 3, nil},
+		{"tightest window wins", 1, 3, []string{"Tomorrow"}},
+		{"long stay before last minute", 1, 14, []string{"Fortnight", "Tomorrow"}},
+	}
+	for _, tt := range tests {
+		price := item.Price(day(tt.start), day(tt.start+tt.days), day(0))
+		got := price.Days[0].Adjustments
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: adjustments = %+v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for n := range got {
+			if got[n].Name != tt.want[n] {
+				t.Errorf("%s: adjustments = %+v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+		for _, d := range price.Days {
+			if len(d.Adjustments) != len(got) {
+				t.Errorf("%s: %s adjusted by %d rules, want every day adjusted alike", tt.name, d.Date.Format("2006-01-02"), len(d.Adjustments))
+				break
+			}
+		}
+	}
+}
+
+func TestPriceCompoundsAndRounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       *RentalItem
+		start, end int
+		days       []float64
+		lines      []PriceLine
+		total      float64
+	}{
+		{
+			name: "day rule then stay rule",
+			item: pricedItem(t, 100, 0,
+				PricingRuleSettings{Name: "Weekend", Kind: PricingWeekend, Adjustment: 20, IsActive: true},
+				PricingRuleSettings{Name: "Long stay", Kind: PricingLongStay, Adjustment: -10, MinDays: 7, IsActive: true},
+			),
+			start: 1, end: 11,
+			days: []float64{90, 90, 90, 90, 90, 108, 108, 90, 90, 90},
+			lines: []PriceLine{
+				{Description: "days", Quantity: 10, UnitPrice: 100, Amount: 1000},
+				{Description: "Long stay (-10%)", Quantity: 10, UnitPrice: -10.4, Amount: -104},
+				{Description: "Weekend (+20%)", Quantity: 2, UnitPrice: 20, Amount: 40},
+			},
+			total: 936,
+		},
+		{
+			name: "weekly rate spread over the days",
+			item: pricedItem(t, 20, 100,
+				PricingRuleSettings{Name: "Weekend", Kind: PricingWeekend, Adjustment: 10, IsActive: true},
+			),
+			start: 0, end: 7,
+			days: []float64{15.71, 14.29, 14.29, 14.29, 14.29, 14.29, 15.71},
+			lines: []PriceLine{
+				{Description: "week", Quantity: 1, UnitPrice: 100, Amount: 100},
+				{Description: "Weekend (+10%)", Quantity: 2, UnitPrice: 1.43, Amount: 2.86},
+			},
+			total: 102.86,
+		},
+	}
+	for _, tt := range tests {
+		price := tt.item.Price(day(tt.start), day(tt.end), day(-60))
+		if len(price.Days) != len(tt.days) {
+			t.Fatalf("%s: got %d days, want %d", tt.name, len(price.Days), len(tt.days))
+		}
+		for n, want := range tt.days {
+			if price.Days[n].Price != want {
+				t.Errorf("%s: day %d costs %v, want %v", tt.name, n, price.Days[n].Price, want)
+			}
+		}
+		if len(price.Lines) != len(tt.lines) {
+			t.Errorf("%s: lines = %+v, want %+v", tt.name, price.Lines, tt.lines)
+		} else {
+			for n, want := range tt.lines {
+				if price.Lines[n] != want {
+					t.Errorf("%s: line %d = %+v, want %+v", tt.name, n, price.Lines[n], want)
+				}
+			}
+		}
+		if price.Total != tt.total {
+			t.Errorf("%s: total = %v, want %v", tt.name, price.Total, tt.total)
+		}
+	}
+}
@@ -4,26 +4,19 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rentalflow/rentalflow/pkg/media"
 )
 
-// ItemCategory represents the category of a rental item
+// ItemCategory is the slug of a category in the category tree
 type ItemCategory string
 
+// The root categories the tree starts out with
 const (
 	CategoryVehicle   ItemCategory = "vehicle"
 	CategoryEquipment ItemCategory = "equipment"
 	CategoryProperty  ItemCategory = "property"
 )
 
-// IsValid checks if the category is valid
-func (c ItemCategory) IsValid() bool {
-	switch c {
-	case CategoryVehicle, CategoryEquipment, CategoryProperty:
-		return true
-	}
-	return false
-}
-
 // AvailabilityStatus represents the availability status
 type AvailabilityStatus string
 
@@ -52,6 +45,10 @@ type RentalItem struct {
 	Category    ItemCategory `json:"category" bson:"category"`
 	Subcategory string       `json:"subcategory" bson:"subcategory"`
 
+	// CategoryPath runs from the root category down to the item's most
+	// specific one, so browsing a category finds items in its descendants
+	CategoryPath []string `json:"category_path" bson:"category_path"`
+
 	// Pricing
 	DailyRate       float64 `json:"daily_rate" bson:"daily_rate"`
 	WeeklyRate      float64 `json:"weekly_rate" bson:"weekly_rate"`
@@ -64,15 +61,51 @@ type RentalItem struct {
 	Latitude  float64 `json:"latitude" bson:"latitude"`
 	Longitude float64 `json:"longitude" bson:"longitude"`
 
+	// GeoLocation mirrors Latitude/Longitude as an indexed GeoJSON point
+	GeoLocation *GeoPoint `json:"-" bson:"location,omitempty"`
+
+	// DistanceKm and Relevance are filled in by searches only, and
+	// QuotedPrice by searches for dates
+	DistanceKm  *float64 `json:"distance_km,omitempty" bson:"distance_km,omitempty"`
+	Relevance   *float64 `json:"relevance,omitempty" bson:"relevance,omitempty"`
+	QuotedPrice *float64 `json:"quoted_price,omitempty" bson:"-"`
+
 	// Specifications (stored as map)
 	Specifications map[string]string `json:"specifications" bson:"specifications"`
 
+	// Attributes holds the specifications typed by the category's schema, for
+	// filtering; items in categories without a schema have none
+	Attributes map[string]interface{} `json:"-" bson:"attributes,omitempty"`
+
 	// Images
 	Images []string `json:"images" bson:"images"`
 
-	IsActive  bool      `json:"is_active" bson:"is_active"`
-	CreatedAt time.Time `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+	// Media describes the images uploaded through the service along with their
+	// generated sizes. Images given as plain URLs have no entry.
+	Media []media.Asset `json:"media" bson:"media,omitempty"`
+
+	// Booking rules set by the owner
+	Rules RentalRules `json:"rules" bson:"rules"`
+
+	// Units are the identical physical units rented out under the listing,
+	// if there are several. Capacity mirrors UnitCapacity for queries.
+	Units    []ItemUnit `json:"units" bson:"units,omitempty"`
+	Capacity int        `json:"capacity" bson:"capacity"`
+
+	// AddOns are the extras and bundles offered with the item
+	AddOns []AddOn `json:"add_ons" bson:"add_ons,omitempty"`
+
+	// PricingRules adjust the rates for certain days and rentals
+	PricingRules []PricingRule `json:"pricing_rules" bson:"pricing_rules,omitempty"`
+
+	IsActive   bool      `json:"is_active" bson:"is_active"`
+	IsFeatured bool      `json:"is_featured" bson:"is_featured"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" bson:"updated_at"`
+
+	// Version counts the saved changes to the item, so an update made from a
+	// stale copy is refused rather than overwriting newer changes
+	Version int64 `json:"-" bson:"version"`
 }
 
 // NewRentalItem creates a new rental item
@@ -87,12 +120,54 @@ func NewRentalItem(ownerID uuid.UUID, title, description string, category ItemCa
 		Subcategory:    subcategory,
 		Specifications: make(map[string]string),
 		Images:         []string{},
+		Capacity:       1,
 		IsActive:       true,
 		CreatedAt:      now,
 		UpdatedAt:      now,
 	}
 }
 
+// MaxItemImages is the most images an item can have
+const MaxItemImages = 12
+
+// AddImage appends an uploaded image to the item's images
+func (i *RentalItem) AddImage(asset *media.Asset) error {
+	if len(i.Images) >= MaxItemImages {
+		return ErrTooManyImages
+	}
+	i.Images = append(i.Images, asset.URL)
+	i.Media = append(i.Media, *asset)
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// RemoveImage drops the image with the given URL. It returns the image's
+// uploaded files, if it had any, so they can be deleted from storage.
+func (i *RentalItem) RemoveImage(url string) (*media.Asset, error) {
+	found := false
+	images := make([]string, 0, len(i.Images))
+	for _, image := range i.Images {
+		if image == url && !found {
+			found = true
+			continue
+		}
+		images = append(images, image)
+	}
+	if !found {
+		return nil, ErrImageNotFound
+	}
+	i.Images = images
+	i.UpdatedAt = time.Now()
+
+	for n, asset := range i.Media {
+		if asset.URL == url {
+			i.Media = append(i.Media[:n:n], i.Media[n+1:]...)
+			return &asset, nil
+		}
+	}
+	return nil, nil
+}
+
 // AvailabilitySlot represents an availability slot for a rental item
 type AvailabilitySlot struct {
 	ID           uuid.UUID          `json:"id" bson:"_id"`
@@ -101,7 +176,16 @@ type AvailabilitySlot struct {
 	EndDate      time.Time          `json:"end_date" bson:"end_date"`
 	Status       AvailabilityStatus `json:"status" bson:"status"`
 	BookingID    *uuid.UUID         `json:"booking_id,omitempty" bson:"booking_id,omitempty"`
-	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	// Confirmed is set once the booking holding the slot has been confirmed
+	Confirmed bool `json:"confirmed,omitempty" bson:"confirmed,omitempty"`
+	// UnitID is the unit the slot holds; bookings are assigned one at pickup
+	UnitID *uuid.UUID `json:"unit_id,omitempty" bson:"unit_id,omitempty"`
+	// AddOns are the add-ons reserved with a booking's dates
+	AddOns []BookedAddOn `json:"add_ons,omitempty" bson:"add_ons,omitempty"`
+	// FeedID and ExternalUID identify slots imported from an external calendar
+	FeedID      *uuid.UUID `json:"feed_id,omitempty" bson:"feed_id,omitempty"`
+	ExternalUID string     `json:"external_uid,omitempty" bson:"external_uid,omitempty"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
 }
 
 // NewAvailabilitySlot creates a new availability slot
@@ -126,7 +210,21 @@ type MaintenanceLog struct {
 	EndDate         *time.Time        `json:"end_date,omitempty" bson:"end_date,omitempty"`
 	Cost            float64           `json:"cost" bson:"cost"`
 	Status          MaintenanceStatus `json:"status" bson:"status"`
-	CreatedAt       time.Time         `json:"created_at" bson:"created_at"`
+
+	// SlotID is the maintenance slot blocking the item's dates until the
+	// work is completed
+	SlotID *uuid.UUID `json:"slot_id,omitempty" bson:"slot_id,omitempty"`
+
+	// UnitID is the unit being serviced, for items with several units
+	UnitID *uuid.UUID `json:"unit_id,omitempty" bson:"unit_id,omitempty"`
+
+	// PlanID is the maintenance plan that scheduled the work, if any
+	PlanID *uuid.UUID `json:"plan_id,omitempty" bson:"plan_id,omitempty"`
+
+	StartedAt   *time.Time `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" bson:"completed_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
 }
 
 // NewMaintenanceLog creates a new maintenance log
@@ -0,0 +1,265 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RentalRules are the owner's limits on how an item may be booked
+type RentalRules struct {
+	// MinDays and MaxDays bound the rental length; zero means no limit
+	MinDays int `json:"min_days" bson:"min_days"`
+	MaxDays int `json:"max_days" bson:"max_days"`
+
+	// LeadTimeHours is the notice needed before a rental starts
+	LeadTimeHours int `json:"lead_time_hours" bson:"lead_time_hours"`
+
+	// MaxAdvanceDays is how far ahead a rental may start; zero means no limit
+	MaxAdvanceDays int `json:"max_advance_days" bson:"max_advance_days"`
+
+	// Blackouts are recurring or one-off periods the item is never rented out
+	Blackouts []BlackoutPattern `json:"blackouts" bson:"blackouts"`
+}
+
+// BlackoutRepeat says how a blackout pattern recurs
+type BlackoutRepeat string
+
+const (
+	BlackoutOnce   BlackoutRepeat = "once"
+	BlackoutWeekly BlackoutRepeat = "weekly"
+	BlackoutYearly BlackoutRepeat = "yearly"
+)
+
+// BlackoutPattern blocks the given weekdays every week, the days from
+// StartDate to EndDate (exclusive) once, or the same calendar days every year
+type BlackoutPattern struct {
+	Repeat    BlackoutRepeat `json:"repeat" bson:"repeat"`
+	Weekdays  []time.Weekday `json:"weekdays,omitempty" bson:"weekdays,omitempty"`
+	StartDate time.Time      `json:"start_date" bson:"start_date"`
+	EndDate   time.Time      `json:"end_date" bson:"end_date"`
+	Reason    string         `json:"reason,omitempty" bson:"reason,omitempty"`
+
+	// MonthDays lists the MMDD days a yearly pattern covers, so that
+	// searches can match them without date arithmetic
+	MonthDays []int `json:"-" bson:"month_days,omitempty"`
+}
+
+// Rule violation codes returned by RentalRules.Check
+const (
+	ViolationMinDuration   = "min_duration"
+	ViolationMaxDuration   = "max_duration"
+	ViolationLeadTime      = "lead_time"
+	ViolationAdvanceWindow = "advance_window"
+	ViolationBlackout      = "blackout"
+	ViolationUnavailable   = "dates_unavailable"
+	ViolationItemInactive  = "item_inactive"
+)
+
+// RuleViolation is one reason a rental can't be booked
+type RuleViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BookingValidation is the outcome of checking a rental against an item's
+// availability and rules
+type BookingValidation struct {
+	Valid      bool            `json:"valid"`
+	Violations []RuleViolation `json:"reasons"`
+}
+
+// NewBookingValidation is valid exactly when there are no violations
+func NewBookingValidation(violations []RuleViolation) *BookingValidation {
+	if violations == nil {
+		violations = []RuleViolation{}
+	}
+	return &BookingValidation{Valid: len(violations) == 0, Violations: violations}
+}
+
+// Validate checks that the rules are consistent
+func (r RentalRules) Validate() error {
+	if r.MinDays < 0 || r.MaxDays < 0 || r.LeadTimeHours < 0 || r.MaxAdvanceDays < 0 {
+		return ErrInvalidRentalRules
+	}
+	if r.MaxDays > 0 && r.MinDays > r.MaxDays {
+		return ErrInvalidRentalRules
+	}
+	for _, blackout := range r.Blackouts {
+		if err := blackout.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Normalize fills in the derived fields of the blackout patterns
+func (r *RentalRules) Normalize() {
+	for i := range r.Blackouts {
+		b := &r.Blackouts[i]
+		b.MonthDays = nil
+		if b.Repeat == BlackoutYearly {
+			b.MonthDays = MonthDaysBetween(b.StartDate, b.EndDate)
+		}
+	}
+}
+
+// MonthDaysBetween lists the MMDD of each day in [from, to), at most one year's worth
+func MonthDaysBetween(from, to time.Time) []int {
+	var days []int
+	seen := map[int]bool{}
+	for day := StartOfDay(from); day.Before(to) && len(seen) < 366; day = day.AddDate(0, 0, 1) {
+		if md := monthDay(day); !seen[md] {
+			seen[md] = true
+			days = append(days, md)
+		}
+	}
+	return days
+}
+
+// WeekdaysBetween lists the weekdays of the days in [from, to)
+func WeekdaysBetween(from, to time.Time) []time.Weekday {
+	var weekdays []time.Weekday
+	for day := StartOfDay(from); day.Before(to) && len(weekdays) < 7; day = day.AddDate(0, 0, 1) {
+		weekdays = append(weekdays, day.Weekday())
+	}
+	return weekdays
+}
+
+func (b BlackoutPattern) validate() error {
+	switch b.Repeat {
+	case BlackoutWeekly:
+		if len(b.Weekdays) == 0 {
+			return ErrInvalidRentalRules
+		}
+		for _, day := range b.Weekdays {
+			if day < time.Sunday || day > time.Saturday {
+				return ErrInvalidRentalRules
+			}
+		}
+	case BlackoutOnce:
+		if !b.EndDate.After(b.StartDate) {
+			return ErrInvalidRentalRules
+		}
+	case BlackoutYearly:
+		if !b.EndDate.After(b.StartDate) || b.EndDate.After(b.StartDate.AddDate(1, 0, 0)) {
+			return ErrInvalidRentalRules
+		}
+	default:
+		return ErrInvalidRentalRules
+	}
+	return nil
+}
+
+// Covers reports whether the pattern blacks out the day starting at the given
+// midnight UTC
+func (b BlackoutPattern) Covers(day time.Time) bool {
+	switch b.Repeat {
+	case BlackoutWeekly:
+		for _, weekday := range b.Weekdays {
+			if day.Weekday() == weekday {
+				return true
+			}
+		}
+	case BlackoutOnce:
+		return !day.Before(StartOfDay(b.StartDate)) && day.Before(b.EndDate)
+	case BlackoutYearly:
+		// Compare month and day only; a range may wrap over new year
+		start, end, d := monthDay(b.StartDate), monthDay(b.EndDate), monthDay(day)
+		if start < end {
+			return d >= start && d < end
+		}
+		return d >= start || d < end
+	}
+	return false
+}
+
+func monthDay(t time.Time) int {
+	t = t.UTC()
+	return int(t.Month())*100 + t.Day()
+}
+
+// IsBlackedOut reports whether any blackout pattern covers the day
+func (r RentalRules) IsBlackedOut(day time.Time) bool {
+	return r.blackoutOn(day) != nil
+}
+
+func (r RentalRules) blackoutOn(day time.Time) *BlackoutPattern {
+	for i := range r.Blackouts {
+		if r.Blackouts[i].Covers(day) {
+			return &r.Blackouts[i]
+		}
+	}
+	return nil
+}
+
+// EarliestStart is the first moment a rental may start at the given time, or
+// the zero time if no notice is needed
+func (r RentalRules) EarliestStart(now time.Time) time.Time {
+	if r.LeadTimeHours == 0 {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(r.LeadTimeHours) * time.Hour)
+}
+
+// LatestStart is the last moment a rental may start at the given time, or the
+// zero time if there is no limit
+func (r RentalRules) LatestStart(now time.Time) time.Time {
+	if r.MaxAdvanceDays == 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, r.MaxAdvanceDays)
+}
+
+// Check lists the ways renting over [startDate, endDate) at the given time
+// breaks the rules
+func (r RentalRules) Check(startDate, endDate, now time.Time) []RuleViolation {
+	var violations []RuleViolation
+
+	days := RentalDays(startDate, endDate)
+	if r.MinDays > 0 && days < r.MinDays {
+		violations = append(violations, RuleViolation{
+			Code:    ViolationMinDuration,
+			Message: fmt.Sprintf("rentals must be at least %d days", r.MinDays),
+		})
+	}
+	if r.MaxDays > 0 && days > r.MaxDays {
+		violations = append(violations, RuleViolation{
+			Code:    ViolationMaxDuration,
+			Message: fmt.Sprintf("rentals can be at most %d days", r.MaxDays),
+		})
+	}
+	if earliest := r.EarliestStart(now); !earliest.IsZero() && startDate.Before(earliest) {
+		violations = append(violations, RuleViolation{
+			Code:    ViolationLeadTime,
+			Message: fmt.Sprintf("rentals need at least %d hours notice", r.LeadTimeHours),
+		})
+	}
+	if latest := r.LatestStart(now); !latest.IsZero() && startDate.After(latest) {
+		violations = append(violations, RuleViolation{
+			Code:    ViolationAdvanceWindow,
+			Message: fmt.Sprintf("rentals can be booked at most %d days ahead", r.MaxAdvanceDays),
+		})
+	}
+	for day := StartOfDay(startDate); day.Before(endDate); day = day.AddDate(0, 0, 1) {
+		if blackout := r.blackoutOn(day); blackout != nil {
+			message := "the item is not available on " + day.Format("2006-01-02")
+			if blackout.Reason != "" {
+				message += ": " + blackout.Reason
+			}
+			violations = append(violations, RuleViolation{Code: ViolationBlackout, Message: message})
+			break
+		}
+	}
+
+	return violations
+}
+
+// RentalDays counts the whole days in a rental, with a minimum of one, the
+// same way bookings are priced
+func RentalDays(startDate, endDate time.Time) int {
+	days := int(endDate.Sub(startDate).Hours() / 24)
+	if days < 1 {
+		days = 1
+	}
+	return days
+}
@@ -0,0 +1,350 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttributeType is the kind of value a specification holds
+type AttributeType string
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeInteger AttributeType = "integer"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeEnum    AttributeType = "enum"
+)
+
+// IsValid checks if the attribute type is known
+func (t AttributeType) IsValid() bool {
+	switch t {
+	case AttributeString, AttributeNumber, AttributeInteger, AttributeBoolean, AttributeEnum:
+		return true
+	}
+	return false
+}
+
+// numeric reports whether values of the type can be compared by size
+func (t AttributeType) numeric() bool {
+	return t == AttributeNumber || t == AttributeInteger
+}
+
+// AttributeSpec defines one specification items in a category may carry
+type AttributeSpec struct {
+	Key      string        `json:"key" bson:"key"`
+	Label    string        `json:"label" bson:"label"`
+	Type     AttributeType `json:"type" bson:"type"`
+	Unit     string        `json:"unit,omitempty" bson:"unit,omitempty"`
+	Options  []string      `json:"options,omitempty" bson:"options,omitempty"`
+	Required bool          `json:"required" bson:"required"`
+
+	// Aliases are other keys owners have used for the attribute, such as
+	// "seat_count" for "seats". They are rewritten to Key when an item is saved.
+	Aliases []string `json:"aliases,omitempty" bson:"aliases,omitempty"`
+}
+
+// SpecSchema is the admin-defined set of specifications for a category. A
+// schema with a Subcategory adds to, and overrides, the category's own.
+type SpecSchema struct {
+	ID          uuid.UUID       `json:"id" bson:"_id"`
+	Category    ItemCategory    `json:"category" bson:"category"`
+	Subcategory string          `json:"subcategory" bson:"subcategory"`
+	Attributes  []AttributeSpec `json:"attributes" bson:"attributes"`
+	UpdatedBy   uuid.UUID       `json:"updated_by" bson:"updated_by"`
+	CreatedAt   time.Time       `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" bson:"updated_at"`
+}
+
+// NewSpecSchema creates a schema for a category, or one of its subcategories
+func NewSpecSchema(category ItemCategory, subcategory string, attributes []AttributeSpec, adminID uuid.UUID) (*SpecSchema, error) {
+	now := time.Now()
+	schema := &SpecSchema{
+		ID:          uuid.New(),
+		Category:    category,
+		Subcategory: strings.TrimSpace(subcategory),
+		CreatedAt:   now,
+	}
+	if err := schema.SetAttributes(attributes, adminID); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// SetAttributes replaces the schema's attributes after normalizing their keys
+func (s *SpecSchema) SetAttributes(attributes []AttributeSpec, adminID uuid.UUID) error {
+	seen := map[string]bool{}
+	normalized := make([]AttributeSpec, len(attributes))
+	for i, attr := range attributes {
+		attr.Key = NormalizeAttributeKey(attr.Key)
+		if attr.Key == "" || !attr.Type.IsValid() || seen[attr.Key] {
+			return ErrInvalidSchema
+		}
+		seen[attr.Key] = true
+		if attr.Label == "" {
+			attr.Label = attr.Key
+		}
+
+		if (attr.Type == AttributeEnum) != (len(attr.Options) > 0) {
+			return ErrInvalidSchema
+		}
+		options := map[string]bool{}
+		for _, option := range attr.Options {
+			option = strings.ToLower(strings.TrimSpace(option))
+			if option == "" || options[option] {
+				return ErrInvalidSchema
+			}
+			options[option] = true
+		}
+
+		aliases := make([]string, 0, len(attr.Aliases))
+		own := map[string]bool{attr.Key: true}
+		for _, alias := range attr.Aliases {
+			if alias = NormalizeAttributeKey(alias); alias != "" && !own[alias] {
+				own[alias] = true
+				aliases = append(aliases, alias)
+			}
+		}
+		attr.Aliases = aliases
+		normalized[i] = attr
+	}
+
+	// An alias must not be mistaken for another attribute
+	for _, attr := range normalized {
+		for _, alias := range attr.Aliases {
+			if seen[alias] {
+				return ErrInvalidSchema
+			}
+			seen[alias] = true
+		}
+	}
+
+	s.Attributes = normalized
+	s.UpdatedBy = adminID
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// NormalizeAttributeKey lower-cases a key and joins its words with underscores,
+// so "Seat Count" and "seat-count" become "seat_count"
+func NormalizeAttributeKey(key string) string {
+	fields := strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_' || r == '.' || r == '$'
+	})
+	return strings.Join(fields, "_")
+}
+
+// AttributeSet is the combined schema items of one category and subcategory
+// are validated against
+type AttributeSet []AttributeSpec
+
+// MergeSchemas combines schemas in order, later attributes replacing earlier
+// ones with the same key
+func MergeSchemas(schemas ...*SpecSchema) AttributeSet {
+	var set AttributeSet
+	index := map[string]int{}
+	for _, schema := range schemas {
+		if schema == nil {
+			continue
+		}
+		for _, attr := range schema.Attributes {
+			if i, ok := index[attr.Key]; ok {
+				set[i] = attr
+				continue
+			}
+			index[attr.Key] = len(set)
+			set = append(set, attr)
+		}
+	}
+	return set
+}
+
+// Lookup finds the attribute a key or one of its aliases names
+func (a AttributeSet) Lookup(key string) (AttributeSpec, bool) {
+	key = NormalizeAttributeKey(key)
+	for _, attr := range a {
+		if attr.Key == key {
+			return attr, true
+		}
+	}
+	for _, attr := range a {
+		for _, alias := range attr.Aliases {
+			if alias == key {
+				return attr, true
+			}
+		}
+	}
+	return AttributeSpec{}, false
+}
+
+// Apply checks an item's specifications against the set. It returns them with
+// schema keys and canonical values, along with the typed values searches
+// filter on.
+func (a AttributeSet) Apply(specs map[string]string) (map[string]string, map[string]interface{}, error) {
+	canonical := make(map[string]string, len(specs))
+	typed := make(map[string]interface{}, len(specs))
+	var problems []string
+
+	keys := make([]string, 0, len(specs))
+	for key := range specs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		raw := strings.TrimSpace(specs[key])
+		if raw == "" {
+			continue
+		}
+		attr, ok := a.Lookup(key)
+		if !ok {
+			problems = append(problems, "unknown specification "+strconv.Quote(key))
+			continue
+		}
+		if _, dup := typed[attr.Key]; dup {
+			problems = append(problems, "specification "+strconv.Quote(attr.Key)+" is given more than once")
+			continue
+		}
+		value, text, err := attr.Parse(raw)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		canonical[attr.Key] = text
+		typed[attr.Key] = value
+	}
+
+	for _, attr := range a {
+		if _, ok := typed[attr.Key]; attr.Required && !ok {
+			problems = append(problems, "specification "+strconv.Quote(attr.Key)+" is required")
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, &SpecificationError{Problems: problems}
+	}
+	return canonical, typed, nil
+}
+
+// Parse reads a raw value as the attribute's type. It returns the typed value
+// and its canonical text; numbers may carry the attribute's unit.
+func (a AttributeSpec) Parse(raw string) (interface{}, string, error) {
+	raw = strings.TrimSpace(raw)
+	invalid := func(want string) error {
+		return errors.New("specification " + strconv.Quote(a.Key) + " must be " + want + ", got " + strconv.Quote(raw))
+	}
+
+	if a.Type.numeric() && a.Unit != "" && len(raw) > len(a.Unit) &&
+		strings.EqualFold(raw[len(raw)-len(a.Unit):], a.Unit) {
+		raw = strings.TrimSpace(raw[:len(raw)-len(a.Unit)])
+	}
+
+	switch a.Type {
+	case AttributeNumber:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, "", invalid("a number")
+		}
+		return v, strconv.FormatFloat(v, 'f', -1, 64), nil
+	case AttributeInteger:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, "", invalid("a whole number")
+		}
+		return v, strconv.FormatInt(v, 10), nil
+	case AttributeBoolean:
+		switch strings.ToLower(raw) {
+		case "true", "yes", "1":
+			return true, "true", nil
+		case "false", "no", "0":
+			return false, "false", nil
+		}
+		return nil, "", invalid("true or false")
+	case AttributeEnum:
+		for _, option := range a.Options {
+			if strings.EqualFold(strings.TrimSpace(option), raw) {
+				option = strings.TrimSpace(option)
+				return option, option, nil
+			}
+		}
+		return nil, "", invalid("one of " + strings.Join(a.Options, ", "))
+	}
+	return raw, raw, nil
+}
+
+// SpecificationError lists the ways an item's specifications break its
+// category's schema
+type SpecificationError struct {
+	Problems []string
+}
+
+func (e *SpecificationError) Error() string {
+	return "invalid specifications: " + strings.Join(e.Problems, "; ")
+}
+
+// AttributeOp compares a specification with a filter value
+type AttributeOp string
+
+const (
+	AttributeEq  AttributeOp = "="
+	AttributeNe  AttributeOp = "!="
+	AttributeGt  AttributeOp = ">"
+	AttributeGte AttributeOp = ">="
+	AttributeLt  AttributeOp = "<"
+	AttributeLte AttributeOp = "<="
+)
+
+// attributeOps is checked in order, so two-character operators win
+var attributeOps = []AttributeOp{AttributeGte, AttributeLte, AttributeNe, AttributeGt, AttributeLt, AttributeEq}
+
+// AttributeFilter matches items whose specification Key compares to Value.
+// Value is the raw text until Resolve types it against a schema.
+type AttributeFilter struct {
+	Key   string
+	Op    AttributeOp
+	Value interface{}
+}
+
+// ParseAttributeFilter reads an expression such as "seats>=5" or
+// "transmission=automatic"
+func ParseAttributeFilter(expr string) (AttributeFilter, error) {
+	at := strings.IndexAny(expr, "<>=!")
+	if at <= 0 {
+		return AttributeFilter{}, ErrInvalidAttributeFilter
+	}
+	rest := expr[at:]
+	for _, op := range attributeOps {
+		if strings.HasPrefix(rest, string(op)) {
+			key := NormalizeAttributeKey(expr[:at])
+			value := strings.TrimSpace(rest[len(op):])
+			if key == "" || value == "" {
+				return AttributeFilter{}, ErrInvalidAttributeFilter
+			}
+			return AttributeFilter{Key: key, Op: op, Value: value}, nil
+		}
+	}
+	return AttributeFilter{}, ErrInvalidAttributeFilter
+}
+
+// Resolve types the filter's value as the attribute it names. Only numbers can
+// be compared by size.
+func (f AttributeFilter) Resolve(attr AttributeSpec) (AttributeFilter, error) {
+	raw, ok := f.Value.(string)
+	if !ok {
+		return f, nil
+	}
+	if f.Op != AttributeEq && f.Op != AttributeNe && !attr.Type.numeric() {
+		return AttributeFilter{}, ErrInvalidAttributeFilter
+	}
+	value, _, err := attr.Parse(raw)
+	if err != nil {
+		return AttributeFilter{}, ErrInvalidAttributeFilter
+	}
+	return AttributeFilter{Key: attr.Key, Op: f.Op, Value: value}, nil
+}
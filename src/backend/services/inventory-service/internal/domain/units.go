@@ -0,0 +1,273 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxItemUnits is the most units a listing can have
+const MaxItemUnits = 500
+
+// ItemUnit is one of several identical physical units rented out under a
+// single listing, such as one drill out of ten. Each booking is assigned a
+// unit at pickup.
+type ItemUnit struct {
+	ID           uuid.UUID `json:"id" bson:"id"`
+	Label        string    `json:"label" bson:"label"`
+	SerialNumber string    `json:"serial_number,omitempty" bson:"serial_number,omitempty"`
+	IsActive     bool      `json:"is_active" bson:"is_active"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+}
+
+// UnitCapacity is how many reservations the item can hold at once: its
+// active units, or one for an item without units
+func (i *RentalItem) UnitCapacity() int {
+	if len(i.Units) == 0 {
+		return 1
+	}
+	active := 0
+	for _, unit := range i.Units {
+		if unit.IsActive {
+			active++
+		}
+	}
+	return active
+}
+
+// ActiveUnits lists the units that can be rented out
+func (i *RentalItem) ActiveUnits() []ItemUnit {
+	var units []ItemUnit
+	for _, unit := range i.Units {
+		if unit.IsActive {
+			units = append(units, unit)
+		}
+	}
+	return units
+}
+
+// Unit finds one of the item's units
+func (i *RentalItem) Unit(unitID uuid.UUID) (*ItemUnit, error) {
+	for n := range i.Units {
+		if i.Units[n].ID == unitID {
+			return &i.Units[n], nil
+		}
+	}
+	return nil, ErrUnitNotFound
+}
+
+// AddUnits adds count active units. Units without a label are numbered after
+// the existing ones; a label given for several units is numbered the same way.
+func (i *RentalItem) AddUnits(count int, label, serialNumber string) ([]ItemUnit, error) {
+	label = strings.TrimSpace(label)
+	if count < 1 || (count > 1 && serialNumber != "") {
+		return nil, ErrInvalidUnit
+	}
+	if len(i.Units)+count > MaxItemUnits {
+		return nil, ErrTooManyUnits
+	}
+
+	now := time.Now()
+	added := make([]ItemUnit, count)
+	for n := range added {
+		name := label
+		if name == "" {
+			name = fmt.Sprintf("Unit %d", len(i.Units)+1)
+		} else if count > 1 {
+			name = fmt.Sprintf("%s %d", label, n+1)
+		}
+		unit := ItemUnit{
+			ID:           uuid.New(),
+			Label:        name,
+			SerialNumber: strings.TrimSpace(serialNumber),
+			IsActive:     true,
+			CreatedAt:    now,
+		}
+		i.Units = append(i.Units, unit)
+		added[n] = unit
+	}
+	i.refreshCapacity()
+	return added, nil
+}
+
+// UpdateUnit changes a unit's label, serial number and whether it is rented out
+func (i *RentalItem) UpdateUnit(unitID uuid.UUID, label, serialNumber string, isActive bool) (*ItemUnit, error) {
+	unit, err := i.Unit(unitID)
+	if err != nil {
+		return nil, err
+	}
+	if label = strings.TrimSpace(label); label == "" {
+		return nil, ErrInvalidUnit
+	}
+	wasActive := unit.IsActive
+	unit.Label = label
+	unit.SerialNumber = strings.TrimSpace(serialNumber)
+	unit.IsActive = isActive
+	if i.UnitCapacity() == 0 {
+		unit.IsActive = wasActive
+		return nil, ErrLastUnit
+	}
+	i.refreshCapacity()
+	return unit, nil
+}
+
+// RemoveUnit drops a unit. Removing the only unit leaves an item without
+// units, which rents out as a single thing.
+func (i *RentalItem) RemoveUnit(unitID uuid.UUID) error {
+	for n, unit := range i.Units {
+		if unit.ID != unitID {
+			continue
+		}
+		units := append(i.Units[:n:n], i.Units[n+1:]...)
+		if len(units) > 0 && (&RentalItem{Units: units}).UnitCapacity() == 0 {
+			return ErrLastUnit
+		}
+		i.Units = units
+		i.refreshCapacity()
+		return nil
+	}
+	return ErrUnitNotFound
+}
+
+func (i *RentalItem) refreshCapacity() {
+	i.Capacity = i.UnitCapacity()
+	i.UpdatedAt = time.Now()
+}
+
+// PeakReservations is the most slots reserving the item at any one moment
+// within [start, end)
+func PeakReservations(slots []*AvailabilitySlot, start, end time.Time) int {
+	return peakUsage(slots, start, end, func(*AvailabilitySlot) int { return 1 })
+}
+
+// peakUsage is the most the slots use at any one moment within [start, end),
+// each slot using its weight
+func peakUsage(slots []*AvailabilitySlot, start, end time.Time, weight func(*AvailabilitySlot) int) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	var events []event
+	for _, slot := range slots {
+		if slot.Status == StatusAvailable || !slot.StartDate.Before(end) || !slot.EndDate.After(start) {
+			continue
+		}
+		w := weight(slot)
+		if w == 0 {
+			continue
+		}
+		from, to := slot.StartDate, slot.EndDate
+		if from.Before(start) {
+			from = start
+		}
+		if to.After(end) {
+			to = end
+		}
+		events = append(events, event{from, w}, event{to, -w})
+	}
+
+	// A slot ending as another starts doesn't overlap it
+	sort.Slice(events, func(a, b int) bool {
+		if events[a].at.Equal(events[b].at) {
+			return events[a].delta < events[b].delta
+		}
+		return events[a].at.Before(events[b].at)
+	})
+
+	peak, current := 0, 0
+	for _, e := range events {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+	return peak
+}
+
+// ReservedSpan is a stretch of time, end exclusive
+type ReservedSpan struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FullyReserved lists the stretches of time over which the slots take up all
+// of the capacity
+func FullyReserved(slots []*AvailabilitySlot, capacity int) []ReservedSpan {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	var events []event
+	for _, slot := range slots {
+		if slot.Status != StatusAvailable && slot.EndDate.After(slot.StartDate) {
+			events = append(events, event{slot.StartDate, 1}, event{slot.EndDate, -1})
+		}
+	}
+	sort.Slice(events, func(a, b int) bool {
+		if events[a].at.Equal(events[b].at) {
+			return events[a].delta < events[b].delta
+		}
+		return events[a].at.Before(events[b].at)
+	})
+
+	var spans []ReservedSpan
+	current := 0
+	for _, e := range events {
+		before := current
+		current += e.delta
+		switch {
+		case before < capacity && current >= capacity:
+			spans = append(spans, ReservedSpan{Start: e.at})
+		case before >= capacity && current < capacity:
+			last := &spans[len(spans)-1]
+			if last.Start.Equal(e.at) {
+				spans = spans[:len(spans)-1]
+			} else {
+				last.End = e.at
+			}
+		}
+	}
+	// Spans that end as the next begins are one span
+	merged := spans[:0]
+	for _, span := range spans {
+		if n := len(merged); n > 0 && merged[n-1].End.Equal(span.Start) {
+			merged[n-1].End = span.End
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// Fits reports whether one more reservation over [start, end) fits alongside
+// the slots without exceeding the item's capacity. A reservation of a
+// particular unit also needs that unit to be free.
+func Fits(slots []*AvailabilitySlot, start, end time.Time, capacity int, unitID *uuid.UUID) bool {
+	if unitID != nil && unitBusy(slots, *unitID, start, end) {
+		return false
+	}
+	return PeakReservations(slots, start, end)+1 <= capacity
+}
+
+// FreeUnit picks the first of the units that no slot holds over [start, end)
+func FreeUnit(units []ItemUnit, slots []*AvailabilitySlot, start, end time.Time) (*ItemUnit, error) {
+	for n := range units {
+		if !unitBusy(slots, units[n].ID, start, end) {
+			return &units[n], nil
+		}
+	}
+	return nil, ErrNoFreeUnit
+}
+
+func unitBusy(slots []*AvailabilitySlot, unitID uuid.UUID, start, end time.Time) bool {
+	for _, slot := range slots {
+		if slot.UnitID != nil && *slot.UnitID == unitID && slot.Status != StatusAvailable &&
+			slot.StartDate.Before(end) && slot.EndDate.After(start) {
+			return true
+		}
+	}
+	return false
+}
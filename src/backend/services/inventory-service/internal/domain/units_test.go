@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func day(n int) time.Time {
+	return time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, n)
+}
+
+func slotOn(from, to int, status AvailabilityStatus) *AvailabilitySlot {
+	return NewAvailabilitySlot(uuid.Nil, day(from), day(to), status)
+}
+
+func unitSlot(from, to int, unitID uuid.UUID) *AvailabilitySlot {
+	slot := slotOn(from, to, StatusBooked)
+	slot.UnitID = &unitID
+	return slot
+}
+
+func TestPeakReservations(t *testing.T) {
+	tests := []struct {
+		name  string
+		slots []*AvailabilitySlot
+		want  int
+	}{
+		{"none", nil, 0},
+		{"overlapping the range but not each other", []*AvailabilitySlot{slotOn(0, 2, StatusBooked), slotOn(3, 5, StatusBooked)}, 1},
+		{"one ends as the next starts", []*AvailabilitySlot{slotOn(0, 2, StatusBooked), slotOn(2, 4, StatusBooked)}, 1},
+		{"stacked", []*AvailabilitySlot{slotOn(0, 4, StatusBooked), slotOn(1, 3, StatusMaintenance), slotOn(2, 5, StatusBlocked)}, 3},
+		{"available slots don't count", []*AvailabilitySlot{slotOn(0, 4, StatusBooked), slotOn(0, 4, StatusAvailable)}, 1},
+		{"outside the range", []*AvailabilitySlot{slotOn(-3, 0, StatusBooked), slotOn(5, 8, StatusBooked)}, 0},
+	}
+	for _, tt := range tests {
+		if got := PeakReservations(tt.slots, day(0), day(5)); got != tt.want {
+			t.Errorf("%s: PeakReservations = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFits(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	tests := []struct {
+		name     string
+		slots    []*AvailabilitySlot
+		capacity int
+		unitID   *uuid.UUID
+		want     bool
+	}{
+		{"single item, free", []*AvailabilitySlot{slotOn(5, 7, StatusBooked)}, 1, nil, true},
+		{"single item, taken", []*AvailabilitySlot{slotOn(1, 3, StatusBooked)}, 1, nil, false},
+		{"single item, ends as it starts", []*AvailabilitySlot{slotOn(-2, 0, StatusBooked)}, 1, nil, true},
+		{"two units, one taken", []*AvailabilitySlot{slotOn(1, 3, StatusBooked)}, 2, nil, true},
+		{"two units, taken in turn", []*AvailabilitySlot{slotOn(0, 2, StatusBooked), slotOn(2, 4, StatusBooked)}, 2, nil, true},
+		{"two units, both taken", []*AvailabilitySlot{slotOn(0, 3, StatusBooked), slotOn(2, 4, StatusMaintenance)}, 2, nil, false},
+		{"unit free", []*AvailabilitySlot{unitSlot(0, 4, b)}, 2, &a, true},
+		{"unit busy", []*AvailabilitySlot{unitSlot(1, 2, a)}, 2, &a, false},
+		{"unit free but no capacity left", []*AvailabilitySlot{unitSlot(0, 4, b), slotOn(0, 4, StatusBooked)}, 2, &a, false},
+	}
+	for _, tt := range tests {
+		if got := Fits(tt.slots, day(0), day(4), tt.capacity, tt.unitID); got != tt.want {
+			t.Errorf("%s: Fits = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFullyReserved(t *testing.T) {
+	tests := []struct {
+		name     string
+		slots    []*AvailabilitySlot
+		capacity int
+		want     []ReservedSpan
+	}{
+		{"single item", []*AvailabilitySlot{slotOn(0, 2, StatusBooked), slotOn(4, 5, StatusBlocked)}, 1,
+			[]ReservedSpan{{day(0), day(2)}, {day(4), day(5)}}},
+		{"back to back spans merge", []*AvailabilitySlot{slotOn(0, 2, StatusBooked), slotOn(2, 4, StatusBooked)}, 1,
+			[]ReservedSpan{{day(0), day(4)}}},
+		{"only where both units are taken", []*AvailabilitySlot{slotOn(0, 4, StatusBooked), slotOn(2, 6, StatusBooked)}, 2,
+			[]ReservedSpan{{day(2), day(4)}}},
+		{"taken in turn is never full", []*AvailabilitySlot{slotOn(0, 2, StatusBooked), slotOn(2, 4, StatusBooked)}, 2, nil},
+		{"available slots don't count", []*AvailabilitySlot{slotOn(0, 2, StatusAvailable)}, 1, nil},
+	}
+	for _, tt := range tests {
+		got := FullyReserved(tt.slots, tt.capacity)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: FullyReserved = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+				t.Errorf("%s: FullyReserved = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFreeUnit(t *testing.T) {
+	units := []ItemUnit{{ID: uuid.New(), Label: "Unit 1"}, {ID: uuid.New(), Label: "Unit 2"}}
+
+	unit, err := FreeUnit(units, []*AvailabilitySlot{unitSlot(1, 3, units[0].ID)}, day(0), day(4))
+	if err != nil || unit.ID != units[1].ID {
+		t.Errorf("FreeUnit = %v, %v, want Unit 2", unit, err)
+	}
+
+	busy := []*AvailabilitySlot{unitSlot(1, 3, units[0].ID), unitSlot(3, 5, units[1].ID)}
+	if _, err := FreeUnit(units, busy, day(0), day(4)); err != ErrNoFreeUnit {
+		t.Errorf("FreeUnit with every unit busy = %v, want ErrNoFreeUnit", err)
+	}
+}
@@ -1,24 +1,52 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/bulk"
+	"github.com/rentalflow/inventory-service/internal/clients"
 	"github.com/rentalflow/inventory-service/internal/domain"
 	"github.com/rentalflow/inventory-service/internal/repository"
 	"github.com/rentalflow/inventory-service/internal/service"
+	"github.com/rentalflow/rentalflow/pkg/media"
 )
 
 // HTTPHandler provides REST endpoints for testing
 type HTTPHandler struct {
 	inventoryService *service.InventoryService
+	calendarService  *service.CalendarService
+	schemaService    *service.SchemaService
+	categoryService  *service.CategoryService
+	bulkService      *service.BulkService
+	planService      *service.MaintenancePlanService
 }
 
 // NewHTTPHandler creates a new HTTP handler
-func NewHTTPHandler(inventoryService *service.InventoryService) *HTTPHandler {
-	return &HTTPHandler{inventoryService: inventoryService}
+func NewHTTPHandler(
+	inventoryService *service.InventoryService,
+	calendarService *service.CalendarService,
+	schemaService *service.SchemaService,
+	categoryService *service.CategoryService,
+	bulkService *service.BulkService,
+	planService *service.MaintenancePlanService,
+) *HTTPHandler {
+	return &HTTPHandler{
+		inventoryService: inventoryService,
+		calendarService:  calendarService,
+		schemaService:    schemaService,
+		categoryService:  categoryService,
+		bulkService:      bulkService,
+		planService:      planService,
+	}
 }
 
 // RegisterRoutes registers HTTP routes
@@ -29,8 +57,35 @@ func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/items/owner", h.GetOwnerItems)
 	mux.HandleFunc("/api/items/search", h.SearchItems)
 	mux.HandleFunc("/api/items/featured", h.GetFeaturedItems)
+	mux.HandleFunc("/api/items/imports", h.HandleImports)
+	mux.HandleFunc("/api/items/import-errors", h.GetImportErrors)
+	mux.HandleFunc("/api/items/export", h.ExportItems)
+	mux.HandleFunc("/api/items/schemas", h.HandleSchemas)
+	mux.HandleFunc("/api/items/{id}/availability", h.GetAvailabilityCalendar)
+	mux.HandleFunc("/api/items/{id}/rules", h.HandleRentalRules)
+	mux.HandleFunc("/api/items/{id}/images", h.HandleItemImages)
+	mux.HandleFunc("/api/items/{id}/units", h.HandleItemUnits)
+	mux.HandleFunc("/api/items/{id}/addons", h.HandleItemAddOns)
+	mux.HandleFunc("/api/items/{id}/pricing-rules", h.HandlePricingRules)
+	mux.HandleFunc("/api/items/{id}/price", h.PriceRental)
+	mux.HandleFunc("/api/items/calendar.ics", h.ExportCalendar)
+	mux.HandleFunc("/api/items/calendar/feeds", h.HandleCalendarFeeds)
+	mux.HandleFunc("/api/items/calendar/feeds/sync", h.SyncCalendarFeed)
+	mux.HandleFunc("/api/categories", h.HandleCategories)
+	mux.HandleFunc("/api/categories/{slug}", h.HandleCategory)
+	mux.HandleFunc("/api/categories/{slug}/items", h.GetCategoryItems)
 	mux.HandleFunc("/api/availability/block", h.BlockDates)
-	mux.HandleFunc("/api/maintenance", h.CreateMaintenance)
+	mux.HandleFunc("/api/availability/release", h.ReleaseDates)
+	mux.HandleFunc("/api/availability/confirm", h.ConfirmDates)
+	mux.HandleFunc("/api/availability/check", h.CheckDates)
+	mux.HandleFunc("/api/availability/reschedule", h.RescheduleDates)
+	mux.HandleFunc("/api/availability/validate", h.ValidateBooking)
+	mux.HandleFunc("/api/availability/assign", h.AssignUnit)
+	mux.HandleFunc("/api/maintenance", h.HandleMaintenance)
+	mux.HandleFunc("/api/maintenance/costs", h.GetMaintenanceCosts)
+	mux.HandleFunc("/api/maintenance/plans", h.HandleMaintenancePlans)
+	mux.HandleFunc("/api/maintenance/{id}/start", h.StartMaintenance)
+	mux.HandleFunc("/api/maintenance/{id}/complete", h.CompleteMaintenance)
 }
 
 func (h *HTTPHandler) Health(w http.ResponseWriter, r *http.Request) {
@@ -155,6 +210,10 @@ func (h *HTTPHandler) GetItem(w http.ResponseWriter, r *http.Request, itemID str
 		"address":          item.Address,
 		"specifications":   item.Specifications,
 		"images":           item.Images,
+		"media":            item.Media,
+		"rules":            item.Rules,
+		"capacity":         item.UnitCapacity(),
+		"add_ons":          item.ActiveAddOns(),
 		"is_active":        item.IsActive,
 		"created_at":       item.CreatedAt,
 	})
@@ -170,11 +229,66 @@ func (h *HTTPHandler) ListItems(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
-	category := r.URL.Query().Get("category")
-	city := r.URL.Query().Get("city")
-	minPriceStr := r.URL.Query().Get("min_price")
-	maxPriceStr := r.URL.Query().Get("max_price")
-	sort := r.URL.Query().Get("sort")
+	filters, err := parseItemFilters(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	items, total, err := h.inventoryService.ListItems(r.Context(), page, pageSize, filters)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	result := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		result[i] = itemSummary(item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items": result,
+		"total": total,
+		"page":  page,
+	})
+}
+
+// itemSummary is how an item appears in list and search results
+func itemSummary(item *domain.RentalItem) map[string]interface{} {
+	summary := map[string]interface{}{
+		"id":         item.ID.String(),
+		"title":      item.Title,
+		"category":   item.Category,
+		"city":       item.City,
+		"latitude":   item.Latitude,
+		"longitude":  item.Longitude,
+		"daily_rate": item.DailyRate,
+		"is_active":  item.IsActive,
+		"images":     item.Images,
+	}
+	if item.DistanceKm != nil {
+		summary["distance_km"] = *item.DistanceKm
+	}
+	if item.Relevance != nil {
+		summary["relevance"] = *item.Relevance
+	}
+	if item.QuotedPrice != nil {
+		summary["quoted_price"] = *item.QuotedPrice
+	}
+	return summary
+}
+
+// parseItemFilters reads the list and search filters. A location search takes
+// either lat, lng and radius_km, or min_lat, min_lng, max_lat and max_lng.
+// Each attr parameter filters on a specification, as in attr=seats>=5.
+func parseItemFilters(r *http.Request) (repository.ItemFilters, error) {
+	q := r.URL.Query()
+	category := q.Get("category")
+	city := q.Get("city")
+	minPriceStr := q.Get("min_price")
+	maxPriceStr := q.Get("max_price")
+	sort := q.Get("sort")
 
 	filters := repository.ItemFilters{}
 	if category != "" {
@@ -198,31 +312,61 @@ func (h *HTTPHandler) ListItems(w http.ResponseWriter, r *http.Request) {
 		filters.SortBy = &sort
 	}
 
-	items, total, err := h.inventoryService.ListItems(r.Context(), page, pageSize, filters)
-	if err != nil {
-		h.handleError(w, err)
-		return
+	if q.Get("lat") != "" || q.Get("lng") != "" || q.Get("radius_km") != "" {
+		values, err := parseFloats(q, "lat", "lng", "radius_km")
+		if err != nil {
+			return filters, err
+		}
+		filters.Near = &domain.GeoRadius{Latitude: values[0], Longitude: values[1], RadiusKm: values[2]}
 	}
-
-	result := make([]map[string]interface{}, len(items))
-	for i, item := range items {
-		result[i] = map[string]interface{}{
-			"id":         item.ID.String(),
-			"title":      item.Title,
-			"category":   item.Category,
-			"city":       item.City,
-			"daily_rate": item.DailyRate,
-			"is_active":  item.IsActive,
-			"images":     item.Images,
+	if q.Get("min_lat") != "" || q.Get("min_lng") != "" || q.Get("max_lat") != "" || q.Get("max_lng") != "" {
+		values, err := parseFloats(q, "min_lat", "min_lng", "max_lat", "max_lng")
+		if err != nil {
+			return filters, err
+		}
+		filters.Within = &domain.BoundingBox{
+			MinLatitude:  values[0],
+			MinLongitude: values[1],
+			MaxLatitude:  values[2],
+			MaxLongitude: values[3],
+		}
+	}
+	if from := q.Get("available_from"); from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return filters, domain.ErrInvalidDateRange
+		}
+		filters.AvailableFrom = &t
+	}
+	if to := q.Get("available_to"); to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return filters, domain.ErrInvalidDateRange
 		}
+		filters.AvailableTo = &t
+	}
+	for _, expr := range q["attr"] {
+		filter, err := domain.ParseAttributeFilter(expr)
+		if err != nil {
+			return filters, err
+		}
+		filters.Attributes = append(filters.Attributes, filter)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"items": result,
-		"total": total,
-		"page":  page,
-	})
+	return filters, nil
+}
+
+// parseFloats reads query parameters that must all be present together
+func parseFloats(q url.Values, keys ...string) ([]float64, error) {
+	values := make([]float64, len(keys))
+	for i, key := range keys {
+		v, err := strconv.ParseFloat(q.Get(key), 64)
+		if err != nil {
+			return nil, domain.ErrInvalidLocation
+		}
+		values[i] = v
+	}
+	return values, nil
 }
 
 func (h *HTTPHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
@@ -368,80 +512,104 @@ func (h *HTTPHandler) SearchItems(w http.ResponseWriter, r *http.Request) {
 		pageSize = 20
 	}
 
-	category := r.URL.Query().Get("category")
-	city := r.URL.Query().Get("city")
-	minPriceStr := r.URL.Query().Get("min_price")
-	maxPriceStr := r.URL.Query().Get("max_price")
-	sort := r.URL.Query().Get("sort")
+	filters, err := parseItemFilters(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
 
-	filters := repository.ItemFilters{}
-	if category != "" {
-		cat := domain.ItemCategory(category)
-		filters.Category = &cat
+	found, err := h.inventoryService.SearchItems(r.Context(), query, page, pageSize, filters)
+	if err != nil {
+		h.handleError(w, err)
+		return
 	}
-	if city != "" {
-		filters.City = &city
+
+	result := make([]map[string]interface{}, len(found.Items))
+	for i, item := range found.Items {
+		result[i] = itemSummary(item)
 	}
-	if minPriceStr != "" {
-		if val, err := strconv.ParseFloat(minPriceStr, 64); err == nil {
-			filters.MinPrice = &val
-		}
+
+	response := map[string]interface{}{
+		"items":  result,
+		"total":  found.Total,
+		"page":   page,
+		"facets": found.Facets,
 	}
-	if maxPriceStr != "" {
-		if val, err := strconv.ParseFloat(maxPriceStr, 64); err == nil {
-			filters.MaxPrice = &val
-		}
+	if found.CorrectedQuery != "" {
+		response["corrected_query"] = found.CorrectedQuery
 	}
-	if sort != "" {
-		filters.SortBy = &sort
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *HTTPHandler) BlockDates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	var items []*domain.RentalItem
-	var total int
-	var err error
+	var req struct {
+		ItemID    string               `json:"item_id"`
+		StartDate string               `json:"start_date"`
+		EndDate   string               `json:"end_date"`
+		BookingID string               `json:"booking_id"`
+		AddOns    []domain.BookedAddOn `json:"add_ons"`
+	}
 
-	if query != "" {
-		items, total, err = h.inventoryService.SearchItems(r.Context(), query, page, pageSize, filters)
-	} else {
-		items, total, err = h.inventoryService.ListItems(r.Context(), page, pageSize, filters)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
+	itemID, err := uuid.Parse(req.ItemID)
 	if err != nil {
-		h.handleError(w, err)
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
 		return
 	}
 
-	result := make([]map[string]interface{}, len(items))
-	for i, item := range items {
-		result[i] = map[string]interface{}{
-			"id":         item.ID.String(),
-			"title":      item.Title,
-			"category":   item.Category,
-			"city":       item.City,
-			"daily_rate": item.DailyRate,
-			"is_active":  item.IsActive,
-			"images":     item.Images,
-		}
+	slot, err := h.inventoryService.BlockDates(r.Context(), itemID, startDate, endDate, bookingID, req.AddOns)
+	if err != nil {
+		h.handleError(w, err)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"items": result,
-		"total": total,
-		"page":  page,
+		"success":    true,
+		"slot_id":    slot.ID.String(),
+		"item_id":    slot.RentalItemID.String(),
+		"booking_id": req.BookingID,
+		"start_date": slot.StartDate,
+		"end_date":   slot.EndDate,
+		"status":     slot.Status,
+		"add_ons":    slot.AddOns,
 	})
 }
 
-func (h *HTTPHandler) BlockDates(w http.ResponseWriter, r *http.Request) {
+func (h *HTTPHandler) ReleaseDates(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req struct {
-		ItemID    string `json:"item_id"`
-		StartDate string `json:"start_date"`
-		EndDate   string `json:"end_date"`
 		BookingID string `json:"booking_id"`
 	}
 
@@ -450,30 +618,2023 @@ func (h *HTTPHandler) BlockDates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.inventoryService.ReleaseDates(r.Context(), bookingID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]bool{"success": true})
 }
 
-func (h *HTTPHandler) CreateMaintenance(w http.ResponseWriter, r *http.Request) {
+// ConfirmDates marks a booking's dates confirmed once the owner accepts it
+func (h *HTTPHandler) ConfirmDates(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	var req struct {
+		BookingID string `json:"booking_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.inventoryService.ConfirmDates(r.Context(), bookingID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]bool{"success": true})
 }
 
-func (h *HTTPHandler) handleError(w http.ResponseWriter, err error) {
+func (h *HTTPHandler) CheckDates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ItemID    string `json:"item_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		BookingID string `json:"booking_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := uuid.Parse(req.ItemID)
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
+		return
+	}
+
+	// booking_id is optional; its own dates are not a conflict
+	var bookingID *uuid.UUID
+	if req.BookingID != "" {
+		id, err := uuid.Parse(req.BookingID)
+		if err != nil {
+			http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+			return
+		}
+		bookingID = &id
+	}
+
+	available, err := h.inventoryService.CheckDates(r.Context(), itemID, startDate, endDate, bookingID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id":    itemID.String(),
+		"start_date": startDate,
+		"end_date":   endDate,
+		"available":  available,
+	})
+}
+
+func (h *HTTPHandler) RescheduleDates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		BookingID string `json:"booking_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
+		return
+	}
+
+	slot, err := h.inventoryService.RescheduleDates(r.Context(), bookingID, startDate, endDate)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"slot_id":    slot.ID.String(),
+		"item_id":    slot.RentalItemID.String(),
+		"booking_id": req.BookingID,
+		"start_date": slot.StartDate,
+		"end_date":   slot.EndDate,
+		"status":     slot.Status,
+	})
+}
+
+// AssignUnit assigns a booking the unit handed over at pickup. unit_id is
+// optional; without it the first free unit is picked. Items without units
+// answer with no unit_id.
+func (h *HTTPHandler) AssignUnit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		BookingID string `json:"booking_id"`
+		UnitID    string `json:"unit_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+	var unitID *uuid.UUID
+	if req.UnitID != "" {
+		id, err := uuid.Parse(req.UnitID)
+		if err != nil {
+			http.Error(w, "Invalid unit_id", http.StatusBadRequest)
+			return
+		}
+		unitID = &id
+	}
+
+	slot, err := h.inventoryService.AssignUnit(r.Context(), bookingID, unitID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":    true,
+		"slot_id":    slot.ID.String(),
+		"item_id":    slot.RentalItemID.String(),
+		"booking_id": req.BookingID,
+	}
+	if slot.UnitID != nil {
+		response["unit_id"] = slot.UnitID.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ValidateBooking checks a rental against the item's rules and reservations.
+// A rental that breaks them is not an error: the response lists the reasons.
+func (h *HTTPHandler) ValidateBooking(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ItemID    string `json:"item_id"`
+		StartDate string `json:"start_date"`
+		EndDate   string `json:"end_date"`
+		BookingID string `json:"booking_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := uuid.Parse(req.ItemID)
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
+		return
+	}
+
+	var bookingID *uuid.UUID
+	if req.BookingID != "" {
+		id, err := uuid.Parse(req.BookingID)
+		if err != nil {
+			http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+			return
+		}
+		bookingID = &id
+	}
+
+	validation, err := h.inventoryService.ValidateBooking(r.Context(), itemID, startDate, endDate, bookingID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(validation)
+}
+
+// parseDate accepts either a full RFC 3339 timestamp or a plain YYYY-MM-DD date
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// calendarDefaultDays is how far ahead the availability calendar looks when
+// no end date is given
+const calendarDefaultDays = 90
+
+// GetAvailabilityCalendar serves the item's free/busy calendar for a date
+// picker. from defaults to today and to, which is exclusive, to 90 days later.
+func (h *HTTPHandler) GetAvailabilityCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	from := time.Now()
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = parseDate(v); err != nil {
+			http.Error(w, "Invalid from", http.StatusBadRequest)
+			return
+		}
+	}
+	to := from.AddDate(0, 0, calendarDefaultDays)
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = parseDate(v); err != nil {
+			http.Error(w, "Invalid to", http.StatusBadRequest)
+			return
+		}
+	}
+
+	calendar, err := h.inventoryService.GetAvailabilityCalendar(r.Context(), itemID, from, to)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(calendar)
+}
+
+func (h *HTTPHandler) HandleRentalRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetRentalRules(w, r)
+	case http.MethodPut:
+		h.SetRentalRules(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) GetRentalRules(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.GetItem(r.Context(), itemID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": item.ID.String(),
+		"rules":   item.Rules,
+	})
+}
+
+func (h *HTTPHandler) SetRentalRules(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		OwnerID string             `json:"owner_id"`
+		Rules   domain.RentalRules `json:"rules"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.SetRentalRules(r.Context(), itemID, ownerID, req.Rules)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": item.ID.String(),
+		"rules":   item.Rules,
+	})
+}
+
+func (h *HTTPHandler) HandleItemImages(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.UploadItemImage(w, r)
+	case http.MethodDelete:
+		h.RemoveItemImage(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// UploadItemImage takes a multipart form with the owner_id and an "image" file
+func (h *HTTPHandler) UploadItemImage(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	file, err := media.ReadFile(w, r, "image", media.ItemImagePolicy.MaxBytes)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	ownerID, err := uuid.Parse(r.FormValue("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	item, asset, err := h.inventoryService.AddItemImage(r.Context(), itemID, ownerID, file)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": item.ID.String(),
+		"image":   asset,
+		"images":  item.Images,
+	})
+}
+
+func (h *HTTPHandler) RemoveItemImage(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	imageURL := r.URL.Query().Get("url")
+	if imageURL == "" {
+		http.Error(w, "url is required", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.RemoveItemImage(r.Context(), itemID, ownerID, imageURL)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": item.ID.String(),
+		"images":  item.Images,
+	})
+}
+
+func (h *HTTPHandler) HandleItemUnits(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListItemUnits(w, r)
+	case http.MethodPost:
+		h.AddItemUnits(w, r)
+	case http.MethodPut:
+		h.UpdateItemUnit(w, r)
+	case http.MethodDelete:
+		h.RemoveItemUnit(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListItemUnits lists the units of one of the owner's items
+func (h *HTTPHandler) ListItemUnits(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.GetItem(r.Context(), itemID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if item.OwnerID != ownerID {
+		h.handleError(w, domain.ErrUnauthorized)
+		return
+	}
+
+	writeUnits(w, http.StatusOK, item, nil)
+}
+
+// AddItemUnits adds count units, one by default. A serial number can only be
+// given when adding a single unit.
+func (h *HTTPHandler) AddItemUnits(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		OwnerID      string `json:"owner_id"`
+		Count        int    `json:"count"`
+		Label        string `json:"label"`
+		SerialNumber string `json:"serial_number"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+
+	item, added, err := h.inventoryService.AddUnits(r.Context(), itemID, ownerID, req.Count, req.Label, req.SerialNumber)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	writeUnits(w, http.StatusCreated, item, added)
+}
+
+func (h *HTTPHandler) UpdateItemUnit(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	unitID, err := uuid.Parse(r.URL.Query().Get("unit_id"))
+	if err != nil {
+		http.Error(w, "Invalid unit_id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		OwnerID      string `json:"owner_id"`
+		Label        string `json:"label"`
+		SerialNumber string `json:"serial_number"`
+		IsActive     bool   `json:"is_active"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	unit, err := h.inventoryService.UpdateUnit(r.Context(), itemID, ownerID, unitID, req.Label, req.SerialNumber, req.IsActive)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(unit)
+}
+
+func (h *HTTPHandler) RemoveItemUnit(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	unitID, err := uuid.Parse(r.URL.Query().Get("unit_id"))
+	if err != nil {
+		http.Error(w, "Invalid unit_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.RemoveUnit(r.Context(), itemID, ownerID, unitID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	writeUnits(w, http.StatusOK, item, nil)
+}
+
+// writeUnits responds with the item's units and capacity, and the units just
+// added if any
+func writeUnits(w http.ResponseWriter, status int, item *domain.RentalItem, added []domain.ItemUnit) {
+	units := item.Units
+	if units == nil {
+		units = []domain.ItemUnit{}
+	}
+	response := map[string]interface{}{
+		"item_id":  item.ID.String(),
+		"capacity": item.UnitCapacity(),
+		"units":    units,
+	}
+	if added != nil {
+		response["added"] = added
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *HTTPHandler) HandleItemAddOns(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListItemAddOns(w, r)
+	case http.MethodPost:
+		h.CreateItemAddOn(w, r)
+	case http.MethodPut:
+		h.UpdateItemAddOn(w, r)
+	case http.MethodDelete:
+		h.RemoveItemAddOn(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListItemAddOns lists the add-ons renters can choose, or all of them,
+// including those switched off, for the item's owner
+func (h *HTTPHandler) ListItemAddOns(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.GetItem(r.Context(), itemID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	addOns := item.ActiveAddOns()
+	if raw := r.URL.Query().Get("owner_id"); raw != "" {
+		ownerID, err := uuid.Parse(raw)
+		if err != nil {
+			http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+			return
+		}
+		if item.OwnerID != ownerID {
+			h.handleError(w, domain.ErrUnauthorized)
+			return
+		}
+		addOns = append([]domain.AddOn{}, item.AddOns...)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": item.ID.String(),
+		"add_ons": addOns,
+	})
+}
+
+// AddOnRequest is the body for creating or updating an add-on
+type AddOnRequest struct {
+	OwnerID string `json:"owner_id"`
+	domain.AddOnSettings
+}
+
+func (h *HTTPHandler) CreateItemAddOn(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	// Add-ons are offered unless the request says otherwise
+	var req AddOnRequest
+	req.IsActive = true
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	addOn, err := h.inventoryService.AddAddOn(r.Context(), itemID, ownerID, req.AddOnSettings)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(addOn)
+}
+
+func (h *HTTPHandler) UpdateItemAddOn(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	addOnID, err := uuid.Parse(r.URL.Query().Get("add_on_id"))
+	if err != nil {
+		http.Error(w, "Invalid add_on_id", http.StatusBadRequest)
+		return
+	}
+
+	var req AddOnRequest
+	req.IsActive = true
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	addOn, err := h.inventoryService.UpdateAddOn(r.Context(), itemID, ownerID, addOnID, req.AddOnSettings)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(addOn)
+}
+
+func (h *HTTPHandler) RemoveItemAddOn(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	addOnID, err := uuid.Parse(r.URL.Query().Get("add_on_id"))
+	if err != nil {
+		http.Error(w, "Invalid add_on_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.RemoveAddOn(r.Context(), itemID, ownerID, addOnID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	addOns := item.AddOns
+	if addOns == nil {
+		addOns = []domain.AddOn{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": item.ID.String(),
+		"add_ons": addOns,
+	})
+}
+
+func (h *HTTPHandler) HandlePricingRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListPricingRules(w, r)
+	case http.MethodPost:
+		h.CreatePricingRule(w, r)
+	case http.MethodPut:
+		h.UpdatePricingRule(w, r)
+	case http.MethodDelete:
+		h.RemovePricingRule(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) ListPricingRules(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.ListPricingRules(r.Context(), itemID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.writePricingRules(w, item)
+}
+
+// PricingRuleRequest is the body for creating or updating a pricing rule
+type PricingRuleRequest struct {
+	OwnerID string `json:"owner_id"`
+	domain.PricingRuleSettings
+}
+
+func (h *HTTPHandler) CreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	// Rules apply unless the request says otherwise
+	var req PricingRuleRequest
+	req.IsActive = true
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.inventoryService.AddPricingRule(r.Context(), itemID, ownerID, req.PricingRuleSettings)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *HTTPHandler) UpdatePricingRule(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ruleID, err := uuid.Parse(r.URL.Query().Get("rule_id"))
+	if err != nil {
+		http.Error(w, "Invalid rule_id", http.StatusBadRequest)
+		return
+	}
+
+	var req PricingRuleRequest
+	req.IsActive = true
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.inventoryService.UpdatePricingRule(r.Context(), itemID, ownerID, ruleID, req.PricingRuleSettings)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *HTTPHandler) RemovePricingRule(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ruleID, err := uuid.Parse(r.URL.Query().Get("rule_id"))
+	if err != nil {
+		http.Error(w, "Invalid rule_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	item, err := h.inventoryService.RemovePricingRule(r.Context(), itemID, ownerID, ruleID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	h.writePricingRules(w, item)
+}
+
+func (h *HTTPHandler) writePricingRules(w http.ResponseWriter, item *domain.RentalItem) {
+	rules := item.PricingRules
+	if rules == nil {
+		rules = []domain.PricingRule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id":       item.ID.String(),
+		"pricing_rules": rules,
+	})
+}
+
+// PriceRental prices renting the item from start_date to end_date, day by day
+// under its rates and pricing rules
+func (h *HTTPHandler) PriceRental(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(r.URL.Query().Get("start_date"))
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(r.URL.Query().Get("end_date"))
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
+		return
+	}
+
+	price, err := h.inventoryService.PriceRental(r.Context(), itemID, startDate, endDate)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(price)
+}
+
+// ExportCalendar serves the item's busy dates as an iCalendar feed that other
+// platforms and calendar apps can subscribe to
+func (h *HTTPHandler) ExportCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+
+	data, err := h.calendarService.ExportCalendar(r.Context(), itemID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="`+itemID.String()+`.ics"`)
+	w.Write(data)
+}
+
+func (h *HTTPHandler) HandleCalendarFeeds(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.AddCalendarFeed(w, r)
+	case http.MethodGet:
+		h.ListCalendarFeeds(w, r)
+	case http.MethodDelete:
+		h.RemoveCalendarFeed(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) AddCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ItemID  string `json:"item_id"`
+		OwnerID string `json:"owner_id"`
+		Name    string `json:"name"`
+		URL     string `json:"url"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := uuid.Parse(req.ItemID)
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	feed, err := h.calendarService.AddFeed(r.Context(), itemID, ownerID, req.Name, req.URL)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(feed)
+}
+
+func (h *HTTPHandler) ListCalendarFeeds(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.URL.Query().Get("item_id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	feeds, err := h.calendarService.ListFeeds(r.Context(), itemID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id": itemID.String(),
+		"feeds":   feeds,
+	})
+}
+
+func (h *HTTPHandler) RemoveCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	feedID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid feed_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.calendarService.RemoveFeed(r.Context(), feedID, ownerID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+func (h *HTTPHandler) SyncCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		FeedID  string `json:"feed_id"`
+		OwnerID string `json:"owner_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	feedID, err := uuid.Parse(req.FeedID)
+	if err != nil {
+		http.Error(w, "Invalid feed_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	feed, err := h.calendarService.SyncFeed(r.Context(), feedID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feed)
+}
+
+// SaveSchemaRequest defines the specifications for a category, or a
+// subcategory if one is given
+type SaveSchemaRequest struct {
+	AdminID     string                 `json:"admin_id"`
+	Category    string                 `json:"category"`
+	Subcategory string                 `json:"subcategory"`
+	Attributes  []domain.AttributeSpec `json:"attributes"`
+}
+
+// HandleSchemas lists the specification schemas for anyone, and lets admins
+// save or delete them
+func (h *HTTPHandler) HandleSchemas(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.ListSchemas(w, r)
+	case http.MethodPut:
+		h.SaveSchema(w, r)
+	case http.MethodDelete:
+		h.DeleteSchema(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
+	var category *domain.ItemCategory
+	if c := r.URL.Query().Get("category"); c != "" {
+		cat := domain.ItemCategory(c)
+		category = &cat
+	}
+
+	schemas, err := h.schemaService.ListSchemas(r.Context(), category)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"schemas": schemas})
+}
+
+func (h *HTTPHandler) SaveSchema(w http.ResponseWriter, r *http.Request) {
+	var req SaveSchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	adminID, err := uuid.Parse(req.AdminID)
+	if err != nil {
+		http.Error(w, "Invalid admin_id", http.StatusBadRequest)
+		return
+	}
+
+	schema, err := h.schemaService.SaveSchema(r.Context(), adminID, domain.ItemCategory(req.Category), req.Subcategory, req.Attributes)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schema)
+}
+
+func (h *HTTPHandler) DeleteSchema(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	adminID, err := uuid.Parse(q.Get("admin_id"))
+	if err != nil {
+		http.Error(w, "Invalid admin_id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.schemaService.DeleteSchema(r.Context(), adminID, domain.ItemCategory(q.Get("category")), q.Get("subcategory"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// CreateCategoryRequest adds a category under Parent, or a root if it is empty
+type CreateCategoryRequest struct {
+	AdminID   string `json:"admin_id"`
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	Parent    string `json:"parent"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// HandleCategories serves the category tree and lets admins add to it.
+// include_inactive=true also lists deactivated branches.
+func (h *HTTPHandler) HandleCategories(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		includeInactive := r.URL.Query().Get("include_inactive") == "true"
+		categories, err := h.categoryService.ListCategories(r.Context(), includeInactive)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"categories": categories})
+	case http.MethodPost:
+		h.CreateCategory(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	adminID, err := uuid.Parse(req.AdminID)
+	if err != nil {
+		http.Error(w, "Invalid admin_id", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(r.Context(), adminID, req.Slug, req.Name, req.Parent, req.SortOrder)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(category)
+}
+
+// HandleCategory reads one category, or lets admins update or delete it. An
+// update takes admin_id with any of name, parent, is_active and sort_order.
+func (h *HTTPHandler) HandleCategory(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+
+	switch r.Method {
+	case http.MethodGet:
+		category, err := h.categoryService.GetActiveCategory(r.Context(), slug)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(category)
+	case http.MethodPut:
+		var updates map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		adminIDStr, _ := updates["admin_id"].(string)
+		adminID, err := uuid.Parse(adminIDStr)
+		if err != nil {
+			http.Error(w, "Invalid admin_id", http.StatusBadRequest)
+			return
+		}
+		category, err := h.categoryService.UpdateCategory(r.Context(), adminID, slug, updates)
+		if err != nil {
+			h.handleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(category)
+	case http.MethodDelete:
+		adminID, err := uuid.Parse(r.URL.Query().Get("admin_id"))
+		if err != nil {
+			http.Error(w, "Invalid admin_id", http.StatusBadRequest)
+			return
+		}
+		if err := h.categoryService.DeleteCategory(r.Context(), adminID, slug); err != nil {
+			h.handleError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]bool{"success": true})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetCategoryItems lists the items in a category and its descendants, taking
+// the same filters as the item list
+func (h *HTTPHandler) GetCategoryItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	category, err := h.categoryService.GetActiveCategory(r.Context(), r.PathValue("slug"))
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	filters, err := parseItemFilters(r)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	slug := domain.ItemCategory(category.Slug)
+	filters.Category = &slug
+
+	items, total, err := h.inventoryService.ListItems(r.Context(), page, pageSize, filters)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	result := make([]map[string]interface{}, len(items))
+	for i, item := range items {
+		result[i] = itemSummary(item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"category": category,
+		"items":    result,
+		"total":    total,
+		"page":     page,
+	})
+}
+
+func (h *HTTPHandler) HandleMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.CreateMaintenance(w, r)
+	case http.MethodGet:
+		if r.URL.Query().Get("id") != "" {
+			h.GetMaintenance(w, r)
+		} else {
+			h.ListMaintenance(w, r)
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// CreateMaintenance schedules maintenance, blocking the item's dates until
+// it is completed
+func (h *HTTPHandler) CreateMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ItemID          string  `json:"item_id"`
+		OwnerID         string  `json:"owner_id"`
+		MaintenanceType string  `json:"maintenance_type"`
+		Description     string  `json:"description"`
+		UnitID          string  `json:"unit_id"`
+		StartDate       string  `json:"start_date"`
+		EndDate         string  `json:"end_date"`
+		Cost            float64 `json:"cost"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := uuid.Parse(req.ItemID)
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+	startDate, err := parseDate(req.StartDate)
+	if err != nil {
+		http.Error(w, "Invalid start_date", http.StatusBadRequest)
+		return
+	}
+	endDate, err := parseDate(req.EndDate)
+	if err != nil {
+		http.Error(w, "Invalid end_date", http.StatusBadRequest)
+		return
+	}
+
+	// unit_id is optional; it picks the unit of an item with several
+	var unitID *uuid.UUID
+	if req.UnitID != "" {
+		id, err := uuid.Parse(req.UnitID)
+		if err != nil {
+			http.Error(w, "Invalid unit_id", http.StatusBadRequest)
+			return
+		}
+		unitID = &id
+	}
+
+	log, err := h.inventoryService.CreateMaintenanceLog(r.Context(), itemID, ownerID, unitID,
+		req.MaintenanceType, req.Description, startDate, endDate, req.Cost)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(log)
+}
+
+func (h *HTTPHandler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	logID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid maintenance id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	log, err := h.inventoryService.GetMaintenanceLog(r.Context(), logID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(log)
+}
+
+// ListMaintenance lists an item's maintenance, or one unit's with unit_id,
+// newest first
+func (h *HTTPHandler) ListMaintenance(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.URL.Query().Get("item_id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	var unitID *uuid.UUID
+	if raw := r.URL.Query().Get("unit_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			http.Error(w, "Invalid unit_id", http.StatusBadRequest)
+			return
+		}
+		unitID = &id
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+
+	logs, total, err := h.inventoryService.ListMaintenanceLogs(r.Context(), itemID, ownerID, unitID, page, pageSize)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if logs == nil {
+		logs = []*domain.MaintenanceLog{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"maintenance": logs,
+		"total":       total,
+	})
+}
+
+// StartMaintenance marks maintenance as under way
+func (h *HTTPHandler) StartMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	logID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid maintenance id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		OwnerID string `json:"owner_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	log, err := h.inventoryService.StartMaintenance(r.Context(), logID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(log)
+}
+
+// CompleteMaintenance marks maintenance done and frees the dates it blocked.
+// cost, if given, replaces the estimate with the final cost.
+func (h *HTTPHandler) CompleteMaintenance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	logID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid maintenance id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		OwnerID string   `json:"owner_id"`
+		Cost    *float64 `json:"cost"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	log, err := h.inventoryService.CompleteMaintenance(r.Context(), logID, ownerID, req.Cost)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(log)
+}
+
+// GetMaintenanceCosts totals maintenance costs for one item, or for all of
+// the owner's items when no item_id is given
+func (h *HTTPHandler) GetMaintenanceCosts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	var itemID *uuid.UUID
+	if raw := r.URL.Query().Get("item_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			http.Error(w, "Invalid item_id", http.StatusBadRequest)
+			return
+		}
+		itemID = &id
+	}
+
+	costs, err := h.inventoryService.GetMaintenanceCosts(r.Context(), ownerID, itemID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(costs)
+}
+
+func (h *HTTPHandler) HandleMaintenancePlans(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.CreateMaintenancePlan(w, r)
+	case http.MethodGet:
+		h.ListMaintenancePlans(w, r)
+	case http.MethodPut:
+		h.UpdateMaintenancePlan(w, r)
+	case http.MethodDelete:
+		h.DeleteMaintenancePlan(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// MaintenancePlanRequest is the body for creating or updating a maintenance
+// plan; item_id and last_serviced_at are only read on creation
+type MaintenancePlanRequest struct {
+	ItemID         string `json:"item_id"`
+	OwnerID        string `json:"owner_id"`
+	LastServicedAt string `json:"last_serviced_at"`
+	domain.MaintenancePlanSettings
+}
+
+func (h *HTTPHandler) CreateMaintenancePlan(w http.ResponseWriter, r *http.Request) {
+	// Plans are active unless the request says otherwise
+	var req MaintenancePlanRequest
+	req.IsActive = true
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := uuid.Parse(req.ItemID)
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	var lastServicedAt *time.Time
+	if req.LastServicedAt != "" {
+		t, err := parseDate(req.LastServicedAt)
+		if err != nil {
+			http.Error(w, "Invalid last_serviced_at", http.StatusBadRequest)
+			return
+		}
+		lastServicedAt = &t
+	}
+
+	plan, err := h.planService.CreatePlan(r.Context(), itemID, ownerID, req.MaintenancePlanSettings, lastServicedAt)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(plan)
+}
+
+// ListMaintenancePlans lists an item's plans with how far each is from
+// falling due
+func (h *HTTPHandler) ListMaintenancePlans(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.URL.Query().Get("item_id"))
+	if err != nil {
+		http.Error(w, "Invalid item_id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	plans, err := h.planService.ListPlans(r.Context(), itemID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"plans": plans,
+	})
+}
+
+func (h *HTTPHandler) UpdateMaintenancePlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid plan id", http.StatusBadRequest)
+		return
+	}
+
+	// Plans are active unless the request says otherwise
+	var req MaintenancePlanRequest
+	req.IsActive = true
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(req.OwnerID)
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	plan, err := h.planService.UpdatePlan(r.Context(), planID, ownerID, req.MaintenancePlanSettings)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+func (h *HTTPHandler) DeleteMaintenancePlan(w http.ResponseWriter, r *http.Request) {
+	planID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid plan id", http.StatusBadRequest)
+		return
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.planService.DeletePlan(r.Context(), planID, ownerID); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+func (h *HTTPHandler) HandleImports(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.StartImport(w, r)
+	case http.MethodGet:
+		if r.URL.Query().Get("id") != "" {
+			h.GetImport(w, r)
+		} else {
+			h.ListImports(w, r)
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// StartImport queues a CSV or NDJSON file of items, sent either as the
+// request body or as the "file" field of a multipart form. The format comes
+// from the format parameter or the body's content type.
+func (h *HTTPHandler) StartImport(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	format := domain.ImportFormat(r.URL.Query().Get("format"))
+	var body io.Reader
+	if media.IsMultipart(r) {
+		file, err := media.ReadFile(w, r, "file", domain.MaxImportBytes)
+		if err != nil {
+			if err == media.ErrTooLarge {
+				err = domain.ErrImportTooLarge
+			}
+			h.handleError(w, err)
+			return
+		}
+		if format == "" {
+			format = domain.ImportFormat(r.FormValue("format"))
+		}
+		body = file
+	} else {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, domain.MaxImportBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				err = domain.ErrImportTooLarge
+			}
+			h.handleError(w, err)
+			return
+		}
+		if format == "" {
+			format = importFormatOf(r.Header.Get("Content-Type"))
+		}
+		body = bytes.NewReader(data)
+	}
+
+	job, err := h.bulkService.StartImport(r.Context(), ownerID, format, body)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// importFormatOf picks the import format from a content type
+func importFormatOf(contentType string) domain.ImportFormat {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return domain.FormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return domain.FormatNDJSON
+	}
+	return ""
+}
+
+func (h *HTTPHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.ownerImport(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+func (h *HTTPHandler) ListImports(w http.ResponseWriter, r *http.Request) {
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := h.bulkService.ListImports(r.Context(), ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []*domain.ImportJob{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"imports": jobs,
+	})
+}
+
+// GetImportErrors downloads the rows of an import that failed as CSV
+func (h *HTTPHandler) GetImportErrors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	job, ok := h.ownerImport(w, r)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := bulk.WriteErrorReport(&buf, job.FailedResults()); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="import-`+job.ID.String()+`-errors.csv"`)
+	w.Write(buf.Bytes())
+}
+
+// ownerImport loads the import job named by the id and owner_id parameters,
+// writing the error response if it can't
+func (h *HTTPHandler) ownerImport(w http.ResponseWriter, r *http.Request) (*domain.ImportJob, bool) {
+	jobID, err := uuid.Parse(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid import id", http.StatusBadRequest)
+		return nil, false
+	}
+	ownerID, err := uuid.Parse(r.URL.Query().Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return nil, false
+	}
+
+	job, err := h.bulkService.GetImport(r.Context(), jobID, ownerID)
+	if err != nil {
+		h.handleError(w, err)
+		return nil, false
+	}
+	return job, true
+}
+
+// ExportItems downloads an owner's items, maintenance logs or availability
+// as CSV or NDJSON
+func (h *HTTPHandler) ExportItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	ownerID, err := uuid.Parse(q.Get("owner_id"))
+	if err != nil {
+		http.Error(w, "Invalid owner_id", http.StatusBadRequest)
+		return
+	}
+
+	format := domain.ImportFormat(q.Get("format"))
+	if format == "" {
+		format = domain.FormatCSV
+	}
+	dataset := q.Get("dataset")
+	if dataset == "" {
+		dataset = service.DatasetItems
+	}
+
+	var span *service.ExportRange
+	if q.Get("from") != "" || q.Get("to") != "" {
+		from, err := parseDate(q.Get("from"))
+		if err != nil {
+			h.handleError(w, domain.ErrInvalidDateRange)
+			return
+		}
+		to, err := parseDate(q.Get("to"))
+		if err != nil {
+			h.handleError(w, domain.ErrInvalidDateRange)
+			return
+		}
+		span = &service.ExportRange{From: from, To: to}
+	}
+
+	// Exports are built in full first so that a failure part way through
+	// still gets an error response
+	var buf bytes.Buffer
+	if err := h.bulkService.Export(r.Context(), &buf, ownerID, format, dataset, span); err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == domain.FormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+dataset+`.`+string(format)+`"`)
+	w.Write(buf.Bytes())
+}
+
+func (h *HTTPHandler) handleError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var specErr *domain.SpecificationError
+	if errors.As(err, &specErr) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    err.Error(),
+			"problems": specErr.Problems,
+		})
+		return
+	}
+
+	var fileErr *domain.ImportFileError
+	if errors.As(err, &fileErr) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    err.Error(),
+			"problems": fileErr.Problems,
+		})
+		return
+	}
+
+	var statusErr *clients.StatusError
+	if errors.As(err, &statusErr) {
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
 
 	switch err {
-	case domain.ErrItemNotFound:
+	case domain.ErrItemNotFound, domain.ErrSlotNotFound, domain.ErrFeedNotFound, domain.ErrSchemaNotFound,
+		domain.ErrCategoryNotFound, domain.ErrImageNotFound, domain.ErrImportNotFound, domain.ErrMaintenanceNotFound,
+		domain.ErrPlanNotFound, domain.ErrUnitNotFound, domain.ErrAddOnNotFound, domain.ErrPricingRuleNotFound:
 		w.WriteHeader(http.StatusNotFound)
 	case domain.ErrUnauthorized:
 		w.WriteHeader(http.StatusForbidden)
-	case domain.ErrInvalidCategory:
+	case domain.ErrInvalidCategory, domain.ErrInvalidDateRange, domain.ErrInvalidFeedURL, domain.ErrFeedAddress,
+		domain.ErrInvalidRentalRules, domain.ErrRangeTooLong, domain.ErrInvalidLocation,
+		domain.ErrInvalidSchema, domain.ErrInvalidAttributeFilter, domain.ErrTooManyImages,
+		domain.ErrMissingTitle, domain.ErrInvalidPrice, domain.ErrInvalidImportFormat, domain.ErrInvalidDataset,
+		domain.ErrMissingMaintenanceType, domain.ErrInvalidMaintenancePlan, domain.ErrInvalidUnit, domain.ErrTooManyUnits,
+		domain.ErrInvalidAddOn, domain.ErrTooManyAddOns, domain.ErrInvalidPricingRule, domain.ErrTooManyPricingRules,
+		media.ErrInvalidImage, media.ErrNoFile:
 		w.WriteHeader(http.StatusBadRequest)
+	case media.ErrTooLarge, domain.ErrImportTooLarge:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case media.ErrUnsupportedType:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case domain.ErrFeedFetch:
+		w.WriteHeader(http.StatusBadGateway)
+	case domain.ErrDateConflict, domain.ErrItemChanged, domain.ErrCategoryExists, domain.ErrCategoryInUse, domain.ErrMaintenanceStatus,
+		domain.ErrLastUnit, domain.ErrUnitInUse, domain.ErrNoFreeUnit, domain.ErrAddOnUnavailable, domain.ErrFeedSyncing,
+		domain.ErrFeedClaimLost:
+		w.WriteHeader(http.StatusConflict)
+	case domain.ErrReservationBusy:
+		w.WriteHeader(http.StatusServiceUnavailable)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -0,0 +1,359 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) needed to
+// exchange busy periods with other rental platforms and personal calendars
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+
+	// maxLineLength is the longest content line we write before folding
+	maxLineLength = 75
+)
+
+// Event is a single VEVENT. End is exclusive, as in iCalendar; AllDay events
+// start and end at midnight UTC.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Status      string
+	Transparent bool
+	Stamp       time.Time
+}
+
+// InvalidEvent is an event Parse skipped because its times couldn't be read
+type InvalidEvent struct {
+	UID string
+	Err error
+}
+
+// Calendar is a named collection of events
+type Calendar struct {
+	ProductID string
+	Name      string
+	Events    []Event
+}
+
+// Encode writes the calendar as an iCalendar document
+func Encode(w io.Writer, cal *Calendar) error {
+	bw := bufio.NewWriter(w)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+cal.ProductID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	writeLine(bw, "METHOD:PUBLISH")
+	if cal.Name != "" {
+		writeLine(bw, "X-WR-CALNAME:"+escapeText(cal.Name))
+	}
+
+	for _, event := range cal.Events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+escapeText(event.UID))
+		writeLine(bw, "DTSTAMP:"+event.Stamp.UTC().Format(dateTimeLayout)+"Z")
+		if event.AllDay {
+			writeLine(bw, "DTSTART;VALUE=DATE:"+event.Start.UTC().Format(dateLayout))
+			writeLine(bw, "DTEND;VALUE=DATE:"+event.End.UTC().Format(dateLayout))
+		} else {
+			writeLine(bw, "DTSTART:"+event.Start.UTC().Format(dateTimeLayout)+"Z")
+			writeLine(bw, "DTEND:"+event.End.UTC().Format(dateTimeLayout)+"Z")
+		}
+		if event.Summary != "" {
+			writeLine(bw, "SUMMARY:"+escapeText(event.Summary))
+		}
+		if event.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Status != "" {
+			writeLine(bw, "STATUS:"+event.Status)
+		}
+		if event.Transparent {
+			writeLine(bw, "TRANSP:TRANSPARENT")
+		} else {
+			writeLine(bw, "TRANSP:OPAQUE")
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// Parse reads the events of an iCalendar document. Events without a start
+// are skipped; a missing end defaults to one day for all-day events and to
+// DURATION, if given, otherwise. Events whose start, end or duration can't be
+// read are left out and returned as invalid, so one bad event doesn't lose
+// the rest of the calendar.
+func Parse(r io.Reader) ([]Event, []InvalidEvent, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []Event
+	var invalid []InvalidEvent
+	var current *Event
+	var duration time.Duration
+	var hasEnd bool
+	var eventErr error
+	depth := 0
+
+	for _, line := range lines {
+		name, params, value, ok := splitLine(line)
+		if !ok {
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") && current == nil {
+				current = &Event{}
+				duration, hasEnd, eventErr = 0, false, nil
+			} else if current != nil {
+				// Nested components such as VALARM
+				depth++
+			}
+			continue
+		case "END":
+			if current == nil {
+				continue
+			}
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if strings.EqualFold(value, "VEVENT") {
+				switch {
+				case eventErr != nil:
+					invalid = append(invalid, InvalidEvent{UID: current.UID, Err: eventErr})
+				case !current.Start.IsZero():
+					if !hasEnd {
+						switch {
+						case duration > 0:
+							current.End = current.Start.Add(duration)
+						case current.AllDay:
+							current.End = current.Start.AddDate(0, 0, 1)
+						default:
+							current.End = current.Start
+						}
+					}
+					events = append(events, *current)
+				}
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil || depth > 0 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			current.UID = unescapeText(value)
+		case "SUMMARY":
+			current.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			current.Description = unescapeText(value)
+		case "STATUS":
+			current.Status = strings.ToUpper(value)
+		case "TRANSP":
+			current.Transparent = strings.EqualFold(value, "TRANSPARENT")
+		case "DTSTAMP":
+			if t, _, err := parseTime(params, value); err == nil {
+				current.Stamp = t
+			}
+		case "DTSTART":
+			t, allDay, err := parseTime(params, value)
+			if err != nil {
+				eventErr = fmt.Errorf("invalid DTSTART %q", value)
+				continue
+			}
+			current.Start = t
+			current.AllDay = allDay
+		case "DTEND":
+			t, _, err := parseTime(params, value)
+			if err != nil {
+				eventErr = fmt.Errorf("invalid DTEND %q", value)
+				continue
+			}
+			current.End = t
+			hasEnd = true
+		case "DURATION":
+			d, err := parseDuration(value)
+			if err != nil {
+				eventErr = fmt.Errorf("invalid DURATION %q", value)
+				continue
+			}
+			duration = d
+		}
+	}
+
+	return events, invalid, nil
+}
+
+// unfold joins continuation lines, which start with a space or tab
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// splitLine splits "NAME;PARAM=x;PARAM=y:value" into its parts
+func splitLine(line string) (name string, params map[string]string, value string, ok bool) {
+	colon := indexOutsideQuotes(line, ':')
+	if colon < 0 {
+		return "", nil, "", false
+	}
+	head, value := line[:colon], line[colon+1:]
+
+	parts := strings.Split(head, ";")
+	name = strings.ToUpper(parts[0])
+	params = make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		if k, v, found := strings.Cut(part, "="); found {
+			params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return name, params, value, true
+}
+
+func indexOutsideQuotes(s string, sep byte) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseTime reads a DATE or DATE-TIME value. Floating times and unknown
+// TZIDs are read as UTC.
+func parseTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, time.UTC)
+		return t, true, err
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if strings.HasSuffix(value, "Z") {
+		value = strings.TrimSuffix(value, "Z")
+		loc = time.UTC
+	}
+
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t.UTC(), false, nil
+}
+
+// parseDuration reads an RFC 5545 duration such as P1D, PT2H30M or P2W
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimPrefix(value, "+")
+	if !strings.HasPrefix(value, "P") {
+		return 0, fmt.Errorf("missing P designator")
+	}
+
+	var total time.Duration
+	var number int
+	var digits bool
+	inTime := false
+	for _, c := range value[1:] {
+		switch {
+		case c >= '0' && c <= '9':
+			number = number*10 + int(c-'0')
+			digits = true
+			continue
+		case c == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("missing number before %q", c)
+		}
+
+		unit := time.Duration(number)
+		switch {
+		case c == 'W' && !inTime:
+			total += unit * 7 * 24 * time.Hour
+		case c == 'D' && !inTime:
+			total += unit * 24 * time.Hour
+		case c == 'H' && inTime:
+			total += unit * time.Hour
+		case c == 'M' && inTime:
+			total += unit * time.Minute
+		case c == 'S' && inTime:
+			total += unit * time.Second
+		default:
+			return 0, fmt.Errorf("unexpected %q", c)
+		}
+		number, digits = 0, false
+	}
+	return total, nil
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+// writeLine writes a CRLF-terminated content line, folding it at 75 octets
+// without splitting a UTF-8 sequence
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines spend one octet on the leading space
+		limit = maxLineLength - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,191 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func calendar(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Event
+		invalid []string
+	}{
+		{
+			name: "all-day event",
+			input: calendar("BEGIN:VEVENT\r\nUID:a\r\nDTSTART;VALUE=DATE:20250301\r\nDTEND;VALUE=DATE:20250304\r\n" +
+				"SUMMARY:Rented\\, elsewhere\r\nEND:VEVENT\r\n"),
+			want: []Event{{UID: "a", Summary: "Rented, elsewhere", Start: date(2025, 3, 1), End: date(2025, 3, 4), AllDay: true}},
+		},
+		{
+			name:  "all-day event without an end lasts a day",
+			input: calendar("BEGIN:VEVENT\r\nUID:a\r\nDTSTART;VALUE=DATE:20250301\r\nEND:VEVENT\r\n"),
+			want:  []Event{{UID: "a", Start: date(2025, 3, 1), End: date(2025, 3, 2), AllDay: true}},
+		},
+		{
+			name:  "duration sets the end",
+			input: calendar("BEGIN:VEVENT\r\nUID:a\r\nDTSTART:20250301T100000Z\r\nDURATION:PT2H30M\r\nEND:VEVENT\r\n"),
+			want: []Event{{UID: "a", Start: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+				End: time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)}},
+		},
+		{
+			name:  "time zone is converted to UTC",
+			input: calendar("BEGIN:VEVENT\r\nUID:a\r\nDTSTART;TZID=Europe/Berlin:20250701T100000\r\nDTEND;TZID=Europe/Berlin:20250701T120000\r\nEND:VEVENT\r\n"),
+			want: []Event{{UID: "a", Start: time.Date(2025, 7, 1, 8, 0, 0, 0, time.UTC),
+				End: time.Date(2025, 7, 1, 10, 0, 0, 0, time.UTC)}},
+		},
+		{
+			name: "folded lines and nested alarms",
+			input: calendar("BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:Long\r\n  title\r\nDTSTART;VALUE=DATE:20250301\r\n" +
+				"BEGIN:VALARM\r\nSUMMARY:Alarm\r\nEND:VALARM\r\nSTATUS:cancelled\r\nTRANSP:TRANSPARENT\r\nEND:VEVENT\r\n"),
+			want: []Event{{UID: "a", Summary: "Long title", Start: date(2025, 3, 1), End: date(2025, 3, 2), AllDay: true,
+				Status: "CANCELLED", Transparent: true}},
+		},
+		{
+			name:  "event without a start is dropped",
+			input: calendar("BEGIN:VEVENT\r\nUID:a\r\nSUMMARY:No start\r\nEND:VEVENT\r\n"),
+		},
+		{
+			name: "bad start skips only that event",
+			input: calendar(
+				"BEGIN:VEVENT\r\nUID:bad\r\nDTSTART:not-a-date\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:good\r\nDTSTART;VALUE=DATE:20250301\r\nEND:VEVENT\r\n",
+			),
+			want:    []Event{{UID: "good", Start: date(2025, 3, 1), End: date(2025, 3, 2), AllDay: true}},
+			invalid: []string{"bad"},
+		},
+		{
+			name: "bad end and duration are reported",
+			input: calendar(
+				"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20250301\r\nDTEND:2025\r\nUID:end\r\nEND:VEVENT\r\n",
+				"BEGIN:VEVENT\r\nUID:duration\r\nDTSTART:20250301T100000Z\r\nDURATION:PXD\r\nEND:VEVENT\r\n",
+			),
+			invalid: []string{"end", "duration"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, invalid, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(events) != len(tt.want) {
+				t.Fatalf("got %d events, want %d: %+v", len(events), len(tt.want), events)
+			}
+			for i, want := range tt.want {
+				got := events[i]
+				if got.UID != want.UID || got.Summary != want.Summary || !got.Start.Equal(want.Start) || !got.End.Equal(want.End) ||
+					got.AllDay != want.AllDay || got.Status != want.Status || got.Transparent != want.Transparent {
+					t.Errorf("event %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if len(invalid) != len(tt.invalid) {
+				t.Fatalf("got %d invalid events, want %d: %+v", len(invalid), len(tt.invalid), invalid)
+			}
+			for i, uid := range tt.invalid {
+				if invalid[i].UID != uid || invalid[i].Err == nil {
+					t.Errorf("invalid event %d = %+v, want UID %q with an error", i, invalid[i], uid)
+				}
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	stamp := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		event Event
+		lines []string
+	}{
+		{
+			name:  "all-day event",
+			event: Event{UID: "a@rentalflow", Summary: "Booked", Start: date(2025, 3, 1), End: date(2025, 3, 4), AllDay: true, Status: "CONFIRMED", Stamp: stamp},
+			lines: []string{"UID:a@rentalflow", "DTSTAMP:20250102T030405Z", "DTSTART;VALUE=DATE:20250301", "DTEND;VALUE=DATE:20250304",
+				"SUMMARY:Booked", "STATUS:CONFIRMED", "TRANSP:OPAQUE"},
+		},
+		{
+			name: "timed event in UTC",
+			event: Event{UID: "b", Start: time.Date(2025, 3, 1, 10, 0, 0, 0, time.FixedZone("X", 3600)),
+				End: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC), Stamp: stamp, Transparent: true},
+			lines: []string{"DTSTART:20250301T090000Z", "DTEND:20250301T120000Z", "TRANSP:TRANSPARENT"},
+		},
+		{
+			name:  "text is escaped",
+			event: Event{UID: "c", Summary: "a,b;c\\d\ne", Start: date(2025, 3, 1), End: date(2025, 3, 2), AllDay: true, Stamp: stamp},
+			lines: []string{`SUMMARY:a\,b\;c\\d\ne`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, &Calendar{ProductID: "-//Test//EN", Events: []Event{tt.event}}); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			out := buf.String()
+			for _, line := range tt.lines {
+				if !strings.Contains(out, "\r\n"+line+"\r\n") {
+					t.Errorf("output is missing line %q:\n%s", line, out)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	name := strings.Repeat("é", 100)
+	if err := Encode(&buf, &Calendar{ProductID: "-//Test//EN", Name: name}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line is %d octets long: %q", len(line), line)
+		}
+	}
+
+	cal := buf.String()
+	if !strings.Contains(strings.ReplaceAll(cal, "\r\n ", ""), "X-WR-CALNAME:"+name) {
+		t.Errorf("unfolded output lost the calendar name:\n%s", cal)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	stamp := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := []Event{
+		{UID: "a", Summary: "Booked", Start: date(2025, 3, 1), End: date(2025, 3, 4), AllDay: true, Status: "CONFIRMED", Stamp: stamp},
+		{UID: "b", Summary: strings.Repeat("long summary, ", 10), Start: time.Date(2025, 3, 5, 9, 0, 0, 0, time.UTC),
+			End: time.Date(2025, 3, 5, 17, 0, 0, 0, time.UTC), Stamp: stamp},
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, &Calendar{ProductID: "-//Test//EN", Events: want}); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	got, invalid, err := Parse(&buf)
+	if err != nil || len(invalid) > 0 {
+		t.Fatalf("Parse: %v, invalid %+v", err, invalid)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].UID != want[i].UID || got[i].Summary != want[i].Summary || !got[i].Start.Equal(want[i].Start) ||
+			!got[i].End.Equal(want[i].End) || got[i].AllDay != want[i].AllDay || !got[i].Stamp.Equal(want[i].Stamp) {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
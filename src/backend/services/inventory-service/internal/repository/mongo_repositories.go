@@ -14,21 +14,84 @@ import (
 
 // MongoItemRepository implements ItemRepository using MongoDB
 type MongoItemRepository struct {
-	coll *mongo.Collection
+	coll  *mongo.Collection
+	terms *mongo.Collection
 }
 
 // NewMongoItemRepository creates a new MongoDB item repository
 func NewMongoItemRepository(db *mongo.Database) *MongoItemRepository {
 	return &MongoItemRepository{
-		coll: db.Collection("rental_items"),
+		coll:  db.Collection("rental_items"),
+		terms: db.Collection("search_terms"),
 	}
 }
 
-func (r *MongoItemRepository) Create(ctx context.Context, item *domain.RentalItem) error {
-	_, err := r.coll.InsertOne(ctx, item)
+// EnsureIndexes creates the 2dsphere index behind distance searches, the text
+// index behind Search and the category path index, and gives items saved
+// before them a point built from their coordinates, a place in the search
+// vocabulary, a category path and a capacity
+func (r *MongoItemRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+	})
+	if err != nil {
+		return err
+	}
+	if err := r.ensureSearchIndexes(ctx); err != nil {
+		return err
+	}
+
+	backfill := bson.M{
+		"location": bson.M{"$exists": false},
+		"$or": []bson.M{
+			{"latitude": bson.M{"$ne": 0}},
+			{"longitude": bson.M{"$ne": 0}},
+		},
+		"latitude":  bson.M{"$gte": -90, "$lte": 90},
+		"longitude": bson.M{"$gte": -180, "$lte": 180},
+	}
+	setPoint := []bson.M{{"$set": bson.M{
+		"location": bson.M{"type": "Point", "coordinates": bson.A{"$longitude", "$latitude"}},
+	}}}
+	if _, err = r.coll.UpdateMany(ctx, backfill, setPoint); err != nil {
+		return err
+	}
+
+	// Items saved before the category tree get a path from their category
+	// and subcategory
+	_, err = r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "category_path", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+	setPath := []bson.M{{"$set": bson.M{
+		"category_path": bson.M{"$cond": bson.A{
+			bson.M{"$in": bson.A{"$subcategory", bson.A{"", nil}}},
+			bson.A{"$category"},
+			bson.A{"$category", "$subcategory"},
+		}},
+	}}}
+	_, err = r.coll.UpdateMany(ctx, bson.M{"category_path": bson.M{"$exists": false}}, setPath)
+	if err != nil {
+		return err
+	}
+
+	// Items saved before units hold one reservation at a time
+	_, err = r.coll.UpdateMany(ctx, bson.M{"capacity": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"capacity": 1}})
 	return err
 }
 
+func (r *MongoItemRepository) Create(ctx context.Context, item *domain.RentalItem) error {
+	if _, err := r.coll.InsertOne(ctx, item); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrItemExists
+		}
+		return err
+	}
+	return r.indexTerms(ctx, item)
+}
+
 func (r *MongoItemRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RentalItem, error) {
 	var item domain.RentalItem
 	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
@@ -69,10 +132,15 @@ func (r *MongoItemRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID,
 }
 
 func (r *MongoItemRepository) List(ctx context.Context, offset, limit int, filters ItemFilters) ([]*domain.RentalItem, int, error) {
+	return r.find(ctx, itemFilter(filters), filters, offset, limit)
+}
+
+// itemFilter turns the attribute and bounding box filters into a query
+func itemFilter(filters ItemFilters) bson.M {
 	filter := bson.M{}
 
 	if filters.Category != nil {
-		filter["category"] = *filters.Category
+		filter["category_path"] = *filters.Category
 	}
 	if filters.City != nil {
 		filter["city"] = *filters.City
@@ -80,11 +148,6 @@ func (r *MongoItemRepository) List(ctx context.Context, offset, limit int, filte
 	if filters.IsActive != nil {
 		filter["is_active"] = *filters.IsActive
 	}
-	// Note: MinPrice/MaxPrice implemented if referenced in filter?
-	// The postgres repo did NOT implement MinPrice/MaxPrice logic in List function shown above (step 773),
-	// it only handled Category, City, IsActive in `List`.
-	// However, `ItemFilters` struct HAS MinPrice/MaxPrice.
-	// I will implement them here for completeness.
 	if filters.MinPrice != nil || filters.MaxPrice != nil {
 		priceFilter := bson.M{}
 		if filters.MinPrice != nil {
@@ -96,21 +159,171 @@ func (r *MongoItemRepository) List(ctx context.Context, offset, limit int, filte
 		filter["daily_rate"] = priceFilter
 	}
 
+	// A box is matched on the plain coordinates, since 2dsphere polygon edges
+	// follow great circles rather than lines of latitude
+	var and []bson.M
+	if box := filters.Within; box != nil {
+		filter["location"] = bson.M{"$exists": true}
+		filter["latitude"] = bson.M{"$gte": box.MinLatitude, "$lte": box.MaxLatitude}
+		if box.MinLongitude < box.MaxLongitude {
+			filter["longitude"] = bson.M{"$gte": box.MinLongitude, "$lte": box.MaxLongitude}
+		} else {
+			and = append(and, bson.M{"$or": []bson.M{
+				{"longitude": bson.M{"$gte": box.MinLongitude}},
+				{"longitude": bson.M{"$lte": box.MaxLongitude}},
+			}})
+		}
+	}
+
+	for _, attr := range filters.Attributes {
+		field := "attributes." + attr.Key
+		cond, ok := filter[field].(bson.M)
+		if !ok {
+			cond = bson.M{}
+			filter[field] = cond
+		}
+		cond[attributeOperators[attr.Op]] = attr.Value
+	}
+
+	if filters.AvailableFrom != nil && filters.AvailableTo != nil {
+		rules, blackouts := rulesFilter(*filters.AvailableFrom, *filters.AvailableTo, time.Now())
+		and = append(and, rules...)
+		filter["rules.blackouts"] = blackouts
+	}
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+
+	return filter
+}
+
+// availabilityStages drop the items whose slots take up all of their
+// capacity at some point in the searched range, joining each item to its
+// overlapping slots. The busiest moment is always the start of one of the
+// slots, clamped to the range, so the peak is the most slots covering any
+// of those starts, as PeakReservations works it out.
+func availabilityStages(filters ItemFilters) []bson.M {
+	if filters.AvailableFrom == nil || filters.AvailableTo == nil {
+		return nil
+	}
+	from, to := *filters.AvailableFrom, *filters.AvailableTo
+
+	peak := bson.M{"$max": bson.M{"$map": bson.M{
+		"input": "$reserved_slots",
+		"as":    "s",
+		"in": bson.M{"$let": bson.M{
+			"vars": bson.M{"at": bson.M{"$max": bson.A{"$$s.start_date", from}}},
+			"in": bson.M{"$size": bson.M{"$filter": bson.M{
+				"input": "$reserved_slots",
+				"as":    "t",
+				"cond": bson.M{"$and": bson.A{
+					bson.M{"$lte": bson.A{"$$t.start_date", "$$at"}},
+					bson.M{"$gt": bson.A{"$$t.end_date", "$$at"}},
+				}},
+			}}},
+		}},
+	}}}
+	capacity := bson.M{"$max": bson.A{bson.M{"$ifNull": bson.A{"$capacity", 1}}, 1}}
+
+	return []bson.M{
+		{"$lookup": bson.M{
+			"from":         "availability_slots",
+			"localField":   "_id",
+			"foreignField": "rental_item_id",
+			"pipeline": []bson.M{
+				{"$match": bson.M{
+					"status":     bson.M{"$ne": domain.StatusAvailable},
+					"start_date": bson.M{"$lt": to},
+					"end_date":   bson.M{"$gt": from},
+				}},
+				{"$project": bson.M{"_id": 0, "start_date": 1, "end_date": 1}},
+			},
+			"as": "reserved_slots",
+		}},
+		{"$match": bson.M{"$expr": bson.M{"$lt": bson.A{bson.M{"$ifNull": bson.A{peak, 0}}, capacity}}}},
+		{"$unset": "reserved_slots"},
+	}
+}
+
+// attributeOperators maps specification filters to query operators
+var attributeOperators = map[domain.AttributeOp]string{
+	domain.AttributeEq:  "$eq",
+	domain.AttributeNe:  "$ne",
+	domain.AttributeGt:  "$gt",
+	domain.AttributeGte: "$gte",
+	domain.AttributeLt:  "$lt",
+	domain.AttributeLte: "$lte",
+}
+
+// rulesFilter matches the items whose rental rules allow renting over
+// [from, to) at the given time, mirroring RentalRules.Check. Rules saved as
+// zero or missing mean no limit.
+func rulesFilter(from, to, now time.Time) ([]bson.M, bson.M) {
+	days := domain.RentalDays(from, to)
+	notice := from.Sub(now).Hours()
+	unset := func(field string) bson.M {
+		return bson.M{field: bson.M{"$in": bson.A{0, nil}}}
+	}
+
+	limits := []bson.M{
+		{"rules.min_days": bson.M{"$not": bson.M{"$gt": days}}},
+		{"$or": []bson.M{unset("rules.max_days"), {"rules.max_days": bson.M{"$gte": days}}}},
+		{"$or": []bson.M{unset("rules.lead_time_hours"), {"rules.lead_time_hours": bson.M{"$lte": notice}}}},
+		{"$or": []bson.M{unset("rules.max_advance_days"), {"rules.max_advance_days": bson.M{"$gte": notice / 24}}}},
+	}
+
+	// A day is blacked out when it falls on a blocked weekday, inside a
+	// one-off period, or on a yearly month-day
+	lastDay := domain.StartOfDay(to.Add(-time.Nanosecond))
+	if lastDay.Before(from) {
+		lastDay = domain.StartOfDay(from)
+	}
+	weekdays := domain.WeekdaysBetween(from, to)
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{from.UTC().Weekday()}
+	}
+	blackouts := bson.M{"$not": bson.M{"$elemMatch": bson.M{"$or": []bson.M{
+		{"repeat": domain.BlackoutWeekly, "weekdays": bson.M{"$in": weekdays}},
+		{
+			"repeat":     domain.BlackoutOnce,
+			"start_date": bson.M{"$lt": lastDay.AddDate(0, 0, 1)},
+			"end_date":   bson.M{"$gt": domain.StartOfDay(from)},
+		},
+		{"repeat": domain.BlackoutYearly, "month_days": bson.M{"$in": domain.MonthDaysBetween(from, lastDay.AddDate(0, 0, 1))}},
+	}}}}
+
+	return limits, blackouts
+}
+
+// itemSort is the requested sort order, or nil if none was asked for
+func itemSort(filters ItemFilters) bson.M {
+	if filters.SortBy == nil {
+		return nil
+	}
+	switch *filters.SortBy {
+	case "price_low":
+		return bson.M{"daily_rate": 1}
+	case "price_high":
+		return bson.M{"daily_rate": -1}
+	case "newest":
+		return bson.M{"created_at": -1}
+	}
+	return nil
+}
+
+func (r *MongoItemRepository) find(ctx context.Context, filter bson.M, filters ItemFilters, offset, limit int) ([]*domain.RentalItem, int, error) {
+	if filters.HasGeo() || filters.AvailableFrom != nil {
+		return r.findPipeline(ctx, filter, filters, offset, limit)
+	}
+
 	total, err := r.coll.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	sort := bson.M{"created_at": -1}
-	if filters.SortBy != nil {
-		switch *filters.SortBy {
-		case "price_low":
-			sort = bson.M{"daily_rate": 1}
-		case "price_high":
-			sort = bson.M{"daily_rate": -1}
-		case "newest":
-			sort = bson.M{"created_at": -1}
-		}
+	sort := itemSort(filters)
+	if sort == nil {
+		sort = bson.M{"created_at": -1}
 	}
 
 	opts := options.Find().
@@ -132,61 +345,96 @@ func (r *MongoItemRepository) List(ctx context.Context, offset, limit int, filte
 	return items, int(total), nil
 }
 
-func (r *MongoItemRepository) Search(ctx context.Context, query string, filters ItemFilters, offset, limit int) ([]*domain.RentalItem, int, error) {
-	// Simple regex search on title or description
-	regex := bson.M{"$regex": query, "$options": "i"}
-	filter := bson.M{
-		"$or": []bson.M{
-			{"title": regex},
-			{"description": regex},
-		},
+// geoNearStage measures distances from the search point, or the box centre,
+// for the items matching filter
+func geoNearStage(filter bson.M, filters ItemFilters) bson.M {
+	var latitude, longitude float64
+	if filters.Near != nil {
+		latitude, longitude = filters.Near.Latitude, filters.Near.Longitude
+	} else {
+		latitude, longitude = filters.Within.Center()
 	}
 
-	// Apply other filters
-	if filters.Category != nil {
-		filter["category"] = *filters.Category
+	geoNear := bson.M{
+		"near":               domain.GeoPoint{Type: "Point", Coordinates: []float64{longitude, latitude}},
+		"key":                "location",
+		"distanceField":      "distance_km",
+		"distanceMultiplier": 0.001,
+		"spherical":          true,
+		"query":              filter,
 	}
-	if filters.City != nil {
-		filter["city"] = *filters.City
+	if filters.Near != nil {
+		geoNear["maxDistance"] = filters.Near.RadiusKm * 1000
 	}
-	if filters.IsActive != nil {
-		filter["is_active"] = *filters.IsActive
+	return geoNear
+}
+
+// findPipeline runs the query as an aggregation, for searches by location or
+// availability. Location searches go through $geoNear, which fills in each
+// item's distance from the search point (or the box centre) and sorts by it.
+func (r *MongoItemRepository) findPipeline(ctx context.Context, filter bson.M, filters ItemFilters, offset, limit int) ([]*domain.RentalItem, int, error) {
+	sort := itemSort(filters)
+	var pipeline []bson.M
+	if filters.HasGeo() {
+		pipeline = append(pipeline, bson.M{"$geoNear": geoNearStage(filter, filters)})
+	} else {
+		pipeline = append(pipeline, bson.M{"$match": filter})
+		if sort == nil {
+			sort = bson.M{"created_at": -1}
+		}
 	}
+	pipeline = append(pipeline, availabilityStages(filters)...)
 
-	total, err := r.coll.CountDocuments(ctx, filter)
+	page := []bson.M{}
+	if sort != nil {
+		page = append(page, bson.M{"$sort": sort})
+	}
+	page = append(page, bson.M{"$skip": offset}, bson.M{"$limit": limit})
+	pipeline = append(pipeline, bson.M{"$facet": bson.M{
+		"items": page,
+		"total": []bson.M{{"$count": "count"}},
+	}})
+
+	cursor, err := r.coll.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, 0, err
 	}
+	defer cursor.Close(ctx)
 
-	sort := bson.M{"created_at": -1}
-	if filters.SortBy != nil {
-		switch *filters.SortBy {
-		case "price_low":
-			sort = bson.M{"daily_rate": 1}
-		case "price_high":
-			sort = bson.M{"daily_rate": -1}
-		case "newest":
-			sort = bson.M{"created_at": -1}
-		}
+	var results []struct {
+		Items []*domain.RentalItem `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+	if len(results) == 0 || len(results[0].Total) == 0 {
+		return []*domain.RentalItem{}, 0, nil
 	}
 
+	return results[0].Items, results[0].Total[0].Count, nil
+}
+
+func (r *MongoItemRepository) GetFeatured(ctx context.Context, limit int) ([]*domain.RentalItem, error) {
+	filter := bson.M{"is_featured": true, "is_active": true}
+
 	opts := options.Find().
-		SetSort(sort).
-		SetSkip(int64(offset)).
+		SetSort(bson.M{"created_at": -1}).
 		SetLimit(int64(limit))
 
 	cursor, err := r.coll.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer cursor.Close(ctx)
 
 	var items []*domain.RentalItem
 	if err := cursor.All(ctx, &items); err != nil {
-		return nil, 0, err
+		return nil, err
 	}
-
-	return items, int(total), nil
+	return items, nil
 }
 
 func (r *MongoItemRepository) Update(ctx context.Context, item *domain.RentalItem) error {
@@ -196,6 +444,7 @@ func (r *MongoItemRepository) Update(ctx context.Context, item *domain.RentalIte
 			"description":      item.Description,
 			"category":         item.Category,
 			"subcategory":      item.Subcategory,
+			"category_path":    item.CategoryPath,
 			"daily_rate":       item.DailyRate,
 			"weekly_rate":      item.WeeklyRate,
 			"monthly_rate":     item.MonthlyRate,
@@ -204,22 +453,83 @@ func (r *MongoItemRepository) Update(ctx context.Context, item *domain.RentalIte
 			"city":             item.City,
 			"latitude":         item.Latitude,
 			"longitude":        item.Longitude,
+			"location":         item.GeoLocation,
 			"specifications":   item.Specifications,
+			"attributes":       item.Attributes,
 			"images":           item.Images,
+			"media":            item.Media,
+			"rules":            item.Rules,
+			"units":            item.Units,
+			"add_ons":          item.AddOns,
+			"pricing_rules":    item.PricingRules,
+			"capacity":         item.UnitCapacity(),
 			"is_active":        item.IsActive,
+			"is_featured":      item.IsFeatured,
 			"updated_at":       time.Now(),
+			"version":          item.Version + 1,
 		},
 	}
 
-	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": item.ID}, update)
+	// Items saved before versions were kept have none
+	var version interface{} = item.Version
+	if item.Version == 0 {
+		version = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": item.ID, "version": version}, update)
 	if err != nil {
 		return err
 	}
 
 	if result.MatchedCount == 0 {
-		return domain.ErrItemNotFound
+		count, err := r.coll.CountDocuments(ctx, bson.M{"_id": item.ID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrItemNotFound
+		}
+		return domain.ErrItemChanged
 	}
-	return nil
+	item.Version++
+	return r.indexTerms(ctx, item)
+}
+
+func (r *MongoItemRepository) CountInCategory(ctx context.Context, slug string) (int, error) {
+	count, err := r.coll.CountDocuments(ctx, bson.M{"category_path": slug})
+	return int(count), err
+}
+
+func (r *MongoItemRepository) MoveCategory(ctx context.Context, slug string, oldAncestors, newAncestors []string) error {
+	update := []bson.M{
+		{"$set": bson.M{"category_path": movedPath("category_path", oldAncestors, newAncestors)}},
+		{"$set": bson.M{
+			"category": bson.M{"$arrayElemAt": bson.A{"$category_path", 0}},
+			"subcategory": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": "$category_path"}, 1}},
+				bson.M{"$arrayElemAt": bson.A{"$category_path", -1}},
+				"",
+			}},
+			// A move counts as a change, so owner edits from before it are refused
+			"version": bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}},
+		}},
+	}
+	_, err := r.coll.UpdateMany(ctx, bson.M{"category_path": slug}, update)
+	return err
+}
+
+// maxCategoryDepth bounds how deep the category tree is read
+const maxCategoryDepth = 32
+
+// movedPath swaps the leading oldAncestors of a path field for newAncestors
+func movedPath(field string, oldAncestors, newAncestors []string) bson.M {
+	if newAncestors == nil {
+		newAncestors = []string{}
+	}
+	return bson.M{"$concatArrays": bson.A{
+		newAncestors,
+		bson.M{"$slice": bson.A{"$" + field, len(oldAncestors), maxCategoryDepth}},
+	}}
 }
 
 func (r *MongoItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -233,17 +543,33 @@ func (r *MongoItemRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// reservationLockTTL bounds how long a crashed reservation can keep an item locked
+const reservationLockTTL = 10 * time.Second
+
 // MongoAvailabilityRepository implements AvailabilityRepository using MongoDB
 type MongoAvailabilityRepository struct {
-	coll *mongo.Collection
+	coll  *mongo.Collection
+	locks *mongo.Collection
+	feeds *mongo.Collection
 }
 
 func NewMongoAvailabilityRepository(db *mongo.Database) *MongoAvailabilityRepository {
 	return &MongoAvailabilityRepository{
-		coll: db.Collection("availability_slots"),
+		coll:  db.Collection("availability_slots"),
+		locks: db.Collection("availability_locks"),
+		feeds: db.Collection("calendar_feeds"),
 	}
 }
 
+// EnsureIndexes creates the index used to find an item's slots over a range,
+// which searches by availability join items to
+func (r *MongoAvailabilityRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "rental_item_id", Value: 1}, {Key: "start_date", Value: 1}, {Key: "end_date", Value: 1}},
+	})
+	return err
+}
+
 func (r *MongoAvailabilityRepository) Create(ctx context.Context, slot *domain.AvailabilitySlot) error {
 	_, err := r.coll.InsertOne(ctx, slot)
 	return err
@@ -283,6 +609,18 @@ func (r *MongoAvailabilityRepository) GetByItem(ctx context.Context, itemID uuid
 	return slots, nil
 }
 
+func (r *MongoAvailabilityRepository) GetByBooking(ctx context.Context, bookingID uuid.UUID) (*domain.AvailabilitySlot, error) {
+	var slot domain.AvailabilitySlot
+	err := r.coll.FindOne(ctx, bson.M{"booking_id": bookingID}).Decode(&slot)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSlotNotFound
+		}
+		return nil, err
+	}
+	return &slot, nil
+}
+
 func (r *MongoAvailabilityRepository) Update(ctx context.Context, slot *domain.AvailabilitySlot) error {
 	update := bson.M{
 		"$set": bson.M{
@@ -311,7 +649,32 @@ func (r *MongoAvailabilityRepository) Delete(ctx context.Context, id uuid.UUID)
 	return nil
 }
 
-func (r *MongoAvailabilityRepository) CheckConflict(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, excludeSlotID *uuid.UUID) (bool, error) {
+func (r *MongoAvailabilityRepository) DeleteByBooking(ctx context.Context, bookingID uuid.UUID) error {
+	_, err := r.coll.DeleteMany(ctx, bson.M{"booking_id": bookingID})
+	return err
+}
+
+func (r *MongoAvailabilityRepository) ConfirmByBooking(ctx context.Context, bookingID uuid.UUID) error {
+	result, err := r.coll.UpdateMany(ctx, bson.M{"booking_id": bookingID}, bson.M{"$set": bson.M{"confirmed": true}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSlotNotFound
+	}
+	return nil
+}
+
+func (r *MongoAvailabilityRepository) CheckConflict(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, excludeSlotID *uuid.UUID, capacity int) (bool, error) {
+	return r.conflicts(ctx, itemID, startDate, endDate, excludeSlotID, capacity, nil)
+}
+
+// conflicts counts overlapping slots directly for a single-capacity item.
+// Otherwise it loads them to find the busiest moment in the range, since
+// slots overlapping the range needn't all overlap each other.
+func (r *MongoAvailabilityRepository) conflicts(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, excludeSlotID *uuid.UUID,
+	capacity int, unitID *uuid.UUID) (bool, error) {
+
 	// Find any slot that overlaps and is not 'available'
 	// Overlap logic: (StartA <= EndB) and (EndA >= StartB)
 	filter := bson.M{
@@ -325,89 +688,953 @@ func (r *MongoAvailabilityRepository) CheckConflict(ctx context.Context, itemID
 		filter["_id"] = bson.M{"$ne": excludeSlotID}
 	}
 
-	count, err := r.coll.CountDocuments(ctx, filter)
-	return count > 0, err
-}
-
-// MongoMaintenanceRepository implements MaintenanceRepository
-type MongoMaintenanceRepository struct {
-	coll *mongo.Collection
-}
+	if capacity <= 1 && unitID == nil {
+		count, err := r.coll.CountDocuments(ctx, filter)
+		return count > 0, err
+	}
 
-func NewMongoMaintenanceRepository(db *mongo.Database) *MongoMaintenanceRepository {
-	return &MongoMaintenanceRepository{
-		coll: db.Collection("maintenance_logs"),
+	cursor, err := r.coll.Find(ctx, filter)
+	if err != nil {
+		return false, err
 	}
-}
+	defer cursor.Close(ctx)
 
-func (r *MongoMaintenanceRepository) Create(ctx context.Context, log *domain.MaintenanceLog) error {
-	_, err := r.coll.InsertOne(ctx, log)
-	return err
+	var slots []*domain.AvailabilitySlot
+	if err := cursor.All(ctx, &slots); err != nil {
+		return false, err
+	}
+	return !domain.Fits(slots, startDate, endDate, capacity, unitID), nil
 }
 
-func (r *MongoMaintenanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.MaintenanceLog, error) {
-	var log domain.MaintenanceLog
-	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&log)
+func (r *MongoAvailabilityRepository) Reserve(ctx context.Context, slot *domain.AvailabilitySlot, limits domain.ReservationLimits) error {
+	token, err := r.lockItem(ctx, slot.RentalItemID)
 	if err != nil {
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, domain.ErrMaintenanceNotFound
-		}
-		return nil, err
+		return err
 	}
-	return &log, nil
-}
-
-func (r *MongoMaintenanceRepository) GetByItem(ctx context.Context, itemID uuid.UUID, offset, limit int) ([]*domain.MaintenanceLog, int, error) {
-	filter := bson.M{"rental_item_id": itemID}
+	defer r.unlockItem(context.Background(), slot.RentalItemID, token)
 
-	total, err := r.coll.CountDocuments(ctx, filter)
+	hasConflict, err := r.conflicts(ctx, slot.RentalItemID, slot.StartDate, slot.EndDate, nil, limits.Capacity, slot.UnitID)
 	if err != nil {
-		return nil, 0, err
+		return err
+	}
+	if hasConflict {
+		return domain.ErrDateConflict
+	}
+	if err := r.checkAddOns(ctx, slot, slot.StartDate, slot.EndDate, nil, limits); err != nil {
+		return err
 	}
 
-	opts := options.Find().
-		SetSort(bson.M{"created_at": -1}).
-		SetSkip(int64(offset)).
-		SetLimit(int64(limit))
+	return r.Create(ctx, slot)
+}
 
-	cursor, err := r.coll.Find(ctx, filter, opts)
+// Reschedule moves a slot to new dates, under the same per-item lock as Reserve.
+// The slot's own current dates don't count as a conflict.
+func (r *MongoAvailabilityRepository) Reschedule(ctx context.Context, slot *domain.AvailabilitySlot, startDate, endDate time.Time, limits domain.ReservationLimits) error {
+	token, err := r.lockItem(ctx, slot.RentalItemID)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
-	defer cursor.Close(ctx)
+	defer r.unlockItem(context.Background(), slot.RentalItemID, token)
 
-	var logs []*domain.MaintenanceLog
-	if err := cursor.All(ctx, &logs); err != nil {
-		return nil, 0, err
+	hasConflict, err := r.conflicts(ctx, slot.RentalItemID, startDate, endDate, &slot.ID, limits.Capacity, slot.UnitID)
+	if err != nil {
+		return err
+	}
+	if hasConflict {
+		return domain.ErrDateConflict
+	}
+	if err := r.checkAddOns(ctx, slot, startDate, endDate, &slot.ID, limits); err != nil {
+		return err
 	}
 
-	return logs, int(total), nil
-}
-
-func (r *MongoMaintenanceRepository) Update(ctx context.Context, log *domain.MaintenanceLog) error {
 	update := bson.M{
 		"$set": bson.M{
-			"status":   log.Status,
-			"end_date": log.EndDate,
+			"start_date": startDate,
+			"end_date":   endDate,
 		},
 	}
-	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": log.ID}, update)
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": slot.ID}, update)
 	if err != nil {
 		return err
 	}
 	if result.MatchedCount == 0 {
-		return domain.ErrMaintenanceNotFound
+		return domain.ErrSlotNotFound
 	}
+
+	slot.StartDate = startDate
+	slot.EndDate = endDate
 	return nil
 }
 
-func (r *MongoMaintenanceRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+// checkAddOns makes sure there is stock for the slot's add-ons over the
+// range, counting what other slots hold. Add-ons without a stock limit need
+// no query.
+func (r *MongoAvailabilityRepository) checkAddOns(ctx context.Context, slot *domain.AvailabilitySlot, startDate, endDate time.Time, excludeSlotID *uuid.UUID,
+	limits domain.ReservationLimits) error {
+
+	if len(limits.Stocked(slot.AddOns)) == 0 {
+		return nil
+	}
+
+	filter := bson.M{
+		"rental_item_id": slot.RentalItemID,
+		"status":         bson.M{"$ne": domain.StatusAvailable},
+		"start_date":     bson.M{"$lt": endDate},
+		"end_date":       bson.M{"$gt": startDate},
+		"add_ons.0":      bson.M{"$exists": true},
+	}
+	if excludeSlotID != nil {
+		filter["_id"] = bson.M{"$ne": excludeSlotID}
+	}
+
+	cursor, err := r.coll.Find(ctx, filter)
 	if err != nil {
 		return err
 	}
-	if result.DeletedCount == 0 {
-		return domain.ErrMaintenanceNotFound
+	defer cursor.Close(ctx)
+
+	var slots []*domain.AvailabilitySlot
+	if err := cursor.All(ctx, &slots); err != nil {
+		return err
+	}
+	if !limits.AddOnsFit(slots, startDate, endDate, slot.AddOns) {
+		return domain.ErrAddOnUnavailable
+	}
+	return nil
+}
+
+// AssignUnit picks the unit under the item lock, so two pickups at once
+// can't be handed the same unit
+func (r *MongoAvailabilityRepository) AssignUnit(ctx context.Context, slot *domain.AvailabilitySlot, units []domain.ItemUnit) error {
+	token, err := r.lockItem(ctx, slot.RentalItemID)
+	if err != nil {
+		return err
+	}
+	defer r.unlockItem(context.Background(), slot.RentalItemID, token)
+
+	overlapping, err := r.GetOverlapping(ctx, slot.RentalItemID, slot.StartDate, slot.EndDate)
+	if err != nil {
+		return err
+	}
+	others := make([]*domain.AvailabilitySlot, 0, len(overlapping))
+	for _, other := range overlapping {
+		if other.ID != slot.ID {
+			others = append(others, other)
+		}
+	}
+	unit, err := domain.FreeUnit(units, others, slot.StartDate, slot.EndDate)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": slot.ID}, bson.M{"$set": bson.M{"unit_id": unit.ID}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSlotNotFound
+	}
+
+	slot.UnitID = &unit.ID
+	return nil
+}
+
+func (r *MongoAvailabilityRepository) GetOverlapping(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time) ([]*domain.AvailabilitySlot, error) {
+	filter := bson.M{
+		"rental_item_id": itemID,
+		"start_date":     bson.M{"$lt": endDate},
+		"end_date":       bson.M{"$gt": startDate},
+	}
+
+	opts := options.Find().SetSort(bson.M{"start_date": 1})
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var slots []*domain.AvailabilitySlot
+	if err := cursor.All(ctx, &slots); err != nil {
+		return nil, err
+	}
+	return slots, nil
+}
+
+// ReplaceFeedSlots inserts the new slots before removing the old ones, so the
+// feed's dates never appear free part way through a sync. The claim is checked
+// once the new slots are in: RemoveFeed deletes the feed before its slots, so
+// slots inserted for a feed still found here are caught by its cleanup.
+func (r *MongoAvailabilityRepository) ReplaceFeedSlots(ctx context.Context, feedID, syncToken uuid.UUID, slots []*domain.AvailabilitySlot) error {
+	keep := make([]uuid.UUID, 0, len(slots))
+	if len(slots) > 0 {
+		docs := make([]interface{}, len(slots))
+		for i, slot := range slots {
+			docs[i] = slot
+			keep = append(keep, slot.ID)
+		}
+		if _, err := r.coll.InsertMany(ctx, docs); err != nil {
+			return err
+		}
+	}
+
+	held, err := r.feeds.CountDocuments(ctx, bson.M{"_id": feedID, "sync_token": syncToken})
+	if err != nil || held == 0 {
+		if len(keep) > 0 {
+			r.coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": keep}})
+		}
+		if err != nil {
+			return err
+		}
+		return domain.ErrFeedClaimLost
+	}
+
+	_, err = r.coll.DeleteMany(ctx, bson.M{"feed_id": feedID, "_id": bson.M{"$nin": keep}})
+	return err
+}
+
+func (r *MongoAvailabilityRepository) DeleteByFeed(ctx context.Context, feedID uuid.UUID) error {
+	_, err := r.coll.DeleteMany(ctx, bson.M{"feed_id": feedID})
+	return err
+}
+
+// lockItem takes a short-lived per-item lock so that concurrent reservations for
+// the same item are serialized. While another holder's lock is unexpired the
+// upsert collides on _id, and we back off and retry.
+func (r *MongoAvailabilityRepository) lockItem(ctx context.Context, itemID uuid.UUID) (uuid.UUID, error) {
+	token := uuid.New()
+	opts := options.Update().SetUpsert(true)
+
+	for attempt := 0; attempt < 20; attempt++ {
+		now := time.Now()
+		filter := bson.M{"_id": itemID, "expires_at": bson.M{"$lt": now}}
+		update := bson.M{"$set": bson.M{"token": token, "expires_at": now.Add(reservationLockTTL)}}
+
+		_, err := r.locks.UpdateOne(ctx, filter, update, opts)
+		if err == nil {
+			return token, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return uuid.Nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return uuid.Nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	return uuid.Nil, domain.ErrReservationBusy
+}
+
+func (r *MongoAvailabilityRepository) unlockItem(ctx context.Context, itemID, token uuid.UUID) {
+	r.locks.DeleteOne(ctx, bson.M{"_id": itemID, "token": token})
+}
+
+// MongoCalendarFeedRepository implements CalendarFeedRepository using MongoDB
+type MongoCalendarFeedRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoCalendarFeedRepository(db *mongo.Database) *MongoCalendarFeedRepository {
+	return &MongoCalendarFeedRepository{
+		coll: db.Collection("calendar_feeds"),
+	}
+}
+
+func (r *MongoCalendarFeedRepository) Create(ctx context.Context, feed *domain.CalendarFeed) error {
+	_, err := r.coll.InsertOne(ctx, feed)
+	return err
+}
+
+func (r *MongoCalendarFeedRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.CalendarFeed, error) {
+	var feed domain.CalendarFeed
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&feed)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrFeedNotFound
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *MongoCalendarFeedRepository) GetByItem(ctx context.Context, itemID uuid.UUID) ([]*domain.CalendarFeed, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.coll.Find(ctx, bson.M{"rental_item_id": itemID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var feeds []*domain.CalendarFeed
+	if err := cursor.All(ctx, &feeds); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+func (r *MongoCalendarFeedRepository) ClaimDue(ctx context.Context, before, leaseUntil time.Time) (*domain.CalendarFeed, error) {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"last_synced_at": bson.M{"$exists": false}},
+				{"last_synced_at": bson.M{"$lt": before}},
+			}},
+			unclaimedFeedFilter(time.Now()),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"last_synced_at": 1})
+	return r.claim(ctx, filter, leaseUntil, opts)
+}
+
+func (r *MongoCalendarFeedRepository) Claim(ctx context.Context, id uuid.UUID, leaseUntil time.Time) (*domain.CalendarFeed, error) {
+	filter := bson.M{"$and": []bson.M{{"_id": id}, unclaimedFeedFilter(time.Now())}}
+	return r.claim(ctx, filter, leaseUntil, options.FindOneAndUpdate())
+}
+
+// unclaimedFeedFilter matches feeds no sync holds at the given time
+func unclaimedFeedFilter(now time.Time) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"syncing_until": bson.M{"$exists": false}},
+		{"syncing_until": bson.M{"$lt": now}},
+	}}
+}
+
+func (r *MongoCalendarFeedRepository) claim(ctx context.Context, filter bson.M, leaseUntil time.Time, opts *options.FindOneAndUpdateOptions) (*domain.CalendarFeed, error) {
+	update := bson.M{"$set": bson.M{"sync_token": uuid.New(), "syncing_until": leaseUntil}}
+	opts.SetReturnDocument(options.After)
+
+	var feed domain.CalendarFeed
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&feed)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *MongoCalendarFeedRepository) SaveSync(ctx context.Context, feed *domain.CalendarFeed) error {
+	update := bson.M{
+		"$set": bson.M{
+			"last_synced_at": feed.LastSyncedAt,
+			"last_error":     feed.LastError,
+			"event_count":    feed.EventCount,
+			"skipped_events": feed.SkippedEvents,
+		},
+		"$unset": bson.M{"sync_token": "", "syncing_until": ""},
+	}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": feed.ID, "sync_token": feed.SyncToken}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrFeedClaimLost
+	}
+	return nil
+}
+
+func (r *MongoCalendarFeedRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrFeedNotFound
+	}
+	return nil
+}
+
+// MongoCategoryRepository implements CategoryRepository using MongoDB
+type MongoCategoryRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoCategoryRepository(db *mongo.Database) *MongoCategoryRepository {
+	return &MongoCategoryRepository{coll: db.Collection("categories")}
+}
+
+// EnsureIndexes keeps slugs unique
+func (r *MongoCategoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *MongoCategoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	_, err := r.coll.InsertOne(ctx, category)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrCategoryExists
+	}
+	return err
+}
+
+func (r *MongoCategoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	var category domain.Category
+	err := r.coll.FindOne(ctx, bson.M{"slug": slug}).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *MongoCategoryRepository) List(ctx context.Context) ([]*domain.Category, error) {
+	cursor, err := r.coll.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	categories := []*domain.Category{}
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *MongoCategoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	update := bson.M{
+		"$set": bson.M{
+			"name":       category.Name,
+			"parent":     category.Parent,
+			"ancestors":  category.Ancestors,
+			"is_active":  category.IsActive,
+			"sort_order": category.SortOrder,
+			"updated_at": category.UpdatedAt,
+		},
+	}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": category.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *MongoCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrCategoryNotFound
+	}
+	return nil
+}
+
+func (r *MongoCategoryRepository) MoveSubtree(ctx context.Context, slug string, oldAncestors, newAncestors []string) error {
+	update := []bson.M{{"$set": bson.M{"ancestors": movedPath("ancestors", oldAncestors, newAncestors)}}}
+	_, err := r.coll.UpdateMany(ctx, bson.M{"ancestors": slug}, update)
+	return err
+}
+
+// MongoSpecSchemaRepository implements SpecSchemaRepository using MongoDB
+type MongoSpecSchemaRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoSpecSchemaRepository(db *mongo.Database) *MongoSpecSchemaRepository {
+	return &MongoSpecSchemaRepository{coll: db.Collection("spec_schemas")}
+}
+
+// EnsureIndexes allows one schema per category and subcategory
+func (r *MongoSpecSchemaRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "category", Value: 1}, {Key: "subcategory", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+func (r *MongoSpecSchemaRepository) Create(ctx context.Context, schema *domain.SpecSchema) error {
+	_, err := r.coll.InsertOne(ctx, schema)
+	return err
+}
+
+func (r *MongoSpecSchemaRepository) Get(ctx context.Context, category domain.ItemCategory, subcategory string) (*domain.SpecSchema, error) {
+	var schema domain.SpecSchema
+	err := r.coll.FindOne(ctx, bson.M{"category": category, "subcategory": subcategory}).Decode(&schema)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrSchemaNotFound
+		}
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (r *MongoSpecSchemaRepository) List(ctx context.Context, category *domain.ItemCategory) ([]*domain.SpecSchema, error) {
+	filter := bson.M{}
+	if category != nil {
+		filter["category"] = *category
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "category", Value: 1}, {Key: "subcategory", Value: 1}})
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	schemas := []*domain.SpecSchema{}
+	if err := cursor.All(ctx, &schemas); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (r *MongoSpecSchemaRepository) Update(ctx context.Context, schema *domain.SpecSchema) error {
+	update := bson.M{
+		"$set": bson.M{
+			"attributes": schema.Attributes,
+			"updated_by": schema.UpdatedBy,
+			"updated_at": schema.UpdatedAt,
+		},
+	}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": schema.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrSchemaNotFound
+	}
+	return nil
+}
+
+func (r *MongoSpecSchemaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrSchemaNotFound
+	}
+	return nil
+}
+
+// MongoMaintenanceRepository implements MaintenanceRepository
+type MongoMaintenanceRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoMaintenanceRepository(db *mongo.Database) *MongoMaintenanceRepository {
+	return &MongoMaintenanceRepository{
+		coll: db.Collection("maintenance_logs"),
+	}
+}
+
+func (r *MongoMaintenanceRepository) Create(ctx context.Context, log *domain.MaintenanceLog) error {
+	_, err := r.coll.InsertOne(ctx, log)
+	return err
+}
+
+func (r *MongoMaintenanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.MaintenanceLog, error) {
+	var log domain.MaintenanceLog
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&log)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrMaintenanceNotFound
+		}
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (r *MongoMaintenanceRepository) GetByItem(ctx context.Context, itemID uuid.UUID, offset, limit int) ([]*domain.MaintenanceLog, int, error) {
+	filter := bson.M{"rental_item_id": itemID}
+
+	total, err := r.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []*domain.MaintenanceLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, 0, err
+	}
+
+	return logs, int(total), nil
+}
+
+func (r *MongoMaintenanceRepository) GetByUnit(ctx context.Context, itemID, unitID uuid.UUID, offset, limit int) ([]*domain.MaintenanceLog, int, error) {
+	filter := bson.M{"rental_item_id": itemID, "unit_id": unitID}
+
+	total, err := r.coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []*domain.MaintenanceLog
+	if err := cursor.All(ctx, &logs); err != nil {
+		return nil, 0, err
+	}
+
+	return logs, int(total), nil
+}
+
+func (r *MongoMaintenanceRepository) Update(ctx context.Context, log *domain.MaintenanceLog) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       log.Status,
+			"end_date":     log.EndDate,
+			"cost":         log.Cost,
+			"slot_id":      log.SlotID,
+			"started_at":   log.StartedAt,
+			"completed_at": log.CompletedAt,
+		},
+	}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": log.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrMaintenanceNotFound
+	}
+	return nil
+}
+
+func (r *MongoMaintenanceRepository) SumCosts(ctx context.Context, itemIDs []uuid.UUID) ([]domain.MaintenanceCostRow, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"rental_item_id": bson.M{"$in": itemIDs}}},
+		{"$group": bson.M{
+			"_id":   bson.M{"maintenance_type": "$maintenance_type", "status": "$status"},
+			"count": bson.M{"$sum": 1},
+			"cost":  bson.M{"$sum": "$cost"},
+		}},
+		{"$project": bson.M{
+			"_id":              0,
+			"maintenance_type": "$_id.maintenance_type",
+			"status":           "$_id.status",
+			"count":            1,
+			"cost":             1,
+		}},
+	}
+
+	cursor, err := r.coll.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []domain.MaintenanceCostRow
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *MongoMaintenanceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrMaintenanceNotFound
+	}
+	return nil
+}
+
+// MongoImportJobRepository implements ImportJobRepository using MongoDB
+type MongoImportJobRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoImportJobRepository(db *mongo.Database) *MongoImportJobRepository {
+	return &MongoImportJobRepository{
+		coll: db.Collection("import_jobs"),
+	}
+}
+
+// EnsureIndexes creates the indexes used to list an owner's jobs and to find
+// the next job to run
+func (r *MongoImportJobRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoImportJobRepository) Create(ctx context.Context, job *domain.ImportJob) error {
+	_, err := r.coll.InsertOne(ctx, job)
+	return err
+}
+
+func (r *MongoImportJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrImportNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *MongoImportJobRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID, limit int) ([]*domain.ImportJob, error) {
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"rows": 0})
+
+	cursor, err := r.coll.Find(ctx, bson.M{"owner_id": ownerID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*domain.ImportJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+func (r *MongoImportJobRepository) ClaimNext(ctx context.Context, staleBefore time.Time) (*domain.ImportJob, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"status": domain.ImportPending},
+			{"status": domain.ImportRunning, "heartbeat_at": bson.M{"$lt": staleBefore}},
+		},
+	}
+	now := time.Now()
+	update := []bson.M{{"$set": bson.M{
+		"status":       domain.ImportRunning,
+		"started_at":   bson.M{"$ifNull": bson.A{"$started_at", now}},
+		"heartbeat_at": now,
+		"claim_token":  uuid.New(),
+	}}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"created_at": 1}).
+		SetReturnDocument(options.After)
+
+	var job domain.ImportJob
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *MongoImportJobRepository) AddResult(ctx context.Context, jobID, claimToken uuid.UUID, result domain.ImportRowResult) error {
+	counter := "failed"
+	if result.OK() {
+		counter = "succeeded"
+	}
+	update := bson.M{
+		"$push": bson.M{"results": result},
+		"$inc":  bson.M{counter: 1},
+		"$set":  bson.M{"heartbeat_at": time.Now()},
+	}
+	return r.updateClaimed(ctx, jobID, claimToken, update)
+}
+
+func (r *MongoImportJobRepository) Finish(ctx context.Context, jobID, claimToken uuid.UUID) error {
+	update := bson.M{
+		"$set":   bson.M{"status": domain.ImportCompleted, "finished_at": time.Now()},
+		"$unset": bson.M{"rows": ""},
+	}
+	return r.updateClaimed(ctx, jobID, claimToken, update)
+}
+
+// updateClaimed updates a running job only while it is still held under the
+// given claim token
+func (r *MongoImportJobRepository) updateClaimed(ctx context.Context, jobID, claimToken uuid.UUID, update bson.M) error {
+	filter := bson.M{"_id": jobID, "status": domain.ImportRunning, "claim_token": claimToken}
+	res, err := r.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrImportClaimLost
+	}
+	return nil
+}
+
+// MongoMaintenancePlanRepository implements MaintenancePlanRepository using MongoDB
+type MongoMaintenancePlanRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMongoMaintenancePlanRepository(db *mongo.Database) *MongoMaintenancePlanRepository {
+	return &MongoMaintenancePlanRepository{
+		coll: db.Collection("maintenance_plans"),
+	}
+}
+
+// EnsureIndexes creates the indexes used to list an item's plans and to find
+// the next plan to check
+func (r *MongoMaintenancePlanRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "rental_item_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "last_checked_at", Value: 1}}},
+	})
+	return err
+}
+
+func (r *MongoMaintenancePlanRepository) Create(ctx context.Context, plan *domain.MaintenancePlan) error {
+	_, err := r.coll.InsertOne(ctx, plan)
+	return err
+}
+
+func (r *MongoMaintenancePlanRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.MaintenancePlan, error) {
+	var plan domain.MaintenancePlan
+	err := r.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&plan)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, domain.ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *MongoMaintenancePlanRepository) GetByItem(ctx context.Context, itemID uuid.UUID) ([]*domain.MaintenancePlan, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+
+	cursor, err := r.coll.Find(ctx, bson.M{"rental_item_id": itemID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var plans []*domain.MaintenancePlan
+	if err := cursor.All(ctx, &plans); err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+func (r *MongoMaintenancePlanRepository) ClaimDue(ctx context.Context, before, leaseUntil time.Time) (*domain.MaintenancePlan, error) {
+	filter := bson.M{
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"last_checked_at": bson.M{"$exists": false}},
+				{"last_checked_at": bson.M{"$lt": before}},
+			}},
+			unclaimedPlanFilter(time.Now()),
+		},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"last_checked_at": 1})
+	return r.claim(ctx, filter, leaseUntil, opts)
+}
+
+func (r *MongoMaintenancePlanRepository) Claim(ctx context.Context, id uuid.UUID, leaseUntil time.Time) (*domain.MaintenancePlan, error) {
+	filter := bson.M{"$and": []bson.M{{"_id": id}, unclaimedPlanFilter(time.Now())}}
+	return r.claim(ctx, filter, leaseUntil, options.FindOneAndUpdate())
+}
+
+// unclaimedPlanFilter matches plans no check holds at the given time
+func unclaimedPlanFilter(now time.Time) bson.M {
+	return bson.M{"$or": []bson.M{
+		{"checking_until": bson.M{"$exists": false}},
+		{"checking_until": bson.M{"$lt": now}},
+	}}
+}
+
+func (r *MongoMaintenancePlanRepository) claim(ctx context.Context, filter bson.M, leaseUntil time.Time, opts *options.FindOneAndUpdateOptions) (*domain.MaintenancePlan, error) {
+	update := bson.M{"$set": bson.M{"check_token": uuid.New(), "checking_until": leaseUntil}}
+	opts.SetReturnDocument(options.After)
+
+	var plan domain.MaintenancePlan
+	err := r.coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&plan)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *MongoMaintenancePlanRepository) SaveCheck(ctx context.Context, plan *domain.MaintenancePlan) error {
+	update := bson.M{
+		"$set": bson.M{
+			"last_serviced_at": plan.LastServicedAt,
+			"serviced_units":   plan.ServicedUnits,
+			"pending_log_id":   plan.PendingLogID,
+			"last_checked_at":  plan.LastCheckedAt,
+			"last_error":       plan.LastError,
+		},
+		"$unset": bson.M{"check_token": "", "checking_until": ""},
+	}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": plan.ID, "check_token": plan.CheckToken}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrPlanClaimLost
+	}
+	return nil
+}
+
+func (r *MongoMaintenancePlanRepository) Update(ctx context.Context, plan *domain.MaintenancePlan) error {
+	settings := plan.MaintenancePlanSettings
+	update := bson.M{"$set": bson.M{
+		"maintenance_type": settings.MaintenanceType,
+		"description":      settings.Description,
+		"interval_months":  settings.IntervalMonths,
+		"usage_days":       settings.UsageDays,
+		"estimated_cost":   settings.EstimatedCost,
+		"duration_days":    settings.DurationDays,
+		"lead_days":        settings.LeadDays,
+		"is_active":        settings.IsActive,
+		"updated_at":       plan.UpdatedAt,
+	}}
+	result, err := r.coll.UpdateOne(ctx, bson.M{"_id": plan.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrPlanNotFound
+	}
+	return nil
+}
+
+func (r *MongoMaintenancePlanRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrPlanNotFound
 	}
 	return nil
 }
@@ -0,0 +1,416 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// textWeights rank a match in the title above one in the description
+var textWeights = bson.D{
+	{Key: "title", Value: 10},
+	{Key: "subcategory", Value: 5},
+	{Key: "city", Value: 3},
+	{Key: "description", Value: 1},
+}
+
+// priceBandLimits are the upper bounds of the daily rate facet bands; the
+// last band is open-ended
+var priceBandLimits = []float64{50, 100, 250, 500, 1000}
+
+const (
+	// facetLimit caps how many values the subcategory and city facets list
+	facetLimit = 50
+
+	// Words shorter than minTermLength are neither indexed nor corrected
+	minTermLength = 3
+
+	// vocabularyCandidates caps how many known words a misspelling is compared with
+	vocabularyCandidates = 5000
+
+	earthRadiusKm = 6378.1
+)
+
+func (r *MongoItemRepository) ensureSearchIndexes(ctx context.Context) error {
+	keys := bson.D{}
+	weights := bson.D{}
+	for _, field := range textWeights {
+		keys = append(keys, bson.E{Key: field.Key, Value: "text"})
+		weights = append(weights, field)
+	}
+
+	_, err := r.coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: keys,
+		Options: options.Index().
+			SetName("item_text").
+			SetWeights(weights).
+			SetDefaultLanguage("english"),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.terms.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "first", Value: 1}, {Key: "length", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Build the vocabulary from items saved before it existed
+	known, err := r.terms.EstimatedDocumentCount(ctx)
+	if err != nil || known > 0 {
+		return err
+	}
+	cursor, err := r.coll.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var item domain.RentalItem
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+		if err := r.indexTerms(ctx, &item); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// indexTerms adds the item's words to the vocabulary that misspelled queries
+// are corrected against. Words are never removed; a stale one only means a
+// correction that finds nothing.
+func (r *MongoItemRepository) indexTerms(ctx context.Context, item *domain.RentalItem) error {
+	words := searchWords(item.Title + " " + item.Subcategory + " " + item.City + " " + item.Description)
+	if len(words) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(words))
+	for _, word := range words {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": word}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{"first": firstLetter(word), "length": utf8.RuneCountInString(word)}}).
+			SetUpsert(true))
+	}
+	_, err := r.terms.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (r *MongoItemRepository) Search(ctx context.Context, query string, filters ItemFilters, offset, limit int) (*SearchResult, error) {
+	result := &SearchResult{Items: []*domain.RentalItem{}}
+
+	filter := itemFilter(filters)
+	words := searchWords(query)
+	textSearch := len(words) > 0
+
+	var stages []bson.M
+	if textSearch {
+		corrections, err := r.correctWords(ctx, words)
+		if err != nil {
+			return nil, err
+		}
+		if len(corrections) > 0 {
+			corrected := make([]string, len(words))
+			for i, word := range words {
+				corrected[i] = word
+				if c, ok := corrections[word]; ok {
+					corrected[i] = c
+				}
+			}
+			result.CorrectedQuery = strings.Join(corrected, " ")
+		}
+
+		// Search for the words as typed and as corrected; a document
+		// matching either ranks by how well it matches
+		terms := append([]string{}, words...)
+		for _, c := range corrections {
+			terms = append(terms, c)
+		}
+		filter["$text"] = bson.M{"$search": strings.Join(terms, " ")}
+
+		// $geoNear can't be combined with $text, so a radius becomes a plain
+		// containment filter and distances are worked out afterwards
+		if near := filters.Near; near != nil {
+			filter["location"] = bson.M{"$geoWithin": bson.M{
+				"$centerSphere": bson.A{bson.A{near.Longitude, near.Latitude}, near.RadiusKm / earthRadiusKm},
+			}}
+		}
+
+		stages = []bson.M{
+			{"$match": filter},
+			{"$addFields": bson.M{"relevance": bson.M{"$meta": "textScore"}}},
+		}
+	} else if filters.HasGeo() {
+		stages = []bson.M{{"$geoNear": geoNearStage(filter, filters)}}
+	} else {
+		stages = []bson.M{{"$match": filter}}
+	}
+	stages = append(stages, availabilityStages(filters)...)
+
+	order := itemSort(filters)
+	switch {
+	case order != nil:
+	case textSearch:
+		order = bson.M{"relevance": -1}
+	case !filters.HasGeo():
+		order = bson.M{"created_at": -1}
+	}
+	page := []bson.M{}
+	if order != nil {
+		page = append(page, bson.M{"$sort": order})
+	}
+	page = append(page, bson.M{"$skip": offset}, bson.M{"$limit": limit})
+
+	stages = append(stages, bson.M{"$facet": bson.M{
+		"items":         page,
+		"total":         []bson.M{{"$count": "count"}},
+		"categories":    []bson.M{{"$sortByCount": "$category"}},
+		"subcategories": subcategoryFacet(),
+		"cities":        valueFacet("city"),
+		"price_bands": []bson.M{
+			{"$group": bson.M{"_id": priceBandExpr(), "count": bson.M{"$sum": 1}}},
+		},
+	}})
+
+	cursor, err := r.coll.Aggregate(ctx, stages)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var pages []struct {
+		Items []*domain.RentalItem `bson:"items"`
+		Total []struct {
+			Count int `bson:"count"`
+		} `bson:"total"`
+		SearchFacets `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &pages); err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 || len(pages[0].Total) == 0 {
+		result.Facets = emptyFacets()
+		return result, nil
+	}
+
+	result.Items = pages[0].Items
+	result.Total = pages[0].Total[0].Count
+	result.Facets = emptyFacets()
+	result.Facets.Categories = append(result.Facets.Categories, pages[0].Categories...)
+	result.Facets.Subcategories = append(result.Facets.Subcategories, pages[0].Subcategories...)
+	result.Facets.Cities = append(result.Facets.Cities, pages[0].Cities...)
+	result.Facets.PriceBands = append(result.Facets.PriceBands, pages[0].PriceBands...)
+	sortPriceBands(result.Facets.PriceBands)
+
+	if textSearch && filters.HasGeo() {
+		setDistances(result.Items, filters)
+	}
+	return result, nil
+}
+
+func valueFacet(field string) []bson.M {
+	return []bson.M{
+		{"$match": bson.M{field: bson.M{"$nin": bson.A{"", nil}}}},
+		{"$sortByCount": "$" + field},
+		{"$limit": facetLimit},
+	}
+}
+
+// subcategoryFacet counts items under each category below the root on their
+// path, so a parent's count includes its descendants'
+func subcategoryFacet() []bson.M {
+	return []bson.M{
+		{"$project": bson.M{"path": bson.M{"$slice": bson.A{"$category_path", 1, maxCategoryDepth}}}},
+		{"$unwind": "$path"},
+		{"$sortByCount": "$path"},
+		{"$limit": facetLimit},
+	}
+}
+
+func emptyFacets() SearchFacets {
+	return SearchFacets{
+		Categories:    []FacetCount{},
+		Subcategories: []FacetCount{},
+		Cities:        []FacetCount{},
+		PriceBands:    []FacetCount{},
+	}
+}
+
+// priceBandExpr labels an item with its daily rate band, such as "50-100"
+func priceBandExpr() bson.M {
+	branches := bson.A{}
+	lower := 0.0
+	for _, upper := range priceBandLimits {
+		branches = append(branches, bson.M{
+			"case": bson.M{"$lt": bson.A{"$daily_rate", upper}},
+			"then": priceBandLabel(lower, upper),
+		})
+		lower = upper
+	}
+	return bson.M{"$switch": bson.M{
+		"branches": branches,
+		"default":  fmt.Sprintf("%g+", lower),
+	}}
+}
+
+func priceBandLabel(lower, upper float64) string {
+	return fmt.Sprintf("%g-%g", lower, upper)
+}
+
+// sortPriceBands puts the bands in price order
+func sortPriceBands(bands []FacetCount) {
+	order := map[string]int{}
+	lower := 0.0
+	for i, upper := range priceBandLimits {
+		order[priceBandLabel(lower, upper)] = i
+		lower = upper
+	}
+	order[fmt.Sprintf("%g+", lower)] = len(priceBandLimits)
+
+	sort.Slice(bands, func(i, j int) bool {
+		return order[bands[i].Value] < order[bands[j].Value]
+	})
+}
+
+// correctWords maps each query word that isn't in the vocabulary to the
+// closest word that is, if one is close enough to be a likely typo
+func (r *MongoItemRepository) correctWords(ctx context.Context, words []string) (map[string]string, error) {
+	corrections := map[string]string{}
+	for _, word := range words {
+		length := utf8.RuneCountInString(word)
+		if _, done := corrections[word]; done || length < minTermLength+1 {
+			continue
+		}
+
+		known, err := r.terms.CountDocuments(ctx, bson.M{"_id": word})
+		if err != nil {
+			return nil, err
+		}
+		if known > 0 {
+			continue
+		}
+
+		maxEdits := 1
+		if length >= 8 {
+			maxEdits = 2
+		}
+		filter := bson.M{
+			"first":  firstLetter(word),
+			"length": bson.M{"$gte": length - maxEdits, "$lte": length + maxEdits},
+		}
+		cursor, err := r.terms.Find(ctx, filter, options.Find().SetLimit(vocabularyCandidates))
+		if err != nil {
+			return nil, err
+		}
+		var candidates []struct {
+			Word string `bson:"_id"`
+		}
+		err = cursor.All(ctx, &candidates)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		best, bestEdits := "", maxEdits+1
+		for _, candidate := range candidates {
+			edits := editDistance(word, candidate.Word)
+			if edits < bestEdits || (edits == bestEdits && candidate.Word < best) {
+				best, bestEdits = candidate.Word, edits
+			}
+		}
+		if best != "" {
+			corrections[word] = best
+		}
+	}
+	return corrections, nil
+}
+
+// searchWords splits text into distinct lowercase words
+func searchWords(text string) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	}) {
+		if utf8.RuneCountInString(word) < minTermLength || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words
+}
+
+func firstLetter(word string) string {
+	r, _ := utf8.DecodeRuneInString(word)
+	return string(r)
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and swaps of adjacent letters
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(t)]
+}
+
+// setDistances fills in each item's distance from the search point or the
+// centre of the search box
+func setDistances(items []*domain.RentalItem, filters ItemFilters) {
+	var latitude, longitude float64
+	if filters.Near != nil {
+		latitude, longitude = filters.Near.Latitude, filters.Near.Longitude
+	} else {
+		latitude, longitude = filters.Within.Center()
+	}
+
+	for _, item := range items {
+		if item.GeoLocation == nil {
+			continue
+		}
+		distance := haversineKm(latitude, longitude, item.Latitude, item.Longitude)
+		item.DistanceKm = &distance
+	}
+}
+
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
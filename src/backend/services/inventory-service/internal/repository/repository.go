@@ -14,19 +14,76 @@ type ItemRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.RentalItem, error)
 	GetByOwner(ctx context.Context, ownerID uuid.UUID, offset, limit int) ([]*domain.RentalItem, int, error)
 	List(ctx context.Context, offset, limit int, filters ItemFilters) ([]*domain.RentalItem, int, error)
-	Search(ctx context.Context, query string, filters ItemFilters, offset, limit int) ([]*domain.RentalItem, int, error)
+	// Search ranks items by relevance to the query, or lists them if the query
+	// is empty, and counts the matches per facet
+	Search(ctx context.Context, query string, filters ItemFilters, offset, limit int) (*SearchResult, error)
+	GetFeatured(ctx context.Context, limit int) ([]*domain.RentalItem, error)
+	// Update saves the item, failing with ErrItemChanged if it was saved since
+	// it was read
 	Update(ctx context.Context, item *domain.RentalItem) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// CountInCategory counts the items in a category or any of its descendants
+	CountInCategory(ctx context.Context, slug string) (int, error)
+	// MoveCategory rewrites the category paths of items in the subtree under
+	// slug after it moves from oldAncestors to newAncestors
+	MoveCategory(ctx context.Context, slug string, oldAncestors, newAncestors []string) error
 }
 
 // ItemFilters defines filters for listing items
 type ItemFilters struct {
+	// Category matches items in the category or any of its descendants
 	Category *domain.ItemCategory
 	City     *string
 	MinPrice *float64
 	MaxPrice *float64
 	IsActive *bool
 	SortBy   *string
+
+	// At most one of Near and Within; either sorts results by distance
+	// unless SortBy says otherwise
+	Near   *domain.GeoRadius
+	Within *domain.BoundingBox
+
+	// AvailableFrom and AvailableTo keep only items that can be rented over
+	// the whole range under their owner's rules and aren't fully reserved
+	// at any point in it
+	AvailableFrom *time.Time
+	AvailableTo   *time.Time
+
+	// Attributes compare typed specifications, such as seats >= 5
+	Attributes []domain.AttributeFilter
+}
+
+// HasGeo reports whether the filters restrict items by location
+func (f ItemFilters) HasGeo() bool {
+	return f.Near != nil || f.Within != nil
+}
+
+// SearchResult is a page of search results with facet counts over all matches
+type SearchResult struct {
+	Items  []*domain.RentalItem `json:"items"`
+	Total  int                  `json:"total"`
+	Facets SearchFacets         `json:"facets"`
+	// CorrectedQuery is the query with misspelled words replaced, if any were
+	CorrectedQuery string `json:"corrected_query,omitempty"`
+}
+
+// SearchFacets counts matching items by attribute. Categories counts by root
+// category, and Subcategories counts each item under every category below the
+// root on its path.
+type SearchFacets struct {
+	Categories    []FacetCount `json:"categories" bson:"categories"`
+	Subcategories []FacetCount `json:"subcategories" bson:"subcategories"`
+	Cities        []FacetCount `json:"cities" bson:"cities"`
+	PriceBands    []FacetCount `json:"price_bands" bson:"price_bands"`
+}
+
+// FacetCount is the number of matching items with a facet value. Category
+// facets are labelled with the category's display name.
+type FacetCount struct {
+	Value string `json:"value" bson:"_id"`
+	Label string `json:"label,omitempty" bson:"-"`
+	Count int    `json:"count" bson:"count"`
 }
 
 // AvailabilityRepository defines the interface for availability slot data access
@@ -34,9 +91,74 @@ type AvailabilityRepository interface {
 	Create(ctx context.Context, slot *domain.AvailabilitySlot) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.AvailabilitySlot, error)
 	GetByItem(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time) ([]*domain.AvailabilitySlot, error)
+	GetByBooking(ctx context.Context, bookingID uuid.UUID) (*domain.AvailabilitySlot, error)
 	Update(ctx context.Context, slot *domain.AvailabilitySlot) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	CheckConflict(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, excludeSlotID *uuid.UUID) (bool, error)
+	DeleteByBooking(ctx context.Context, bookingID uuid.UUID) error
+	// ConfirmByBooking marks the booking's slot confirmed, returning
+	// domain.ErrSlotNotFound if the booking holds none
+	ConfirmByBooking(ctx context.Context, bookingID uuid.UUID) error
+	// CheckConflict reports whether another reservation over the range would
+	// take the item past capacity, the number of reservations it can hold at once
+	CheckConflict(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, excludeSlotID *uuid.UUID, capacity int) (bool, error)
+	// Reserve inserts the slot only if it fits within the item's capacity, and
+	// its unit is free if it has one, returning domain.ErrDateConflict
+	// otherwise. Its add-ons must be in stock too, or it returns
+	// domain.ErrAddOnUnavailable.
+	Reserve(ctx context.Context, slot *domain.AvailabilitySlot, limits domain.ReservationLimits) error
+	Reschedule(ctx context.Context, slot *domain.AvailabilitySlot, startDate, endDate time.Time, limits domain.ReservationLimits) error
+	// AssignUnit gives the slot the first of the units free over its dates,
+	// returning domain.ErrNoFreeUnit if none are
+	AssignUnit(ctx context.Context, slot *domain.AvailabilitySlot, units []domain.ItemUnit) error
+	// GetOverlapping returns the item's slots that overlap the range at all
+	GetOverlapping(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time) ([]*domain.AvailabilitySlot, error)
+	// ReplaceFeedSlots swaps the slots imported from a feed for a new set. It
+	// fails with ErrFeedClaimLost, leaving no new slots behind, if the feed was
+	// removed or is no longer held under the given sync token.
+	ReplaceFeedSlots(ctx context.Context, feedID, syncToken uuid.UUID, slots []*domain.AvailabilitySlot) error
+	DeleteByFeed(ctx context.Context, feedID uuid.UUID) error
+}
+
+// CalendarFeedRepository defines the interface for imported calendar feed data access
+type CalendarFeedRepository interface {
+	Create(ctx context.Context, feed *domain.CalendarFeed) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.CalendarFeed, error)
+	GetByItem(ctx context.Context, itemID uuid.UUID) ([]*domain.CalendarFeed, error)
+	// ClaimDue claims the feed longest without a sync, if it was never synced
+	// or last synced before the given time, holding it under a new sync token
+	// until leaseUntil. It returns nil if there is none.
+	ClaimDue(ctx context.Context, before, leaseUntil time.Time) (*domain.CalendarFeed, error)
+	// Claim claims the feed the same way unless another sync holds it, in
+	// which case it returns nil
+	Claim(ctx context.Context, id uuid.UUID, leaseUntil time.Time) (*domain.CalendarFeed, error)
+	// SaveSync stores the outcome of a sync and releases the claim. It fails
+	// with ErrFeedClaimLost if the feed was removed or claimed again meanwhile.
+	SaveSync(ctx context.Context, feed *domain.CalendarFeed) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// CategoryRepository defines the interface for category tree data access
+type CategoryRepository interface {
+	Create(ctx context.Context, category *domain.Category) error
+	GetBySlug(ctx context.Context, slug string) (*domain.Category, error)
+	// List returns every category, active or not
+	List(ctx context.Context) ([]*domain.Category, error)
+	Update(ctx context.Context, category *domain.Category) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// MoveSubtree rewrites the ancestors of the categories under slug after it
+	// moves from oldAncestors to newAncestors
+	MoveSubtree(ctx context.Context, slug string, oldAncestors, newAncestors []string) error
+}
+
+// SpecSchemaRepository defines the interface for specification schema data access
+type SpecSchemaRepository interface {
+	Create(ctx context.Context, schema *domain.SpecSchema) error
+	// Get finds the schema for a category, or one of its subcategories
+	Get(ctx context.Context, category domain.ItemCategory, subcategory string) (*domain.SpecSchema, error)
+	// List returns the schemas for a category, or all of them if category is nil
+	List(ctx context.Context, category *domain.ItemCategory) ([]*domain.SpecSchema, error)
+	Update(ctx context.Context, schema *domain.SpecSchema) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 // MaintenanceRepository defines the interface for maintenance log data access
@@ -44,6 +166,48 @@ type MaintenanceRepository interface {
 	Create(ctx context.Context, log *domain.MaintenanceLog) error
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.MaintenanceLog, error)
 	GetByItem(ctx context.Context, itemID uuid.UUID, offset, limit int) ([]*domain.MaintenanceLog, int, error)
+	GetByUnit(ctx context.Context, itemID, unitID uuid.UUID, offset, limit int) ([]*domain.MaintenanceLog, int, error)
 	Update(ctx context.Context, log *domain.MaintenanceLog) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// SumCosts counts and totals the cost of the items' maintenance by type
+	// and status
+	SumCosts(ctx context.Context, itemIDs []uuid.UUID) ([]domain.MaintenanceCostRow, error)
+}
+
+// ImportJobRepository defines the interface for bulk import job data access
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *domain.ImportJob) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.ImportJob, error)
+	// GetByOwner returns the owner's most recent jobs, without their rows
+	GetByOwner(ctx context.Context, ownerID uuid.UUID, limit int) ([]*domain.ImportJob, error)
+	// ClaimNext marks the oldest pending job, or a running job whose heartbeat
+	// is older than staleBefore, as running under a new claim token and
+	// returns it, or nil if there is none
+	ClaimNext(ctx context.Context, staleBefore time.Time) (*domain.ImportJob, error)
+	// AddResult saves a row's outcome and refreshes the job's heartbeat. It
+	// fails with ErrImportClaimLost once the job has been claimed again.
+	AddResult(ctx context.Context, jobID, claimToken uuid.UUID, result domain.ImportRowResult) error
+	// Finish marks the job completed and drops its rows, under the same
+	// claim check as AddResult
+	Finish(ctx context.Context, jobID, claimToken uuid.UUID) error
+}
+
+// MaintenancePlanRepository defines the interface for preventive maintenance plan data access
+type MaintenancePlanRepository interface {
+	Create(ctx context.Context, plan *domain.MaintenancePlan) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.MaintenancePlan, error)
+	GetByItem(ctx context.Context, itemID uuid.UUID) ([]*domain.MaintenancePlan, error)
+	// ClaimDue claims the plan longest without a check, if it was never
+	// checked or last checked before the given time, holding it under a new
+	// check token until leaseUntil. It returns nil if there is none.
+	ClaimDue(ctx context.Context, before, leaseUntil time.Time) (*domain.MaintenancePlan, error)
+	// Claim claims the plan the same way unless another check holds it, in
+	// which case it returns nil
+	Claim(ctx context.Context, id uuid.UUID, leaseUntil time.Time) (*domain.MaintenancePlan, error)
+	// SaveCheck stores the outcome of a check and releases the claim. It fails
+	// with ErrPlanClaimLost if the plan was claimed again meanwhile.
+	SaveCheck(ctx context.Context, plan *domain.MaintenancePlan) error
+	// Update stores the owner's settings, leaving the scheduler's fields alone
+	Update(ctx context.Context, plan *domain.MaintenancePlan) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+)
+
+// AddAddOn offers a new extra or bundle with the owner's item
+func (s *InventoryService) AddAddOn(ctx context.Context, itemID, ownerID uuid.UUID, settings domain.AddOnSettings) (*domain.AddOn, error) {
+	var addOn *domain.AddOn
+	_, err := s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) (err error) {
+		addOn, err = item.AddAddOn(settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addOn, nil
+}
+
+// UpdateAddOn replaces the settings of one of the item's add-ons. Lowering
+// its stock only limits bookings made from now on.
+func (s *InventoryService) UpdateAddOn(ctx context.Context, itemID, ownerID, addOnID uuid.UUID, settings domain.AddOnSettings) (*domain.AddOn, error) {
+	var addOn *domain.AddOn
+	_, err := s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) (err error) {
+		addOn, err = item.UpdateAddOn(addOnID, settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addOn, nil
+}
+
+// RemoveAddOn stops offering one of the item's add-ons
+func (s *InventoryService) RemoveAddOn(ctx context.Context, itemID, ownerID, addOnID uuid.UUID) (*domain.RentalItem, error) {
+	return s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) error {
+		return item.RemoveAddOn(addOnID)
+	})
+}
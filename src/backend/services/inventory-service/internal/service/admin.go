@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/clients"
+	"github.com/rentalflow/inventory-service/internal/domain"
+)
+
+// requireAdmin lets only platform admins through
+func requireAdmin(ctx context.Context, userClient *clients.UserClient, userID uuid.UUID) error {
+	user, err := userClient.GetUser(ctx, userID)
+	if err != nil {
+		if err == domain.ErrUserNotFound {
+			return domain.ErrUnauthorized
+		}
+		return err
+	}
+	if !user.IsAdmin() {
+		return domain.ErrUnauthorized
+	}
+	return nil
+}
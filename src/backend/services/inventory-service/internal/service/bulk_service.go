@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/bulk"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+// Export datasets
+const (
+	DatasetItems        = "items"
+	DatasetMaintenance  = "maintenance"
+	DatasetAvailability = "availability"
+)
+
+// importJobListLimit caps how many of an owner's jobs are listed
+const importJobListLimit = 50
+
+// exportPageSize is how many records are read per query while exporting
+const exportPageSize = 200
+
+// BulkService imports items in bulk and exports an owner's catalogue
+type BulkService struct {
+	inventoryService *InventoryService
+	jobRepo          repository.ImportJobRepository
+	itemRepo         repository.ItemRepository
+	availabilityRepo repository.AvailabilityRepository
+	maintenanceRepo  repository.MaintenanceRepository
+}
+
+// NewBulkService creates a new bulk service
+func NewBulkService(
+	inventoryService *InventoryService,
+	jobRepo repository.ImportJobRepository,
+	itemRepo repository.ItemRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	maintenanceRepo repository.MaintenanceRepository,
+) *BulkService {
+	return &BulkService{
+		inventoryService: inventoryService,
+		jobRepo:          jobRepo,
+		itemRepo:         itemRepo,
+		availabilityRepo: availabilityRepo,
+		maintenanceRepo:  maintenanceRepo,
+	}
+}
+
+// StartImport reads the file and queues its rows to be imported as the
+// owner's items. Files that can't be read at all are rejected straight away;
+// problems with single rows are reported in the job's results.
+func (s *BulkService) StartImport(ctx context.Context, ownerID uuid.UUID, format domain.ImportFormat, r io.Reader) (*domain.ImportJob, error) {
+	if !format.IsValid() {
+		return nil, domain.ErrInvalidImportFormat
+	}
+	rows, err := bulk.ParseItems(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	job := domain.NewImportJob(ownerID, format, rows)
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// GetImport returns one of the owner's import jobs
+func (s *BulkService) GetImport(ctx context.Context, jobID, ownerID uuid.UUID) (*domain.ImportJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+	return job, nil
+}
+
+// ListImports returns the owner's most recent import jobs
+func (s *BulkService) ListImports(ctx context.Context, ownerID uuid.UUID) ([]*domain.ImportJob, error) {
+	return s.jobRepo.GetByOwner(ctx, ownerID, importJobListLimit)
+}
+
+// runImport imports the job's remaining rows, saving each result as it goes
+func (s *BulkService) runImport(ctx context.Context, job *domain.ImportJob) error {
+	for _, row := range job.Remaining() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result := s.importRow(ctx, job, row)
+		if err := s.jobRepo.AddResult(ctx, job.ID, job.ClaimToken, result); err != nil {
+			return err
+		}
+	}
+	return s.jobRepo.Finish(ctx, job.ID, job.ClaimToken)
+}
+
+// importRow creates the row's item, going through the same checks as
+// creating an item by hand. The item ID comes from the job and line, so a
+// row run again after an interrupted run finds its item and counts it as
+// imported.
+func (s *BulkService) importRow(ctx context.Context, job *domain.ImportJob, row domain.ImportRow) domain.ImportRowResult {
+	result := domain.ImportRowResult{Line: row.Line}
+	if len(row.Problems) > 0 {
+		result.Errors = row.Problems
+		return result
+	}
+
+	in := row.Item
+	location := domain.Location{
+		Address:   in.Address,
+		City:      in.City,
+		Latitude:  in.Latitude,
+		Longitude: in.Longitude,
+	}
+	itemID := job.RowItemID(row.Line)
+	_, err := s.inventoryService.createItem(
+		ctx, itemID, job.OwnerID, in.Title, in.Description,
+		domain.ItemCategory(in.Category), in.Subcategory,
+		in.DailyRate, in.WeeklyRate, in.MonthlyRate, in.SecurityDeposit,
+		location, in.Specifications, in.Images,
+	)
+	if err != nil && err != domain.ErrItemExists {
+		var specErr *domain.SpecificationError
+		if errors.As(err, &specErr) {
+			result.Errors = specErr.Problems
+		} else {
+			result.Errors = []string{err.Error()}
+		}
+		return result
+	}
+	result.ItemID = &itemID
+	return result
+}
+
+// ExportRange is the span of availability slots exported
+type ExportRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// Export writes the owner's items, or the maintenance logs or availability
+// slots of all their items, in the given format. Availability defaults to
+// the bookable window from today when no range is given.
+func (s *BulkService) Export(ctx context.Context, w io.Writer, ownerID uuid.UUID, format domain.ImportFormat, dataset string, span *ExportRange) error {
+	if !format.IsValid() {
+		return domain.ErrInvalidImportFormat
+	}
+	switch dataset {
+	case DatasetItems, DatasetMaintenance, DatasetAvailability:
+	default:
+		return domain.ErrInvalidDataset
+	}
+
+	items, err := allOwnerItems(ctx, s.itemRepo, ownerID)
+	if err != nil {
+		return err
+	}
+	titles := make(map[uuid.UUID]string, len(items))
+	for _, item := range items {
+		titles[item.ID] = item.Title
+	}
+
+	switch dataset {
+	case DatasetMaintenance:
+		var logs []*domain.MaintenanceLog
+		for _, item := range items {
+			for offset := 0; ; offset += exportPageSize {
+				page, total, err := s.maintenanceRepo.GetByItem(ctx, item.ID, offset, exportPageSize)
+				if err != nil {
+					return err
+				}
+				logs = append(logs, page...)
+				if len(page) == 0 || offset+len(page) >= total {
+					break
+				}
+			}
+		}
+		return bulk.WriteMaintenance(w, format, logs, titles)
+
+	case DatasetAvailability:
+		if span == nil {
+			from := time.Now().Truncate(24 * time.Hour)
+			span = &ExportRange{From: from, To: from.AddDate(0, 0, domain.MaxCalendarDays)}
+		}
+		if !span.To.After(span.From) {
+			return domain.ErrInvalidDateRange
+		}
+		if span.To.Sub(span.From) > domain.MaxCalendarDays*24*time.Hour {
+			return domain.ErrRangeTooLong
+		}
+		var slots []*domain.AvailabilitySlot
+		for _, item := range items {
+			page, err := s.availabilityRepo.GetOverlapping(ctx, item.ID, span.From, span.To)
+			if err != nil {
+				return err
+			}
+			slots = append(slots, page...)
+		}
+		return bulk.WriteAvailability(w, format, slots, titles)
+
+	default:
+		return bulk.WriteItems(w, format, items)
+	}
+}
+
+// allOwnerItems reads all of the owner's items, active or not
+func allOwnerItems(ctx context.Context, itemRepo repository.ItemRepository, ownerID uuid.UUID) ([]*domain.RentalItem, error) {
+	var items []*domain.RentalItem
+	for offset := 0; ; offset += exportPageSize {
+		page, total, err := itemRepo.GetByOwner(ctx, ownerID, offset, exportPageSize)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+		if len(page) == 0 || offset+len(page) >= total {
+			return items, nil
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/ical"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+const (
+	calendarProductID = "-//RentalFlow//Availability//EN"
+
+	// Exported calendars cover this much history and future
+	exportLookBack  = 30 * 24 * time.Hour
+	exportLookAhead = 2 * 365 * 24 * time.Hour
+
+	// Limits on what we accept from an external feed
+	maxFeedSize      = 5 << 20
+	maxFeedEvents    = 2000
+	maxFeedRedirects = 5
+	feedTimeout      = 30 * time.Second
+
+	// feedSyncLease is how long a sync holds its feed, well past the time a
+	// feed can take to download
+	feedSyncLease = 5 * time.Minute
+
+	// maxSkippedEvents bounds how many unreadable events a sync reports
+	maxSkippedEvents = 20
+)
+
+// Reasons a feed couldn't be synced, as recorded on the feed. They don't
+// repeat what the remote server said, so a feed can't be used to probe it.
+var (
+	errFeedUnreachable = errors.New("feed could not be downloaded")
+	errFeedResponse    = errors.New("feed server did not return a calendar")
+	errFeedInvalid     = errors.New("feed is not a valid iCalendar document")
+	errFeedRedirects   = errors.New("feed redirected too many times")
+)
+
+// CalendarService publishes item availability as iCalendar feeds and imports
+// external feeds as blocked dates
+type CalendarService struct {
+	itemRepo         repository.ItemRepository
+	availabilityRepo repository.AvailabilityRepository
+	feedRepo         repository.CalendarFeedRepository
+	httpClient       *http.Client
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(
+	itemRepo repository.ItemRepository,
+	availabilityRepo repository.AvailabilityRepository,
+	feedRepo repository.CalendarFeedRepository,
+) *CalendarService {
+	return &CalendarService{
+		itemRepo:         itemRepo,
+		availabilityRepo: availabilityRepo,
+		feedRepo:         feedRepo,
+		httpClient:       newFeedClient(),
+	}
+}
+
+// newFeedClient returns the client feeds are fetched with. It only connects
+// to public addresses, checked on the address actually dialled so that
+// redirects and hostnames resolving to internal addresses are caught too,
+// and it ignores proxy settings, which would hide the address.
+func newFeedClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil || !domain.IsPublicAddress(addr) {
+				return domain.ErrFeedAddress
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: feedTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: feedTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxFeedRedirects {
+				return errFeedRedirects
+			}
+			return domain.CheckFeedURL(req.URL)
+		},
+	}
+}
+
+// ExportCalendar renders the item's confirmed bookings and blocked dates as
+// an iCalendar document. Bookings still awaiting the owner are left out, as
+// are dates imported from external feeds, so platforms that import each
+// other's feeds don't echo blocks back and forth. An item with several units
+// is only shown busy while all of them are taken.
+func (s *CalendarService) ExportCalendar(ctx context.Context, itemID uuid.UUID) ([]byte, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slots, err := s.availabilityRepo.GetOverlapping(ctx, itemID, now.Add(-exportLookBack), now.Add(exportLookAhead))
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{
+		ProductID: calendarProductID,
+		Name:      item.Title,
+	}
+	slots = publishedSlots(slots)
+	if capacity := item.UnitCapacity(); capacity > 1 {
+		cal.Events = fullyBookedEvents(item.ID, slots, capacity, now)
+	} else {
+		for _, slot := range slots {
+			summary := slotSummary(slot.Status)
+			if summary == "" {
+				continue
+			}
+			cal.Events = append(cal.Events, ical.Event{
+				UID:     slot.ID.String() + "@rentalflow",
+				Summary: summary,
+				Start:   slot.StartDate,
+				End:     slot.EndDate,
+				AllDay:  isMidnight(slot.StartDate) && isMidnight(slot.EndDate),
+				Status:  "CONFIRMED",
+				Stamp:   slot.CreatedAt,
+			})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// publishedSlots drops the slots a calendar export leaves out: those imported
+// from feeds and those held for bookings that aren't confirmed yet
+func publishedSlots(slots []*domain.AvailabilitySlot) []*domain.AvailabilitySlot {
+	published := make([]*domain.AvailabilitySlot, 0, len(slots))
+	for _, slot := range slots {
+		if slot.FeedID != nil || (slot.Status == domain.StatusBooked && !slot.Confirmed) {
+			continue
+		}
+		published = append(published, slot)
+	}
+	return published
+}
+
+// fullyBookedEvents covers the times all of an item's units are taken
+func fullyBookedEvents(itemID uuid.UUID, slots []*domain.AvailabilitySlot, capacity int, now time.Time) []ical.Event {
+	var events []ical.Event
+	for _, span := range domain.FullyReserved(slots, capacity) {
+		events = append(events, ical.Event{
+			UID:     fmt.Sprintf("%s-%d@rentalflow", itemID, span.Start.Unix()),
+			Summary: "Fully booked",
+			Start:   span.Start,
+			End:     span.End,
+			AllDay:  isMidnight(span.Start) && isMidnight(span.End),
+			Status:  "CONFIRMED",
+			Stamp:   now,
+		})
+	}
+	return events
+}
+
+// slotSummary is the event title for a slot, or "" if the slot isn't busy
+func slotSummary(status domain.AvailabilityStatus) string {
+	switch status {
+	case domain.StatusBooked:
+		return "Booked"
+	case domain.StatusBlocked:
+		return "Unavailable"
+	case domain.StatusMaintenance:
+		return "Maintenance"
+	}
+	return ""
+}
+
+func isMidnight(t time.Time) bool {
+	t = t.UTC()
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// AddFeed imports an external calendar for the owner's item and syncs it once
+// straight away. A failed first sync still keeps the feed, with the error
+// recorded on it, so the scheduler can retry.
+func (s *CalendarService) AddFeed(ctx context.Context, itemID, ownerID uuid.UUID, name, feedURL string) (*domain.CalendarFeed, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	feed, err := domain.NewCalendarFeed(itemID, ownerID, name, feedURL)
+	if err != nil {
+		return nil, err
+	}
+	// The feed is created claimed for its first sync
+	leaseUntil := time.Now().Add(feedSyncLease)
+	feed.SyncToken = uuid.New()
+	feed.SyncingUntil = &leaseUntil
+	if err := s.feedRepo.Create(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	if err := s.sync(ctx, feed); err != nil && err != domain.ErrFeedFetch {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// ListFeeds lists the calendars imported for the owner's item
+func (s *CalendarService) ListFeeds(ctx context.Context, itemID, ownerID uuid.UUID) ([]*domain.CalendarFeed, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+	return s.feedRepo.GetByItem(ctx, itemID)
+}
+
+// RemoveFeed stops importing a calendar and frees the dates it blocked. The
+// feed goes first, so a sync running meanwhile either has its slots removed
+// here or finds the feed gone and takes them back out itself.
+func (s *CalendarService) RemoveFeed(ctx context.Context, feedID, ownerID uuid.UUID) error {
+	feed, err := s.feedRepo.GetByID(ctx, feedID)
+	if err != nil {
+		return err
+	}
+	if feed.OwnerID != ownerID {
+		return domain.ErrUnauthorized
+	}
+
+	if err := s.feedRepo.Delete(ctx, feedID); err != nil {
+		return err
+	}
+	return s.availabilityRepo.DeleteByFeed(ctx, feedID)
+}
+
+// SyncFeed re-imports the owner's feed now rather than waiting for the
+// scheduler, unless a sync of it is already running
+func (s *CalendarService) SyncFeed(ctx context.Context, feedID, ownerID uuid.UUID) (*domain.CalendarFeed, error) {
+	feed, err := s.feedRepo.GetByID(ctx, feedID)
+	if err != nil {
+		return nil, err
+	}
+	if feed.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+
+	feed, err = s.feedRepo.Claim(ctx, feedID, time.Now().Add(feedSyncLease))
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, domain.ErrFeedSyncing
+	}
+
+	if err := s.sync(ctx, feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// sync fetches a claimed feed and replaces the slots it blocks, releasing the
+// claim. If the feed can't be fetched or parsed the previous slots stay in
+// place, the error is recorded on the feed and domain.ErrFeedFetch is
+// returned. Events that can't be read are skipped and reported on the feed.
+func (s *CalendarService) sync(ctx context.Context, feed *domain.CalendarFeed) error {
+	now := time.Now()
+
+	events, invalid, fetchErr := s.fetch(ctx, feed.URL)
+	if fetchErr != nil {
+		feed.RecordSync(now, 0, nil, fetchErr)
+		if err := s.feedRepo.SaveSync(ctx, feed); err != nil {
+			return err
+		}
+		return domain.ErrFeedFetch
+	}
+
+	slots := feedSlots(feed, events, now)
+	if err := s.availabilityRepo.ReplaceFeedSlots(ctx, feed.ID, feed.SyncToken, slots); err != nil {
+		return err
+	}
+
+	feed.RecordSync(now, len(slots), skippedEvents(invalid), nil)
+	return s.feedRepo.SaveSync(ctx, feed)
+}
+
+// fetch downloads and parses a feed. Its errors are safe to show the owner.
+func (s *CalendarService) fetch(ctx context.Context, feedURL string) ([]ical.Event, []ical.InvalidEvent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, nil, domain.ErrInvalidFeedURL
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		for _, reason := range []error{domain.ErrFeedAddress, domain.ErrInvalidFeedURL, errFeedRedirects} {
+			if errors.Is(err, reason) {
+				return nil, nil, reason
+			}
+		}
+		return nil, nil, errFeedUnreachable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, errFeedResponse
+	}
+
+	events, invalid, err := ical.Parse(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, nil, errFeedInvalid
+	}
+	if len(events)+len(invalid) > maxFeedEvents {
+		return nil, nil, fmt.Errorf("feed has more than the %d events allowed", maxFeedEvents)
+	}
+	return events, invalid, nil
+}
+
+// skippedEvents reports the first few events a sync couldn't read
+func skippedEvents(invalid []ical.InvalidEvent) []domain.SkippedEvent {
+	if len(invalid) > maxSkippedEvents {
+		invalid = invalid[:maxSkippedEvents]
+	}
+	var skipped []domain.SkippedEvent
+	for _, event := range invalid {
+		skipped = append(skipped, domain.SkippedEvent{UID: event.UID, Reason: event.Err.Error()})
+	}
+	return skipped
+}
+
+// feedSlots turns the busy, current or upcoming events of a feed into
+// blocked slots for the feed's item
+func feedSlots(feed *domain.CalendarFeed, events []ical.Event, now time.Time) []*domain.AvailabilitySlot {
+	slots := make([]*domain.AvailabilitySlot, 0, len(events))
+	for _, event := range events {
+		if event.Status == "CANCELLED" || event.Transparent {
+			continue
+		}
+		if !event.End.After(event.Start) || !event.End.After(now) {
+			continue
+		}
+
+		feedID := feed.ID
+		slot := domain.NewAvailabilitySlot(feed.RentalItemID, event.Start, event.End, domain.StatusBlocked)
+		slot.FeedID = &feedID
+		slot.ExternalUID = event.UID
+		slots = append(slots, slot)
+	}
+	return slots
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+// The fakes embed the repository interfaces, so calling a method a test
+// doesn't expect panics
+
+type fakeItems struct {
+	repository.ItemRepository
+	item *domain.RentalItem
+}
+
+func (f *fakeItems) GetByID(ctx context.Context, id uuid.UUID) (*domain.RentalItem, error) {
+	if f.item == nil || f.item.ID != id {
+		return nil, domain.ErrItemNotFound
+	}
+	return f.item, nil
+}
+
+type fakeSlots struct {
+	repository.AvailabilityRepository
+	slots    []*domain.AvailabilitySlot
+	replaced []*domain.AvailabilitySlot
+	// holder is the sync token the feed is held under, if not the syncing one
+	holder *uuid.UUID
+}
+
+func (f *fakeSlots) GetOverlapping(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time) ([]*domain.AvailabilitySlot, error) {
+	return f.slots, nil
+}
+
+func (f *fakeSlots) ReplaceFeedSlots(ctx context.Context, feedID, syncToken uuid.UUID, slots []*domain.AvailabilitySlot) error {
+	if f.holder != nil && *f.holder != syncToken {
+		return domain.ErrFeedClaimLost
+	}
+	f.replaced = slots
+	return nil
+}
+
+type fakeFeeds struct {
+	repository.CalendarFeedRepository
+	updates int
+}
+
+func (f *fakeFeeds) SaveSync(ctx context.Context, feed *domain.CalendarFeed) error {
+	f.updates++
+	return nil
+}
+
+func newTestFeed(t *testing.T, url string) *domain.CalendarFeed {
+	t.Helper()
+	return &domain.CalendarFeed{ID: uuid.New(), RentalItemID: uuid.New(), OwnerID: uuid.New(), URL: url, SyncToken: uuid.New()}
+}
+
+func TestSyncImportsFeed(t *testing.T) {
+	start := time.Now().UTC().AddDate(0, 0, 10).Truncate(24 * time.Hour)
+	ics := fmt.Sprintf("BEGIN:VCALENDAR\r\n"+
+		"BEGIN:VEVENT\r\nUID:busy\r\nDTSTART;VALUE=DATE:%s\r\nDTEND;VALUE=DATE:%s\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:broken\r\nDTSTART:tomorrow\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:cancelled\r\nDTSTART;VALUE=DATE:%s\r\nSTATUS:CANCELLED\r\nEND:VEVENT\r\n"+
+		"BEGIN:VEVENT\r\nUID:past\r\nDTSTART;VALUE=DATE:20000101\r\nEND:VEVENT\r\n"+
+		"END:VCALENDAR\r\n",
+		start.Format("20060102"), start.AddDate(0, 0, 3).Format("20060102"), start.Format("20060102"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		fmt.Fprint(w, ics)
+	}))
+	defer server.Close()
+
+	slots, feeds := &fakeSlots{}, &fakeFeeds{}
+	s := NewCalendarService(&fakeItems{}, slots, feeds)
+	// The test server listens on loopback, which the real client refuses
+	s.httpClient = server.Client()
+
+	feed := newTestFeed(t, server.URL)
+	if err := s.sync(context.Background(), feed); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	if len(slots.replaced) != 1 {
+		t.Fatalf("got %d slots, want 1: %+v", len(slots.replaced), slots.replaced)
+	}
+	slot := slots.replaced[0]
+	if slot.ExternalUID != "busy" || slot.Status != domain.StatusBlocked || *slot.FeedID != feed.ID ||
+		!slot.StartDate.Equal(start) || !slot.EndDate.Equal(start.AddDate(0, 0, 3)) {
+		t.Errorf("slot = %+v", slot)
+	}
+
+	if feed.LastError != "" || feed.EventCount != 1 || feed.LastSyncedAt == nil {
+		t.Errorf("feed = %+v", feed)
+	}
+	if len(feed.SkippedEvents) != 1 || feed.SkippedEvents[0].UID != "broken" {
+		t.Errorf("skipped = %+v, want the broken event", feed.SkippedEvents)
+	}
+	if feeds.updates != 1 {
+		t.Errorf("feed saved %d times, want 1", feeds.updates)
+	}
+}
+
+func TestSyncStopsOnceClaimLost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	}))
+	defer server.Close()
+
+	other := uuid.New()
+	slots, feeds := &fakeSlots{holder: &other}, &fakeFeeds{}
+	s := NewCalendarService(&fakeItems{}, slots, feeds)
+	s.httpClient = server.Client()
+
+	feed := newTestFeed(t, server.URL)
+	if err := s.sync(context.Background(), feed); err != domain.ErrFeedClaimLost {
+		t.Fatalf("sync = %v, want ErrFeedClaimLost", err)
+	}
+	if slots.replaced != nil || feeds.updates != 0 {
+		t.Errorf("sync went on after losing the feed: %d slots, %d saves", len(slots.replaced), feeds.updates)
+	}
+}
+
+func TestSyncFailureKeepsSlotsAndHidesResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal admin console", http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	slots, feeds := &fakeSlots{}, &fakeFeeds{}
+	s := NewCalendarService(&fakeItems{}, slots, feeds)
+	s.httpClient = server.Client()
+
+	feed := newTestFeed(t, server.URL)
+	if err := s.sync(context.Background(), feed); err != domain.ErrFeedFetch {
+		t.Fatalf("sync = %v, want ErrFeedFetch", err)
+	}
+	if slots.replaced != nil {
+		t.Error("slots were replaced after a failed fetch")
+	}
+	if feed.LastError != errFeedResponse.Error() {
+		t.Errorf("last error = %q, want %q", feed.LastError, errFeedResponse)
+	}
+	if strings.Contains(feed.LastError, "418") || strings.Contains(feed.LastError, "admin") {
+		t.Errorf("last error repeats the response: %q", feed.LastError)
+	}
+}
+
+func TestSyncRefusesInternalAddresses(t *testing.T) {
+	requests := 0
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	}))
+	defer internal.Close()
+
+	feeds := &fakeFeeds{}
+	s := NewCalendarService(&fakeItems{}, &fakeSlots{}, feeds)
+
+	feed := newTestFeed(t, internal.URL)
+	if err := s.sync(context.Background(), feed); err != domain.ErrFeedFetch {
+		t.Fatalf("sync = %v, want ErrFeedFetch", err)
+	}
+	if feed.LastError != domain.ErrFeedAddress.Error() {
+		t.Errorf("last error = %q, want %q", feed.LastError, domain.ErrFeedAddress)
+	}
+	if requests != 0 {
+		t.Errorf("internal server got %d requests", requests)
+	}
+}
+
+func TestFeedClientChecksRedirects(t *testing.T) {
+	client := newFeedClient()
+	via := []*http.Request{httptest.NewRequest(http.MethodGet, "https://calendar.example.com/feed.ics", nil)}
+
+	tests := []struct {
+		target string
+		want   error
+	}{
+		{"https://other.example.com/feed.ics", nil},
+		{"http://169.254.169.254/latest/meta-data/", domain.ErrFeedAddress},
+		{"http://localhost:8080/", domain.ErrFeedAddress},
+		{"file:///etc/passwd", domain.ErrInvalidFeedURL},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if err := client.CheckRedirect(req, via); err != tt.want {
+			t.Errorf("redirect to %s = %v, want %v", tt.target, err, tt.want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://other.example.com/", nil)
+	if err := client.CheckRedirect(req, make([]*http.Request, maxFeedRedirects)); err != errFeedRedirects {
+		t.Errorf("redirect after %d hops = %v, want errFeedRedirects", maxFeedRedirects, err)
+	}
+}
+
+func TestExportCalendarPublishesConfirmedBookings(t *testing.T) {
+	item := &domain.RentalItem{ID: uuid.New(), Title: "Kayak"}
+	day := time.Now().UTC().AddDate(0, 0, 5).Truncate(24 * time.Hour)
+	slot := func(status domain.AvailabilityStatus, offset int) *domain.AvailabilitySlot {
+		return domain.NewAvailabilitySlot(item.ID, day.AddDate(0, 0, offset), day.AddDate(0, 0, offset+1), status)
+	}
+
+	confirmed := slot(domain.StatusBooked, 0)
+	confirmed.Confirmed = true
+	pending := slot(domain.StatusBooked, 2)
+	blocked := slot(domain.StatusBlocked, 4)
+	imported := slot(domain.StatusBlocked, 6)
+	feedID := uuid.New()
+	imported.FeedID = &feedID
+
+	s := NewCalendarService(&fakeItems{item: item}, &fakeSlots{slots: []*domain.AvailabilitySlot{confirmed, pending, blocked, imported}}, &fakeFeeds{})
+	out, err := s.ExportCalendar(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("ExportCalendar: %v", err)
+	}
+
+	cal := string(out)
+	for _, want := range []*domain.AvailabilitySlot{confirmed, blocked} {
+		if !strings.Contains(cal, "UID:"+want.ID.String()) {
+			t.Errorf("export is missing slot %s:\n%s", want.ID, cal)
+		}
+	}
+	for _, unwanted := range []*domain.AvailabilitySlot{pending, imported} {
+		if strings.Contains(cal, unwanted.ID.String()) {
+			t.Errorf("export includes slot %s:\n%s", unwanted.ID, cal)
+		}
+	}
+}
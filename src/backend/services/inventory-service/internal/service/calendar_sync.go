@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+// feedSyncBatchSize caps how many feeds are synced per check. Feeds are
+// claimed one at a time, so several service instances can share the work.
+const feedSyncBatchSize = 50
+
+// CalendarSyncScheduler periodically re-imports external calendar feeds so
+// that reservations made elsewhere keep blocking the item's dates
+type CalendarSyncScheduler struct {
+	calendarService *CalendarService
+	log             zerolog.Logger
+}
+
+func NewCalendarSyncScheduler(calendarService *CalendarService) *CalendarSyncScheduler {
+	return &CalendarSyncScheduler{
+		calendarService: calendarService,
+		log:             logger.NewLogger("calendar-sync"),
+	}
+}
+
+// Run syncs feeds not synced within the last interval, now and then every
+// interval until ctx is done
+func (c *CalendarSyncScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.syncDue(ctx, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *CalendarSyncScheduler) syncDue(ctx context.Context, interval time.Duration) {
+	s := c.calendarService
+
+	before := time.Now().Add(-interval)
+	for i := 0; i < feedSyncBatchSize && ctx.Err() == nil; i++ {
+		feed, err := s.feedRepo.ClaimDue(ctx, before, time.Now().Add(feedSyncLease))
+		if err != nil {
+			c.log.Error().Err(err).Msg("Failed to claim a calendar feed due for sync")
+			return
+		}
+		if feed == nil {
+			return
+		}
+
+		if err := s.sync(ctx, feed); err != nil {
+			c.log.Warn().Err(err).Str("feed_id", feed.ID.String()).Str("last_error", feed.LastError).Msg("Failed to sync calendar feed")
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/clients"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+// CategoryService manages the category tree items are filed under
+type CategoryService struct {
+	categoryRepo repository.CategoryRepository
+	itemRepo     repository.ItemRepository
+	userClient   *clients.UserClient
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(categoryRepo repository.CategoryRepository, itemRepo repository.ItemRepository, userClient *clients.UserClient) *CategoryService {
+	return &CategoryService{
+		categoryRepo: categoryRepo,
+		itemRepo:     itemRepo,
+		userClient:   userClient,
+	}
+}
+
+// loadCategoryTree reads the whole tree, which is small enough to load per request
+func loadCategoryTree(ctx context.Context, categoryRepo repository.CategoryRepository) (*domain.CategoryTree, error) {
+	categories, err := categoryRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewCategoryTree(categories), nil
+}
+
+// EnsureDefaults seeds the tree with the default categories if it is empty
+func (s *CategoryService) EnsureDefaults(ctx context.Context) error {
+	categories, err := s.categoryRepo.List(ctx)
+	if err != nil || len(categories) > 0 {
+		return err
+	}
+	return s.createDefaults(ctx, domain.DefaultCategories, nil)
+}
+
+func (s *CategoryService) createDefaults(ctx context.Context, defaults []domain.DefaultCategory, parent *domain.Category) error {
+	for i, def := range defaults {
+		category, err := domain.NewCategory(def.Slug, def.Name, parent, i)
+		if err != nil {
+			return err
+		}
+		if err := s.categoryRepo.Create(ctx, category); err != nil && err != domain.ErrCategoryExists {
+			return err
+		}
+		if err := s.createDefaults(ctx, def.Children, category); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListCategories returns the tree from its roots. Inactive branches are only
+// shown when asked for.
+func (s *CategoryService) ListCategories(ctx context.Context, includeInactive bool) ([]*domain.CategoryNode, error) {
+	tree, err := loadCategoryTree(ctx, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Nodes(includeInactive), nil
+}
+
+// GetActiveCategory returns a category that, along with its ancestors, is
+// open for browsing
+func (s *CategoryService) GetActiveCategory(ctx context.Context, slug string) (*domain.Category, error) {
+	tree, err := loadCategoryTree(ctx, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	category, ok := tree.Get(slug)
+	if !ok || !tree.IsActive(slug) {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+// CreateCategory adds a category under parent, or a new root if parent is empty
+func (s *CategoryService) CreateCategory(ctx context.Context, adminID uuid.UUID, slug, name, parent string, sortOrder int) (*domain.Category, error) {
+	if err := requireAdmin(ctx, s.userClient, adminID); err != nil {
+		return nil, err
+	}
+
+	var parentCategory *domain.Category
+	if parent != "" {
+		p, err := s.categoryRepo.GetBySlug(ctx, parent)
+		if err == domain.ErrCategoryNotFound {
+			return nil, domain.ErrInvalidCategory
+		}
+		if err != nil {
+			return nil, err
+		}
+		parentCategory = p
+	}
+
+	category, err := domain.NewCategory(slug, name, parentCategory, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.Create(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory renames, reorders, activates or deactivates a category, or
+// moves it to another parent along with its subtree and items. The slug
+// cannot change.
+func (s *CategoryService) UpdateCategory(ctx context.Context, adminID uuid.UUID, slug string, updates map[string]interface{}) (*domain.Category, error) {
+	if err := requireAdmin(ctx, s.userClient, adminID); err != nil {
+		return nil, err
+	}
+
+	tree, err := loadCategoryTree(ctx, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	category, ok := tree.Get(slug)
+	if !ok {
+		return nil, domain.ErrCategoryNotFound
+	}
+
+	if v, ok := updates["name"].(string); ok {
+		if v == "" {
+			return nil, domain.ErrInvalidCategory
+		}
+		category.Name = v
+	}
+	if v, ok := updates["is_active"].(bool); ok {
+		category.IsActive = v
+	}
+	if v, ok := updates["sort_order"].(float64); ok {
+		category.SortOrder = int(v)
+	}
+
+	oldAncestors := category.Ancestors
+	parent, moving := updates["parent"].(string)
+	moving = moving && parent != category.Parent
+	if moving {
+		ancestors := []string{}
+		if parent != "" {
+			p, ok := tree.Get(parent)
+			if !ok || parent == slug || tree.IsDescendant(parent, slug) {
+				return nil, domain.ErrInvalidCategory
+			}
+			ancestors = p.Path()
+		}
+		category.Parent = parent
+		category.Ancestors = ancestors
+	}
+
+	category.UpdatedAt = time.Now()
+	if err := s.categoryRepo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+
+	if moving {
+		if err := s.categoryRepo.MoveSubtree(ctx, slug, oldAncestors, category.Ancestors); err != nil {
+			return nil, err
+		}
+		if err := s.itemRepo.MoveCategory(ctx, slug, oldAncestors, category.Ancestors); err != nil {
+			return nil, err
+		}
+	}
+	return category, nil
+}
+
+// DeleteCategory removes a category with no subcategories or items.
+// Deactivating it is the way to retire one that is in use.
+func (s *CategoryService) DeleteCategory(ctx context.Context, adminID uuid.UUID, slug string) error {
+	if err := requireAdmin(ctx, s.userClient, adminID); err != nil {
+		return err
+	}
+
+	tree, err := loadCategoryTree(ctx, s.categoryRepo)
+	if err != nil {
+		return err
+	}
+	category, ok := tree.Get(slug)
+	if !ok {
+		return domain.ErrCategoryNotFound
+	}
+	if tree.HasChildren(slug) {
+		return domain.ErrCategoryInUse
+	}
+	count, err := s.itemRepo.CountInCategory(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrCategoryInUse
+	}
+
+	return s.categoryRepo.Delete(ctx, category.ID)
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+// importStaleAfter is how long a running job can go without saving a row
+// before another worker takes it over
+const importStaleAfter = 2 * time.Minute
+
+// ImportWorker runs queued bulk imports in the background. Jobs are claimed
+// one at a time, so several service instances can share the queue.
+type ImportWorker struct {
+	bulkService *BulkService
+	log         zerolog.Logger
+}
+
+func NewImportWorker(bulkService *BulkService) *ImportWorker {
+	return &ImportWorker{
+		bulkService: bulkService,
+		log:         logger.NewLogger("import-worker"),
+	}
+}
+
+// Run works through the queued jobs, then checks for new ones every interval
+// until ctx is done
+func (w *ImportWorker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		w.runQueued(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *ImportWorker) runQueued(ctx context.Context) {
+	s := w.bulkService
+
+	for ctx.Err() == nil {
+		job, err := s.jobRepo.ClaimNext(ctx, time.Now().Add(-importStaleAfter))
+		if err != nil {
+			w.log.Error().Err(err).Msg("Failed to claim import job")
+			return
+		}
+		if job == nil {
+			return
+		}
+
+		if err := s.runImport(ctx, job); err == domain.ErrImportClaimLost {
+			w.log.Info().Str("job_id", job.ID.String()).Msg("Import job taken over by another worker")
+			continue
+		} else if err != nil {
+			w.log.Warn().Err(err).Str("job_id", job.ID.String()).Msg("Import job interrupted")
+			return
+		}
+		w.log.Info().Str("job_id", job.ID.String()).Int("total", job.Total).Msg("Import job completed")
+	}
+}
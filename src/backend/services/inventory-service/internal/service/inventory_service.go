@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rentalflow/inventory-service/internal/domain"
 	"github.com/rentalflow/inventory-service/internal/repository"
+	"github.com/rentalflow/rentalflow/pkg/media"
 )
 
 // InventoryService handles inventory business logic
@@ -14,6 +17,9 @@ type InventoryService struct {
 	itemRepo         repository.ItemRepository
 	availabilityRepo repository.AvailabilityRepository
 	maintenanceRepo  repository.MaintenanceRepository
+	schemaRepo       repository.SpecSchemaRepository
+	categoryRepo     repository.CategoryRepository
+	uploader         *media.Uploader
 }
 
 // NewInventoryService creates a new inventory service
@@ -21,11 +27,17 @@ func NewInventoryService(
 	itemRepo repository.ItemRepository,
 	availabilityRepo repository.AvailabilityRepository,
 	maintenanceRepo repository.MaintenanceRepository,
+	schemaRepo repository.SpecSchemaRepository,
+	categoryRepo repository.CategoryRepository,
+	uploader *media.Uploader,
 ) *InventoryService {
 	return &InventoryService{
 		itemRepo:         itemRepo,
 		availabilityRepo: availabilityRepo,
 		maintenanceRepo:  maintenanceRepo,
+		schemaRepo:       schemaRepo,
+		categoryRepo:     categoryRepo,
+		uploader:         uploader,
 	}
 }
 
@@ -33,21 +45,52 @@ func NewInventoryService(
 func (s *InventoryService) CreateItem(ctx context.Context, ownerID uuid.UUID, title, description string,
 	category domain.ItemCategory, subcategory string, dailyRate, weeklyRate, monthlyRate, securityDeposit float64,
 	location domain.Location, specs map[string]string, images []string) (*domain.RentalItem, error) {
+	return s.createItem(ctx, uuid.New(), ownerID, title, description, category, subcategory,
+		dailyRate, weeklyRate, monthlyRate, securityDeposit, location, specs, images)
+}
 
-	if !category.IsValid() {
-		return nil, domain.ErrInvalidCategory
+// createItem creates an item under the given ID. Creating it again fails
+// with ErrItemExists.
+func (s *InventoryService) createItem(ctx context.Context, id, ownerID uuid.UUID, title, description string,
+	category domain.ItemCategory, subcategory string, dailyRate, weeklyRate, monthlyRate, securityDeposit float64,
+	location domain.Location, specs map[string]string, images []string) (*domain.RentalItem, error) {
+
+	if strings.TrimSpace(title) == "" {
+		return nil, domain.ErrMissingTitle
+	}
+	if dailyRate <= 0 || weeklyRate < 0 || monthlyRate < 0 || securityDeposit < 0 {
+		return nil, domain.ErrInvalidPrice
 	}
 
-	item := domain.NewRentalItem(ownerID, title, description, category, subcategory)
+	tree, err := loadCategoryTree(ctx, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	path, err := tree.Resolve(category, subcategory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Items are filed under their root category, with the most specific
+	// category as the subcategory
+	item := domain.NewRentalItem(ownerID, title, description, domain.ItemCategory(path[0]), "")
+	item.ID = id
+	if len(path) > 1 {
+		item.Subcategory = path[len(path)-1]
+	}
+	item.CategoryPath = path
 	item.DailyRate = dailyRate
 	item.WeeklyRate = weeklyRate
 	item.MonthlyRate = monthlyRate
 	item.SecurityDeposit = securityDeposit
 	item.Address = location.Address
 	item.City = location.City
-	item.Latitude = location.Latitude
-	item.Longitude = location.Longitude
-	item.Specifications = specs
+	if err := item.SetCoordinates(location.Latitude, location.Longitude); err != nil {
+		return nil, err
+	}
+	if err := s.applySpecifications(ctx, item, specs); err != nil {
+		return nil, err
+	}
 	item.Images = images
 
 	if err := s.itemRepo.Create(ctx, item); err != nil {
@@ -57,13 +100,68 @@ func (s *InventoryService) CreateItem(ctx context.Context, ownerID uuid.UUID, ti
 	return item, nil
 }
 
+// applySpecifications checks the specifications against the schemas for the
+// item's category and subcategory, storing them under the schema's keys. Items
+// in categories without a schema keep them as given.
+func (s *InventoryService) applySpecifications(ctx context.Context, item *domain.RentalItem, specs map[string]string) error {
+	if specs == nil {
+		specs = make(map[string]string)
+	}
+
+	set, err := s.attributeSet(ctx, item.Category, item.Subcategory)
+	if err != nil {
+		return err
+	}
+	if len(set) == 0 {
+		item.Specifications = specs
+		item.Attributes = nil
+		return nil
+	}
+
+	canonical, typed, err := set.Apply(specs)
+	if err != nil {
+		return err
+	}
+	item.Specifications = canonical
+	item.Attributes = typed
+	return nil
+}
+
+// attributeSet merges the category's schema with the subcategory's, if either exists
+func (s *InventoryService) attributeSet(ctx context.Context, category domain.ItemCategory, subcategory string) (domain.AttributeSet, error) {
+	base, err := s.findSchema(ctx, category, "")
+	if err != nil {
+		return nil, err
+	}
+	var sub *domain.SpecSchema
+	if subcategory != "" {
+		if sub, err = s.findSchema(ctx, category, subcategory); err != nil {
+			return nil, err
+		}
+	}
+	return domain.MergeSchemas(base, sub), nil
+}
+
+// findSchema is like Get but returns nil when there is no schema
+func (s *InventoryService) findSchema(ctx context.Context, category domain.ItemCategory, subcategory string) (*domain.SpecSchema, error) {
+	schema, err := s.schemaRepo.Get(ctx, category, subcategory)
+	if err == domain.ErrSchemaNotFound {
+		return nil, nil
+	}
+	return schema, err
+}
+
 // GetItem retrieves an item by ID
 func (s *InventoryService) GetItem(ctx context.Context, itemID uuid.UUID) (*domain.RentalItem, error) {
 	return s.itemRepo.GetByID(ctx, itemID)
 }
 
-// ListItems lists items with filters
+// ListItems lists items with filters. A search for dates sorted by price is
+// sorted by what those dates cost under each item's pricing rules.
 func (s *InventoryService) ListItems(ctx context.Context, page, pageSize int, filters repository.ItemFilters) ([]*domain.RentalItem, int, error) {
+	if err := s.prepareFilters(ctx, &filters); err != nil {
+		return nil, 0, err
+	}
 	if page < 1 {
 		page = 1
 	}
@@ -71,11 +169,22 @@ func (s *InventoryService) ListItems(ctx context.Context, page, pageSize int, fi
 		pageSize = 20
 	}
 	offset := (page - 1) * pageSize
-	return s.itemRepo.List(ctx, offset, pageSize, filters)
+
+	window, fetchOffset, fetchLimit := priceSortFetch(filters, offset, pageSize)
+	items, total, err := s.itemRepo.List(ctx, fetchOffset, fetchLimit, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return quotePrices(items, filters, window, offset, pageSize), total, nil
 }
 
-// SearchItems searches items by query and filters
-func (s *InventoryService) SearchItems(ctx context.Context, query string, page, pageSize int, filters repository.ItemFilters) ([]*domain.RentalItem, int, error) {
+// SearchItems ranks items by relevance to the query, with facet counts
+// labelled from the category tree. An empty query lists the items matching
+// the filters. Searches for dates are priced and sorted as in ListItems.
+func (s *InventoryService) SearchItems(ctx context.Context, query string, page, pageSize int, filters repository.ItemFilters) (*repository.SearchResult, error) {
+	if err := s.prepareFilters(ctx, &filters); err != nil {
+		return nil, err
+	}
 	if page < 1 {
 		page = 1
 	}
@@ -83,7 +192,82 @@ func (s *InventoryService) SearchItems(ctx context.Context, query string, page,
 		pageSize = 20
 	}
 	offset := (page - 1) * pageSize
-	return s.itemRepo.Search(ctx, query, filters, offset, pageSize)
+
+	window, fetchOffset, fetchLimit := priceSortFetch(filters, offset, pageSize)
+	result, err := s.itemRepo.Search(ctx, query, filters, fetchOffset, fetchLimit)
+	if err != nil {
+		return nil, err
+	}
+	result.Items = quotePrices(result.Items, filters, window, offset, pageSize)
+
+	tree, err := loadCategoryTree(ctx, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	for _, facets := range [][]repository.FacetCount{result.Facets.Categories, result.Facets.Subcategories} {
+		for i := range facets {
+			facets[i].Label = tree.Name(facets[i].Value)
+		}
+	}
+	return result, nil
+}
+
+// prepareFilters validates the search area, specification filters and
+// availability window
+func (s *InventoryService) prepareFilters(ctx context.Context, filters *repository.ItemFilters) error {
+	if err := validateGeoFilters(*filters); err != nil {
+		return err
+	}
+	if len(filters.Attributes) > 0 {
+		if err := s.resolveAttributeFilters(ctx, filters); err != nil {
+			return err
+		}
+	}
+	if filters.AvailableFrom == nil && filters.AvailableTo == nil {
+		return nil
+	}
+	if filters.AvailableFrom == nil || filters.AvailableTo == nil || !filters.AvailableTo.After(*filters.AvailableFrom) {
+		return domain.ErrInvalidDateRange
+	}
+	if filters.AvailableTo.Sub(*filters.AvailableFrom) > domain.MaxCalendarDays*24*time.Hour {
+		return domain.ErrRangeTooLong
+	}
+	return nil
+}
+
+// resolveAttributeFilters types each specification filter by the schema
+// attribute it names, looking only at the filtered category's schemas if there is one
+func (s *InventoryService) resolveAttributeFilters(ctx context.Context, filters *repository.ItemFilters) error {
+	schemas, err := s.schemaRepo.List(ctx, filters.Category)
+	if err != nil {
+		return err
+	}
+	set := domain.MergeSchemas(schemas...)
+
+	for i, filter := range filters.Attributes {
+		attr, ok := set.Lookup(filter.Key)
+		if !ok {
+			return domain.ErrInvalidAttributeFilter
+		}
+		if filters.Attributes[i], err = filter.Resolve(attr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateGeoFilters allows a radius or a bounding box, but not both
+func validateGeoFilters(filters repository.ItemFilters) error {
+	if filters.Near != nil && filters.Within != nil {
+		return domain.ErrInvalidLocation
+	}
+	if filters.Near != nil {
+		return filters.Near.Validate()
+	}
+	if filters.Within != nil {
+		return filters.Within.Validate()
+	}
+	return nil
 }
 
 // GetOwnerItems retrieves items owned by a specific user
@@ -108,16 +292,13 @@ func (s *InventoryService) GetFeaturedItems(ctx context.Context, limit int) ([]*
 
 // UpdateItem updates an existing item
 func (s *InventoryService) UpdateItem(ctx context.Context, itemID, ownerID uuid.UUID, updates map[string]interface{}) (*domain.RentalItem, error) {
-	item, err := s.itemRepo.GetByID(ctx, itemID)
-	if err != nil {
-		return nil, err
-	}
-
-	if item.OwnerID != ownerID {
-		return nil, domain.ErrUnauthorized
-	}
+	return s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) error {
+		return s.applyItemUpdates(ctx, item, updates)
+	})
+}
 
-	// Apply updates
+// applyItemUpdates sets the fields given in updates on the item
+func (s *InventoryService) applyItemUpdates(ctx context.Context, item *domain.RentalItem, updates map[string]interface{}) error {
 	if v, ok := updates["title"].(string); ok {
 		item.Title = v
 	}
@@ -133,12 +314,39 @@ func (s *InventoryService) UpdateItem(ctx context.Context, itemID, ownerID uuid.
 	if v, ok := updates["is_featured"].(bool); ok {
 		item.IsFeatured = v
 	}
-
-	if err := s.itemRepo.Update(ctx, item); err != nil {
-		return nil, err
+	if v, ok := updates["address"].(string); ok {
+		item.Address = v
 	}
-
-	return item, nil
+	if v, ok := updates["city"].(string); ok {
+		item.City = v
+	}
+	lat, hasLat := updates["latitude"].(float64)
+	lng, hasLng := updates["longitude"].(float64)
+	if hasLat || hasLng {
+		if !hasLat {
+			lat = item.Latitude
+		}
+		if !hasLng {
+			lng = item.Longitude
+		}
+		if err := item.SetCoordinates(lat, lng); err != nil {
+			return err
+		}
+	}
+	// Specifications are checked only when they change, so items saved
+	// before their category had a schema can still be edited
+	if v, ok := updates["specifications"].(map[string]interface{}); ok {
+		specs := make(map[string]string, len(v))
+		for key, value := range v {
+			if value != nil {
+				specs[key] = fmt.Sprint(value)
+			}
+		}
+		if err := s.applySpecifications(ctx, item, specs); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // DeleteItem deletes an item
@@ -152,46 +360,194 @@ func (s *InventoryService) DeleteItem(ctx context.Context, itemID, ownerID uuid.
 		return domain.ErrUnauthorized
 	}
 
-	return s.itemRepo.Delete(ctx, itemID)
+	if err := s.itemRepo.Delete(ctx, itemID); err != nil {
+		return err
+	}
+	for i := range item.Media {
+		s.deleteImage(ctx, &item.Media[i])
+	}
+	return nil
 }
 
-// BlockDates blocks dates for booking
-func (s *InventoryService) BlockDates(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, bookingID uuid.UUID) (*domain.AvailabilitySlot, error) {
-	// Check for conflicts
-	hasConflict, err := s.availabilityRepo.CheckConflict(ctx, itemID, startDate, endDate, nil)
+// BlockDates reserves the date range for a booking. The conflict check and
+// insert happen under a per-item lock, so two bookings can't take the same dates.
+// The booking's add-ons are reserved with the dates, so their stock can't be
+// overbooked either. Retrying with the same booking returns the slot already
+// held for it.
+func (s *InventoryService) BlockDates(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, bookingID uuid.UUID,
+	addOns []domain.BookedAddOn) (*domain.AvailabilitySlot, error) {
+	if !endDate.After(startDate) {
+		return nil, domain.ErrInvalidDateRange
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
-	if hasConflict {
+
+	existing, err := s.availabilityRepo.GetByBooking(ctx, bookingID)
+	if err == nil {
+		if existing.RentalItemID == itemID && existing.StartDate.Equal(startDate) && existing.EndDate.Equal(endDate) {
+			return existing, nil
+		}
 		return nil, domain.ErrDateConflict
 	}
+	if err != domain.ErrSlotNotFound {
+		return nil, err
+	}
+
+	if err := item.CheckAddOns(addOns); err != nil {
+		return nil, err
+	}
 
 	slot := domain.NewAvailabilitySlot(itemID, startDate, endDate, domain.StatusBooked)
 	slot.BookingID = &bookingID
+	slot.AddOns = addOns
+
+	if err := s.availabilityRepo.Reserve(ctx, slot, item.ReservationLimits()); err != nil {
+		return nil, err
+	}
+
+	return slot, nil
+}
+
+// ReleaseDates frees the dates held for a booking. Releasing a booking that
+// holds nothing is not an error.
+func (s *InventoryService) ReleaseDates(ctx context.Context, bookingID uuid.UUID) error {
+	return s.availabilityRepo.DeleteByBooking(ctx, bookingID)
+}
+
+// ConfirmDates marks the dates held for a booking as confirmed, so they are
+// published in the item's calendar. Confirming again is a no-op.
+func (s *InventoryService) ConfirmDates(ctx context.Context, bookingID uuid.UUID) error {
+	return s.availabilityRepo.ConfirmByBooking(ctx, bookingID)
+}
+
+// CheckDates reports whether the item is free over the date range. Dates already
+// held by the given booking, if any, count as free.
+func (s *InventoryService) CheckDates(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, bookingID *uuid.UUID) (bool, error) {
+	if !endDate.After(startDate) {
+		return false, domain.ErrInvalidDateRange
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return false, err
+	}
+
+	var excludeSlotID *uuid.UUID
+	if bookingID != nil {
+		slot, err := s.availabilityRepo.GetByBooking(ctx, *bookingID)
+		if err != nil && err != domain.ErrSlotNotFound {
+			return false, err
+		}
+		if slot != nil {
+			excludeSlotID = &slot.ID
+		}
+	}
+
+	hasConflict, err := s.availabilityRepo.CheckConflict(ctx, itemID, startDate, endDate, excludeSlotID, item.UnitCapacity())
+	if err != nil {
+		return false, err
+	}
+	return !hasConflict, nil
+}
+
+// RescheduleDates moves the dates held for a booking. Rescheduling to the dates
+// already held is a no-op, so retries are safe.
+func (s *InventoryService) RescheduleDates(ctx context.Context, bookingID uuid.UUID, startDate, endDate time.Time) (*domain.AvailabilitySlot, error) {
+	if !endDate.After(startDate) {
+		return nil, domain.ErrInvalidDateRange
+	}
 
-	if err := s.availabilityRepo.Create(ctx, slot); err != nil {
+	slot, err := s.availabilityRepo.GetByBooking(ctx, bookingID)
+	if err != nil {
 		return nil, err
 	}
+	if slot.StartDate.Equal(startDate) && slot.EndDate.Equal(endDate) {
+		return slot, nil
+	}
 
+	item, err := s.itemRepo.GetByID(ctx, slot.RentalItemID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.availabilityRepo.Reschedule(ctx, slot, startDate, endDate, item.ReservationLimits()); err != nil {
+		return nil, err
+	}
 	return slot, nil
 }
 
-// CreateMaintenanceLog creates a maintenance log
-func (s *InventoryService) CreateMaintenanceLog(ctx context.Context, itemID, ownerID uuid.UUID, maintenanceType, description string, startDate time.Time, cost float64) (*domain.MaintenanceLog, error) {
-	// Verify owner
+// GetAvailabilityCalendar returns the item's day-by-day free/busy calendar over
+// [from, to), with busy days merged into ranges and free days that are too
+// short a gap for the minimum stay marked as not bookable
+func (s *InventoryService) GetAvailabilityCalendar(ctx context.Context, itemID uuid.UUID, from, to time.Time) (*domain.AvailabilityCalendar, error) {
+	from, to = domain.StartOfDay(from), domain.StartOfDay(to)
+	if !to.After(from) {
+		return nil, domain.ErrInvalidDateRange
+	}
+	if to.Sub(from) > domain.MaxCalendarDays*24*time.Hour {
+		return nil, domain.ErrRangeTooLong
+	}
+
 	item, err := s.itemRepo.GetByID(ctx, itemID)
 	if err != nil {
 		return nil, err
 	}
-	if item.OwnerID != ownerID {
-		return nil, domain.ErrUnauthorized
+
+	windowFrom, windowTo := domain.CalendarWindow(from, to, item.Rules)
+	slots, err := s.availabilityRepo.GetOverlapping(ctx, itemID, windowFrom, windowTo)
+	if err != nil {
+		return nil, err
 	}
 
-	log := domain.NewMaintenanceLog(itemID, maintenanceType, description, startDate, cost)
+	return domain.BuildAvailabilityCalendar(itemID, from, to, item.UnitCapacity(), item.Rules, slots, time.Now()), nil
+}
+
+// SetRentalRules replaces the owner's booking rules for an item
+func (s *InventoryService) SetRentalRules(ctx context.Context, itemID, ownerID uuid.UUID, rules domain.RentalRules) (*domain.RentalItem, error) {
+	return s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) error {
+		if err := rules.Validate(); err != nil {
+			return err
+		}
+		rules.Normalize()
+		item.Rules = rules
+		return nil
+	})
+}
+
+// ValidateBooking checks a rental against the item's rules and existing
+// reservations, listing every reason it can't be booked. Dates already held by
+// the given booking, if any, count as free.
+func (s *InventoryService) ValidateBooking(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time, bookingID *uuid.UUID) (*domain.BookingValidation, error) {
+	if !endDate.After(startDate) {
+		return nil, domain.ErrInvalidDateRange
+	}
 
-	if err := s.maintenanceRepo.Create(ctx, log); err != nil {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
 		return nil, err
 	}
 
-	return log, nil
+	var violations []domain.RuleViolation
+	if !item.IsActive {
+		violations = append(violations, domain.RuleViolation{
+			Code:    domain.ViolationItemInactive,
+			Message: "the item is not currently listed for rent",
+		})
+	}
+	violations = append(violations, item.Rules.Check(startDate, endDate, time.Now())...)
+
+	available, err := s.CheckDates(ctx, itemID, startDate, endDate, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		violations = append(violations, domain.RuleViolation{
+			Code:    domain.ViolationUnavailable,
+			Message: "the item is already reserved for some of these dates",
+		})
+	}
+
+	return domain.NewBookingValidation(violations), nil
 }
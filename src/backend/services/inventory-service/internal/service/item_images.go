@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rentalflow/rentalflow/pkg/media"
+)
+
+// AddItemImage uploads an image for the owner's item, generates its sizes and
+// appends it to the item's images
+func (s *InventoryService) AddItemImage(ctx context.Context, itemID, ownerID uuid.UUID, file io.Reader) (*domain.RentalItem, *media.Asset, error) {
+	item, err := s.ownedItem(ctx, itemID, ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(item.Images) >= domain.MaxItemImages {
+		return nil, nil, domain.ErrTooManyImages
+	}
+
+	asset, err := s.uploader.Upload(ctx, media.ItemImagePolicy, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	item, err = s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) error {
+		return item.AddImage(asset)
+	})
+	if err != nil {
+		s.deleteImage(ctx, asset)
+		return nil, nil, err
+	}
+	return item, asset, nil
+}
+
+// RemoveItemImage drops an image from the owner's item, deleting its files if
+// it was uploaded here
+func (s *InventoryService) RemoveItemImage(ctx context.Context, itemID, ownerID uuid.UUID, url string) (*domain.RentalItem, error) {
+	var asset *media.Asset
+	item, err := s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) (err error) {
+		asset, err = item.RemoveImage(url)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.deleteImage(ctx, asset)
+	return item, nil
+}
+
+// deleteImage removes an image's files. Failures only leave orphaned files
+// behind, so they are logged rather than returned.
+func (s *InventoryService) deleteImage(ctx context.Context, asset *media.Asset) {
+	if err := s.uploader.Delete(ctx, asset); err != nil {
+		log := logger.NewLogger("inventory")
+		log.Warn().Err(err).Str("key", asset.Key).Msg("Failed to delete image files")
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+)
+
+// CreateMaintenanceLog schedules maintenance on an item over [startDate,
+// endDate). The dates are blocked with a maintenance slot, so maintenance
+// can't be scheduled over a booking and the item can't be booked while the
+// work is outstanding. For an item with units, unitID picks the unit being
+// serviced; without one the work takes up one unit's worth of capacity.
+func (s *InventoryService) CreateMaintenanceLog(ctx context.Context, itemID, ownerID uuid.UUID, unitID *uuid.UUID, maintenanceType, description string,
+	startDate, endDate time.Time, cost float64) (*domain.MaintenanceLog, error) {
+
+	log := domain.NewMaintenanceLog(itemID, maintenanceType, description, startDate, cost)
+	log.EndDate = &endDate
+	log.UnitID = unitID
+	if err := s.scheduleMaintenance(ctx, ownerID, log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// scheduleMaintenance blocks the log's dates and saves it
+func (s *InventoryService) scheduleMaintenance(ctx context.Context, ownerID uuid.UUID, log *domain.MaintenanceLog) error {
+	if strings.TrimSpace(log.MaintenanceType) == "" {
+		return domain.ErrMissingMaintenanceType
+	}
+	if log.Cost < 0 {
+		return domain.ErrInvalidPrice
+	}
+	if log.EndDate == nil || !log.EndDate.After(log.StartDate) {
+		return domain.ErrInvalidDateRange
+	}
+	if log.EndDate.Sub(log.StartDate) > domain.MaxCalendarDays*24*time.Hour {
+		return domain.ErrRangeTooLong
+	}
+
+	// Verify owner
+	item, err := s.ownedItem(ctx, log.RentalItemID, ownerID)
+	if err != nil {
+		return err
+	}
+	if log.UnitID != nil {
+		if _, err := item.Unit(*log.UnitID); err != nil {
+			return err
+		}
+	}
+
+	slot := domain.NewAvailabilitySlot(log.RentalItemID, log.StartDate, *log.EndDate, domain.StatusMaintenance)
+	slot.UnitID = log.UnitID
+	if err := s.availabilityRepo.Reserve(ctx, slot, item.ReservationLimits()); err != nil {
+		return err
+	}
+	log.SlotID = &slot.ID
+
+	if err := s.maintenanceRepo.Create(ctx, log); err != nil {
+		s.availabilityRepo.Delete(ctx, slot.ID)
+		return err
+	}
+	return nil
+}
+
+// GetMaintenanceLog returns maintenance on one of the owner's items
+func (s *InventoryService) GetMaintenanceLog(ctx context.Context, logID, ownerID uuid.UUID) (*domain.MaintenanceLog, error) {
+	log, err := s.maintenanceRepo.GetByID(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ownedItem(ctx, log.RentalItemID, ownerID); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// ListMaintenanceLogs returns an item's maintenance, or one of its units'
+// maintenance if unitID is set, newest first
+func (s *InventoryService) ListMaintenanceLogs(ctx context.Context, itemID, ownerID uuid.UUID, unitID *uuid.UUID, page, pageSize int) ([]*domain.MaintenanceLog, int, error) {
+	if _, err := s.ownedItem(ctx, itemID, ownerID); err != nil {
+		return nil, 0, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	offset := (page - 1) * pageSize
+
+	if unitID != nil {
+		return s.maintenanceRepo.GetByUnit(ctx, itemID, *unitID, offset, pageSize)
+	}
+	return s.maintenanceRepo.GetByItem(ctx, itemID, offset, pageSize)
+}
+
+// StartMaintenance marks scheduled maintenance as under way
+func (s *InventoryService) StartMaintenance(ctx context.Context, logID, ownerID uuid.UUID) (*domain.MaintenanceLog, error) {
+	log, err := s.GetMaintenanceLog(ctx, logID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := log.Start(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.maintenanceRepo.Update(ctx, log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// CompleteMaintenance marks maintenance done, recording the final cost if
+// given, and frees the dates it blocked so the item can be booked again
+func (s *InventoryService) CompleteMaintenance(ctx context.Context, logID, ownerID uuid.UUID, cost *float64) (*domain.MaintenanceLog, error) {
+	log, err := s.GetMaintenanceLog(ctx, logID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := log.Complete(time.Now(), cost); err != nil {
+		return nil, err
+	}
+
+	// The slot goes first so that a failed update can be retried; a slot
+	// that is already gone is fine
+	if log.SlotID != nil {
+		if err := s.availabilityRepo.Delete(ctx, *log.SlotID); err != nil && err != domain.ErrSlotNotFound {
+			return nil, err
+		}
+		log.SlotID = nil
+	}
+
+	if err := s.maintenanceRepo.Update(ctx, log); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// GetMaintenanceCosts totals the maintenance costs of one of the owner's
+// items, or of all of them if itemID is nil
+func (s *InventoryService) GetMaintenanceCosts(ctx context.Context, ownerID uuid.UUID, itemID *uuid.UUID) (*domain.MaintenanceCosts, error) {
+	var itemIDs []uuid.UUID
+	if itemID != nil {
+		if _, err := s.ownedItem(ctx, *itemID, ownerID); err != nil {
+			return nil, err
+		}
+		itemIDs = []uuid.UUID{*itemID}
+	} else {
+		items, err := allOwnerItems(ctx, s.itemRepo, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			itemIDs = append(itemIDs, item.ID)
+		}
+	}
+	if len(itemIDs) == 0 {
+		return domain.NewMaintenanceCosts(nil), nil
+	}
+
+	rows, err := s.maintenanceRepo.SumCosts(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewMaintenanceCosts(rows), nil
+}
+
+// ownedItem loads the item, checking that it belongs to the owner
+func (s *InventoryService) ownedItem(ctx context.Context, itemID, ownerID uuid.UUID) (*domain.RentalItem, error) {
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+	return item, nil
+}
+
+// maxUpdateAttempts bounds how often a change is applied again to a fresh copy
+// of an item that others keep saving
+const maxUpdateAttempts = 3
+
+// modifyItem loads the owner's item, applies the change and saves it, starting
+// over from a fresh read if someone else saved the item in between
+func (s *InventoryService) modifyItem(ctx context.Context, itemID, ownerID uuid.UUID, change func(*domain.RentalItem) error) (*domain.RentalItem, error) {
+	for attempt := 1; ; attempt++ {
+		item, err := s.ownedItem(ctx, itemID, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if err := change(item); err != nil {
+			return nil, err
+		}
+		err = s.itemRepo.Update(ctx, item)
+		if err == domain.ErrItemChanged && attempt < maxUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return item, nil
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+// planCheckLease is how long a check holds a plan before another scheduler
+// may take it over
+const planCheckLease = 5 * time.Minute
+
+// MaintenancePlanService manages preventive maintenance plans and schedules
+// the maintenance they call for
+type MaintenancePlanService struct {
+	inventoryService *InventoryService
+	planRepo         repository.MaintenancePlanRepository
+	maintenanceRepo  repository.MaintenanceRepository
+	availabilityRepo repository.AvailabilityRepository
+}
+
+// NewMaintenancePlanService creates a new maintenance plan service
+func NewMaintenancePlanService(
+	inventoryService *InventoryService,
+	planRepo repository.MaintenancePlanRepository,
+	maintenanceRepo repository.MaintenanceRepository,
+	availabilityRepo repository.AvailabilityRepository,
+) *MaintenancePlanService {
+	return &MaintenancePlanService{
+		inventoryService: inventoryService,
+		planRepo:         planRepo,
+		maintenanceRepo:  maintenanceRepo,
+		availabilityRepo: availabilityRepo,
+	}
+}
+
+// PlanWithForecast is a maintenance plan along with its item's usage since
+// the last service and when the next one is due, for each unit of an item
+// with units
+type PlanWithForecast struct {
+	*domain.MaintenancePlan
+	Forecasts []domain.MaintenanceForecast `json:"forecasts"`
+}
+
+// CreatePlan adds a maintenance plan to one of the owner's items. The item
+// counts as last serviced at lastServicedAt, or now if that is nil.
+// Maintenance that is already due is scheduled straight away.
+func (s *MaintenancePlanService) CreatePlan(ctx context.Context, itemID, ownerID uuid.UUID, settings domain.MaintenancePlanSettings,
+	lastServicedAt *time.Time) (*domain.MaintenancePlan, error) {
+
+	if lastServicedAt != nil && lastServicedAt.After(time.Now()) {
+		return nil, domain.ErrInvalidDateRange
+	}
+	if _, err := s.inventoryService.ownedItem(ctx, itemID, ownerID); err != nil {
+		return nil, err
+	}
+
+	plan, err := domain.NewMaintenancePlan(itemID, ownerID, settings)
+	if err != nil {
+		return nil, err
+	}
+	if lastServicedAt != nil {
+		plan.LastServicedAt = *lastServicedAt
+	}
+	if err := s.planRepo.Create(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return s.checkNow(ctx, plan), nil
+}
+
+// ListPlans returns the plans for one of the owner's items with their forecasts
+func (s *MaintenancePlanService) ListPlans(ctx context.Context, itemID, ownerID uuid.UUID) ([]PlanWithForecast, error) {
+	item, err := s.inventoryService.ownedItem(ctx, itemID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	plans, err := s.planRepo.GetByItem(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	units := item.ActiveUnits()
+	result := make([]PlanWithForecast, 0, len(plans))
+	for _, plan := range plans {
+		slots, err := s.usageSlots(ctx, plan, units, now)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, PlanWithForecast{MaintenancePlan: plan, Forecasts: plan.Forecasts(units, slots, now)})
+	}
+	return result, nil
+}
+
+// UpdatePlan replaces the plan's settings. Maintenance already scheduled by
+// the plan keeps its dates. If the scheduler is checking the plan right now,
+// the new settings take effect at its next check.
+func (s *MaintenancePlanService) UpdatePlan(ctx context.Context, planID, ownerID uuid.UUID, settings domain.MaintenancePlanSettings) (*domain.MaintenancePlan, error) {
+	plan, err := s.ownedPlan(ctx, planID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	plan.MaintenancePlanSettings = settings
+	plan.UpdatedAt = time.Now()
+	if err := s.planRepo.Update(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return s.checkNow(ctx, plan), nil
+}
+
+// DeletePlan removes the plan. Maintenance it already scheduled is left for
+// the owner to complete.
+func (s *MaintenancePlanService) DeletePlan(ctx context.Context, planID, ownerID uuid.UUID) error {
+	if _, err := s.ownedPlan(ctx, planID, ownerID); err != nil {
+		return err
+	}
+	return s.planRepo.Delete(ctx, planID)
+}
+
+func (s *MaintenancePlanService) ownedPlan(ctx context.Context, planID, ownerID uuid.UUID) (*domain.MaintenancePlan, error) {
+	plan, err := s.planRepo.GetByID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.OwnerID != ownerID {
+		return nil, domain.ErrUnauthorized
+	}
+	return plan, nil
+}
+
+// checkNow checks a plan the owner just saved, unless the scheduler holds
+// it, and returns the plan as it stands after the check
+func (s *MaintenancePlanService) checkNow(ctx context.Context, plan *domain.MaintenancePlan) *domain.MaintenancePlan {
+	claimed, err := s.planRepo.Claim(ctx, plan.ID, time.Now().Add(planCheckLease))
+	if err != nil || claimed == nil {
+		return plan
+	}
+	s.check(ctx, claimed)
+	return claimed
+}
+
+// check brings a claimed plan up to date and records the outcome on it,
+// releasing the claim
+func (s *MaintenancePlanService) check(ctx context.Context, plan *domain.MaintenancePlan) error {
+	now := time.Now()
+	err := s.scheduleDue(ctx, plan, now)
+
+	plan.LastCheckedAt = &now
+	plan.LastError = ""
+	if err != nil {
+		plan.LastError = err.Error()
+	}
+	if saveErr := s.planRepo.SaveCheck(ctx, plan); saveErr != nil {
+		return saveErr
+	}
+	return err
+}
+
+// scheduleDue notes when the plan's outstanding maintenance was completed
+// and, once the next maintenance is due within the plan's lead time, blocks
+// the first free days from the due date for it. On an item with units the
+// work goes to the unit due first, one unit at a time.
+func (s *MaintenancePlanService) scheduleDue(ctx context.Context, plan *domain.MaintenancePlan, now time.Time) error {
+	if plan.PendingLogID != nil {
+		log, err := s.maintenanceRepo.GetByID(ctx, *plan.PendingLogID)
+		switch {
+		case err == domain.ErrMaintenanceNotFound:
+			plan.PendingLogID = nil
+		case err != nil:
+			return err
+		case log.Status != domain.MaintenanceCompleted:
+			return nil
+		default:
+			plan.RecordService(log.UnitID, *log.CompletedAt)
+			plan.PendingLogID = nil
+		}
+	}
+	if !plan.IsActive {
+		return nil
+	}
+
+	item, err := s.inventoryService.itemRepo.GetByID(ctx, plan.RentalItemID)
+	if err != nil {
+		return err
+	}
+	units := item.ActiveUnits()
+	slots, err := s.usageSlots(ctx, plan, units, now)
+	if err != nil {
+		return err
+	}
+	forecast := domain.NextDue(plan.Forecasts(units, slots, now))
+	if forecast == nil || forecast.DueAt.After(now.AddDate(0, 0, plan.LeadDays)) {
+		return nil
+	}
+
+	from := *forecast.DueAt
+	if from.Before(now) {
+		from = now
+	}
+	window, err := s.availabilityRepo.GetOverlapping(ctx, plan.RentalItemID, domain.StartOfDay(from), domain.StartOfDay(from).AddDate(0, 0, domain.MaxCalendarDays))
+	if err != nil {
+		return err
+	}
+	var start time.Time
+	if forecast.UnitID != nil {
+		start = domain.NextFreeUnitWindow(from, plan.DurationDays, window, item.UnitCapacity(), *forecast.UnitID)
+	} else {
+		start = domain.NextFreeWindow(from, plan.DurationDays, window)
+	}
+	end := start.AddDate(0, 0, plan.DurationDays)
+
+	log := domain.NewMaintenanceLog(plan.RentalItemID, plan.MaintenanceType, plan.Description, start, plan.EstimatedCost)
+	log.EndDate = &end
+	log.UnitID = forecast.UnitID
+	log.PlanID = &plan.ID
+	if err := s.inventoryService.scheduleMaintenance(ctx, plan.OwnerID, log); err != nil {
+		return err
+	}
+	plan.PendingLogID = &log.ID
+	return nil
+}
+
+// usageSlots returns the item's slots from the earliest last service of it or
+// its units up to the end of the bookable window
+func (s *MaintenancePlanService) usageSlots(ctx context.Context, plan *domain.MaintenancePlan, units []domain.ItemUnit, now time.Time) ([]*domain.AvailabilitySlot, error) {
+	return s.availabilityRepo.GetOverlapping(ctx, plan.RentalItemID, plan.EarliestService(units), now.AddDate(0, 0, domain.MaxCalendarDays))
+}
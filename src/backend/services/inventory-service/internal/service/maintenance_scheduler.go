@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rs/zerolog"
+)
+
+// planCheckBatchSize caps how many plans are checked per run. Plans are
+// claimed one at a time, so several service instances can share the work.
+const planCheckBatchSize = 100
+
+// MaintenanceScheduler periodically checks preventive maintenance plans,
+// scheduling maintenance as it falls due so its dates are blocked before
+// renters can book them
+type MaintenanceScheduler struct {
+	planService *MaintenancePlanService
+	log         zerolog.Logger
+}
+
+func NewMaintenanceScheduler(planService *MaintenancePlanService) *MaintenanceScheduler {
+	return &MaintenanceScheduler{
+		planService: planService,
+		log:         logger.NewLogger("maintenance-scheduler"),
+	}
+}
+
+// Run checks plans not checked within the last interval, now and then every
+// interval until ctx is done
+func (m *MaintenanceScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m.checkDue(ctx, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *MaintenanceScheduler) checkDue(ctx context.Context, interval time.Duration) {
+	s := m.planService
+
+	before := time.Now().Add(-interval)
+	for i := 0; i < planCheckBatchSize && ctx.Err() == nil; i++ {
+		plan, err := s.planRepo.ClaimDue(ctx, before, time.Now().Add(planCheckLease))
+		if err != nil {
+			m.log.Error().Err(err).Msg("Failed to claim a maintenance plan due for a check")
+			return
+		}
+		if plan == nil {
+			return
+		}
+
+		if err := s.check(ctx, plan); err != nil {
+			m.log.Warn().Err(err).Str("plan_id", plan.ID.String()).Msg("Failed to schedule planned maintenance")
+		}
+	}
+}
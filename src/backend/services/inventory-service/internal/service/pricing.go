@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+// priceSortWindow caps how many matches, in daily rate order, are priced to
+// sort a search for dates by what those dates cost. Results past it keep
+// daily rate order, so a page running over the end of the window is the
+// tail of the window followed by the first results after it.
+const priceSortWindow = 500
+
+// ListPricingRules returns the pricing rules on one of the owner's items
+func (s *InventoryService) ListPricingRules(ctx context.Context, itemID, ownerID uuid.UUID) (*domain.RentalItem, error) {
+	return s.ownedItem(ctx, itemID, ownerID)
+}
+
+// AddPricingRule adds a pricing rule to the owner's item
+func (s *InventoryService) AddPricingRule(ctx context.Context, itemID, ownerID uuid.UUID, settings domain.PricingRuleSettings) (*domain.PricingRule, error) {
+	var rule *domain.PricingRule
+	_, err := s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) (err error) {
+		rule, err = item.AddPricingRule(settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdatePricingRule replaces the settings of one of the item's pricing rules
+func (s *InventoryService) UpdatePricingRule(ctx context.Context, itemID, ownerID, ruleID uuid.UUID, settings domain.PricingRuleSettings) (*domain.PricingRule, error) {
+	var rule *domain.PricingRule
+	_, err := s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) (err error) {
+		rule, err = item.UpdatePricingRule(ruleID, settings)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// RemovePricingRule drops one of the item's pricing rules
+func (s *InventoryService) RemovePricingRule(ctx context.Context, itemID, ownerID, ruleID uuid.UUID) (*domain.RentalItem, error) {
+	return s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) error {
+		return item.RemovePricingRule(ruleID)
+	})
+}
+
+// PriceRental works out what renting the item over the date range costs if
+// booked now, day by day under the item's rates and pricing rules
+func (s *InventoryService) PriceRental(ctx context.Context, itemID uuid.UUID, startDate, endDate time.Time) (*domain.RentalPrice, error) {
+	if !endDate.After(startDate) {
+		return nil, domain.ErrInvalidDateRange
+	}
+	if endDate.Sub(startDate) > domain.MaxCalendarDays*24*time.Hour {
+		return nil, domain.ErrRangeTooLong
+	}
+
+	item, err := s.itemRepo.GetByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return item.Price(startDate, endDate, time.Now()), nil
+}
+
+// priceSortFetch says which results to fetch for a page: everything from the
+// start of the price sort window to the end of the page if the page starts
+// inside the window and is sorted by what the searched dates cost,
+// otherwise just the page, already in order
+func priceSortFetch(filters repository.ItemFilters, offset, limit int) (window bool, fetchOffset, fetchLimit int) {
+	if filters.AvailableFrom == nil || filters.AvailableTo == nil || filters.SortBy == nil || offset >= priceSortWindow {
+		return false, offset, limit
+	}
+	switch *filters.SortBy {
+	case "price_low", "price_high":
+		if offset+limit > priceSortWindow {
+			return true, 0, offset + limit
+		}
+		return true, 0, priceSortWindow
+	}
+	return false, offset, limit
+}
+
+// quotePrices fills in what the searched dates cost for each item, if the
+// search is for dates. When the items start at the price sort window, those
+// within it are sorted by that price and the whole lot cut down to the page.
+func quotePrices(items []*domain.RentalItem, filters repository.ItemFilters, window bool, offset, limit int) []*domain.RentalItem {
+	if filters.AvailableFrom == nil || filters.AvailableTo == nil {
+		return items
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		total := item.Price(*filters.AvailableFrom, *filters.AvailableTo, now).Total
+		item.QuotedPrice = &total
+	}
+	if !window {
+		return items
+	}
+
+	sorted := items
+	if len(sorted) > priceSortWindow {
+		sorted = sorted[:priceSortWindow]
+	}
+	descending := *filters.SortBy == "price_high"
+	sort.SliceStable(sorted, func(a, b int) bool {
+		if descending {
+			return *sorted[a].QuotedPrice > *sorted[b].QuotedPrice
+		}
+		return *sorted[a].QuotedPrice < *sorted[b].QuotedPrice
+	})
+
+	if offset >= len(items) {
+		return []*domain.RentalItem{}
+	}
+	end := offset + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[offset:end]
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+func TestPriceSortPagesCoverEveryMatchOnce(t *testing.T) {
+	// Matches in the order the repository returns them, with prices that
+	// don't follow that order
+	matches := make([]*domain.RentalItem, priceSortWindow+40)
+	for n := range matches {
+		matches[n] = &domain.RentalItem{ID: uuid.New(), DailyRate: float64(n*37%len(matches) + 1)}
+	}
+
+	from := time.Now().UTC().AddDate(0, 1, 0).Truncate(24 * time.Hour)
+	to := from.AddDate(0, 0, 2)
+	sortBy := "price_low"
+	filters := repository.ItemFilters{AvailableFrom: &from, AvailableTo: &to, SortBy: &sortBy}
+
+	for _, pageSize := range []int{15, 20, 100} {
+		seen := map[uuid.UUID]int{}
+		var prices []float64
+		for offset := 0; offset < len(matches); offset += pageSize {
+			window, fetchOffset, fetchLimit := priceSortFetch(filters, offset, pageSize)
+			end := fetchOffset + fetchLimit
+			if end > len(matches) {
+				end = len(matches)
+			}
+			fetched := append([]*domain.RentalItem(nil), matches[fetchOffset:end]...)
+
+			for _, item := range quotePrices(fetched, filters, window, offset, pageSize) {
+				seen[item.ID]++
+				prices = append(prices, *item.QuotedPrice)
+			}
+		}
+
+		if len(seen) != len(matches) {
+			t.Errorf("page size %d: %d of %d matches shown", pageSize, len(seen), len(matches))
+		}
+		for id, count := range seen {
+			if count != 1 {
+				t.Errorf("page size %d: match %s shown %d times", pageSize, id, count)
+			}
+		}
+		for n := 1; n < priceSortWindow && n < len(prices); n++ {
+			if prices[n] < prices[n-1] {
+				t.Errorf("page size %d: result %d costs %v, less than %v before it", pageSize, n, prices[n], prices[n-1])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/clients"
+	"github.com/rentalflow/inventory-service/internal/domain"
+	"github.com/rentalflow/inventory-service/internal/repository"
+)
+
+// SchemaService manages the specification schemas admins define per category
+type SchemaService struct {
+	schemaRepo   repository.SpecSchemaRepository
+	categoryRepo repository.CategoryRepository
+	userClient   *clients.UserClient
+}
+
+// NewSchemaService creates a new schema service
+func NewSchemaService(schemaRepo repository.SpecSchemaRepository, categoryRepo repository.CategoryRepository, userClient *clients.UserClient) *SchemaService {
+	return &SchemaService{
+		schemaRepo:   schemaRepo,
+		categoryRepo: categoryRepo,
+		userClient:   userClient,
+	}
+}
+
+// ListSchemas returns the schemas for a category, or all of them if category is nil
+func (s *SchemaService) ListSchemas(ctx context.Context, category *domain.ItemCategory) ([]*domain.SpecSchema, error) {
+	if category != nil {
+		if _, err := s.categoryRepo.GetBySlug(ctx, string(*category)); err != nil {
+			return nil, err
+		}
+	}
+	return s.schemaRepo.List(ctx, category)
+}
+
+// SaveSchema creates or replaces the schema for a root category, or a
+// category below it. Existing items are checked against it the next time
+// their specifications change.
+func (s *SchemaService) SaveSchema(ctx context.Context, adminID uuid.UUID, category domain.ItemCategory, subcategory string, attributes []domain.AttributeSpec) (*domain.SpecSchema, error) {
+	if err := requireAdmin(ctx, s.userClient, adminID); err != nil {
+		return nil, err
+	}
+
+	tree, err := loadCategoryTree(ctx, s.categoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := tree.Get(string(category))
+	if !ok || root.Parent != "" || (subcategory != "" && !tree.IsDescendant(subcategory, root.Slug)) {
+		return nil, domain.ErrInvalidCategory
+	}
+
+	schema, err := s.schemaRepo.Get(ctx, category, subcategory)
+	if err == domain.ErrSchemaNotFound {
+		schema, err = domain.NewSpecSchema(category, subcategory, attributes, adminID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.schemaRepo.Create(ctx, schema); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := schema.SetAttributes(attributes, adminID); err != nil {
+		return nil, err
+	}
+	if err := s.schemaRepo.Update(ctx, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// DeleteSchema removes a category or subcategory schema, so its items'
+// specifications are no longer checked
+func (s *SchemaService) DeleteSchema(ctx context.Context, adminID uuid.UUID, category domain.ItemCategory, subcategory string) error {
+	if err := requireAdmin(ctx, s.userClient, adminID); err != nil {
+		return err
+	}
+
+	schema, err := s.schemaRepo.Get(ctx, category, subcategory)
+	if err != nil {
+		return err
+	}
+	return s.schemaRepo.Delete(ctx, schema.ID)
+}
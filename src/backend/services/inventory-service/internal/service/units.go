@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rentalflow/inventory-service/internal/domain"
+)
+
+// AddUnits adds identical units to the owner's item, raising how many
+// bookings it can take at once
+func (s *InventoryService) AddUnits(ctx context.Context, itemID, ownerID uuid.UUID, count int, label, serialNumber string) (*domain.RentalItem, []domain.ItemUnit, error) {
+	var added []domain.ItemUnit
+	item, err := s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) (err error) {
+		added, err = item.AddUnits(count, label, serialNumber)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return item, added, nil
+}
+
+// UpdateUnit changes one of the item's units. A unit can't be taken out of
+// service while it is assigned to a booking or maintenance still ahead, or
+// while the item's reservations need every unit it has.
+func (s *InventoryService) UpdateUnit(ctx context.Context, itemID, ownerID, unitID uuid.UUID, label, serialNumber string, isActive bool) (*domain.ItemUnit, error) {
+	var unit *domain.ItemUnit
+	_, err := s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) error {
+		current, err := item.Unit(unitID)
+		if err != nil {
+			return err
+		}
+		withdrawing := current.IsActive && !isActive
+
+		unit, err = item.UpdateUnit(unitID, label, serialNumber, isActive)
+		if err != nil {
+			return err
+		}
+		if withdrawing {
+			return s.checkUnitWithdrawal(ctx, item, unitID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+// RemoveUnit deletes one of the item's units, under the same conditions as
+// taking it out of service
+func (s *InventoryService) RemoveUnit(ctx context.Context, itemID, ownerID, unitID uuid.UUID) (*domain.RentalItem, error) {
+	return s.modifyItem(ctx, itemID, ownerID, func(item *domain.RentalItem) error {
+		if err := item.RemoveUnit(unitID); err != nil {
+			return err
+		}
+		return s.checkUnitWithdrawal(ctx, item, unitID)
+	})
+}
+
+// checkUnitWithdrawal makes sure the item, already without the unit, can
+// still hold its reservations from now on
+func (s *InventoryService) checkUnitWithdrawal(ctx context.Context, item *domain.RentalItem, unitID uuid.UUID) error {
+	now := time.Now()
+	until := now.AddDate(0, 0, domain.MaxCalendarDays)
+	slots, err := s.availabilityRepo.GetOverlapping(ctx, item.ID, now, until)
+	if err != nil {
+		return err
+	}
+
+	for _, slot := range slots {
+		if slot.UnitID != nil && *slot.UnitID == unitID {
+			return domain.ErrUnitInUse
+		}
+	}
+	if domain.PeakReservations(slots, now, until) > item.UnitCapacity() {
+		return domain.ErrUnitInUse
+	}
+	return nil
+}
+
+// AssignUnit gives a booking the unit handed over at pickup: unitID if given,
+// otherwise the first active unit free for the booking's dates. A booking
+// keeps the unit it already has unless another is asked for, so retries are
+// safe. Items without units have nothing to assign and return a nil unit.
+func (s *InventoryService) AssignUnit(ctx context.Context, bookingID uuid.UUID, unitID *uuid.UUID) (*domain.AvailabilitySlot, error) {
+	slot, err := s.availabilityRepo.GetByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.itemRepo.GetByID(ctx, slot.RentalItemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(item.Units) == 0 {
+		return slot, nil
+	}
+	if slot.UnitID != nil && (unitID == nil || *unitID == *slot.UnitID) {
+		return slot, nil
+	}
+
+	candidates := item.ActiveUnits()
+	if unitID != nil {
+		unit, err := item.Unit(*unitID)
+		if err != nil {
+			return nil, err
+		}
+		if !unit.IsActive {
+			return nil, domain.ErrNoFreeUnit
+		}
+		candidates = []domain.ItemUnit{*unit}
+	}
+
+	if err := s.availabilityRepo.AssignUnit(ctx, slot, candidates); err != nil {
+		return nil, err
+	}
+	return slot, nil
+}
@@ -72,8 +72,8 @@ func main() {
 			}
 
 			// Subscribe
-			err = broker.Subscribe(q.Name, func(body []byte) error {
-				return notifService.HandleBookingEvent(context.Background(), body)
+			err = broker.SubscribeRouted(q.Name, func(routingKey string, body []byte) error {
+				return notifService.HandleBookingEvent(context.Background(), routingKey, body)
 			})
 			if err != nil {
 				log.Error().Err(err).Msg("Failed to subscribe to booking events")
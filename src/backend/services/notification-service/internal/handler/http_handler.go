@@ -28,7 +28,7 @@ func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/health", h.Health)
 	fmt.Println("Registering /api/notifications/booking-created")
 	mux.HandleFunc("/api/notifications/booking-created", h.SendBookingCreated)
-	fmt.Println("Registering /api/notifications/payment-success", h.SendPaymentSuccess)
+	fmt.Println("Registering /api/notifications/payment-success")
 	mux.HandleFunc("/api/notifications/payment-success", h.SendPaymentSuccess)
 	fmt.Println("Registering /api/notifications/review-received")
 	mux.HandleFunc("/api/notifications/review-received", h.SendReviewReceived)
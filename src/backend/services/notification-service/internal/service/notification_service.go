@@ -21,13 +21,18 @@ func NewNotificationService(notificationRepo repository.NotificationRepository,
 	}
 }
 
-func (s *NotificationService) HandleBookingEvent(ctx context.Context, eventData []byte) error {
+// HandleBookingEvent notifies the parties of a booking event. The routing key
+// says what happened, since several events can carry the same status.
+func (s *NotificationService) HandleBookingEvent(ctx context.Context, routingKey string, eventData []byte) error {
 	var event struct {
-		ID           uuid.UUID `json:"id"`
-		RenterID     uuid.UUID `json:"renter_id"`
-		OwnerID      uuid.UUID `json:"owner_id"`
-		RentalItemID uuid.UUID `json:"rental_item_id"`
-		Status       string    `json:"status"`
+		ID            uuid.UUID `json:"id"`
+		RenterID      uuid.UUID `json:"renter_id"`
+		OwnerID       uuid.UUID `json:"owner_id"`
+		RentalItemID  uuid.UUID `json:"rental_item_id"`
+		Status        string    `json:"status"`
+		StatusHistory []struct {
+			From string `json:"from"`
+		} `json:"status_history"`
 	}
 
 	if err := json.Unmarshal(eventData, &event); err != nil {
@@ -38,19 +43,49 @@ func (s *NotificationService) HandleBookingEvent(ctx context.Context, eventData
 	message := ""
 	var targetUserID uuid.UUID
 
-	switch event.Status {
-	case "pending": // booking.created
+	switch routingKey {
+	case "booking.created":
 		targetUserID = event.OwnerID
 		title = "New Booking Request"
 		message = "You have a new booking request for your item."
-	case "confirmed": // booking.confirmed
+	case "booking.confirmed":
 		targetUserID = event.RenterID
 		title = "Booking Confirmed"
 		message = "Your booking request has been confirmed by the owner."
-	case "cancelled": // booking.cancelled
+	case "booking.cancelled":
 		targetUserID = event.RenterID
 		title = "Booking Cancelled"
 		message = "Your booking has been cancelled."
+	case "booking.expired":
+		// Both sides are told, with the reason depending on who missed the deadline
+		previous := ""
+		if n := len(event.StatusHistory); n > 0 {
+			previous = event.StatusHistory[n-1].From
+		}
+		return s.notifyBookingExpired(ctx, event.RenterID, event.OwnerID, previous)
+	case "booking.modification_requested":
+		targetUserID = event.OwnerID
+		title = "Booking Change Requested"
+		message = "A renter has asked to change the dates of their booking."
+	case "booking.modified":
+		targetUserID = event.RenterID
+		title = "Booking Change Accepted"
+		message = "The owner accepted your requested booking change."
+	case "booking.modification_rejected":
+		targetUserID = event.RenterID
+		title = "Booking Change Declined"
+		message = "The owner declined your requested booking change."
+	case "booking.dispute_opened":
+		targetUserID = event.RenterID
+		title = "Damage Claim Filed"
+		message = "The owner reported damage at check-in and claimed part of your security deposit. Please respond to the claim."
+	case "booking.dispute_responded":
+		targetUserID = event.OwnerID
+		title = "Damage Claim Response"
+		message = "The renter has responded to your damage claim."
+	case "booking.dispute_resolved":
+		return s.notifyBothParties(ctx, event.RenterID, event.OwnerID, "Damage Claim Resolved",
+			"The damage claim has been resolved and the security deposit settled.")
 	}
 
 	if targetUserID != uuid.Nil {
@@ -61,6 +96,29 @@ func (s *NotificationService) HandleBookingEvent(ctx context.Context, eventData
 	return nil
 }
 
+func (s *NotificationService) notifyBookingExpired(ctx context.Context, renterID, ownerID uuid.UUID, previousStatus string) error {
+	renterMessage := "Your booking request expired because the owner did not respond in time."
+	ownerMessage := "A booking request for your item expired because it was not answered in time."
+	if previousStatus == "confirmed" {
+		renterMessage = "Your booking expired because payment was not completed in time."
+		ownerMessage = "A confirmed booking for your item expired because the renter did not pay in time."
+	}
+
+	if _, err := s.SendNotification(ctx, renterID, "booking", "Booking Expired", renterMessage, domain.ChannelInApp); err != nil {
+		return err
+	}
+	_, err := s.SendNotification(ctx, ownerID, "booking", "Booking Expired", ownerMessage, domain.ChannelInApp)
+	return err
+}
+
+func (s *NotificationService) notifyBothParties(ctx context.Context, renterID, ownerID uuid.UUID, title, message string) error {
+	if _, err := s.SendNotification(ctx, renterID, "booking", title, message, domain.ChannelInApp); err != nil {
+		return err
+	}
+	_, err := s.SendNotification(ctx, ownerID, "booking", title, message, domain.ChannelInApp)
+	return err
+}
+
 func (s *NotificationService) SendNotification(ctx context.Context, userID uuid.UUID, notifType, title, message string, channel domain.NotificationChannel) (*domain.Notification, error) {
 	notification := domain.NewNotification(userID, notifType, title, message, channel)
 	if err := s.notificationRepo.Create(ctx, notification); err != nil {
@@ -16,6 +16,7 @@ import (
 	"github.com/rentalflow/payment-service/internal/service"
 	"github.com/rentalflow/rentalflow/pkg/database"
 	"github.com/rentalflow/rentalflow/pkg/logger"
+	"github.com/rentalflow/rentalflow/pkg/messaging"
 )
 
 func main() {
@@ -52,7 +53,42 @@ func main() {
 
 	paymentRepo := repository.NewMongoPaymentRepository(client.DB)
 	paymentService := service.NewPaymentService(paymentRepo, chapaClient)
-	httpHandler := handler.NewHTTPHandler(paymentService)
+
+	// Initialize messaging
+	brokerUrl := fmt.Sprintf("amqp://%s:%s@%s:%d/",
+		cfg.RabbitMQ.User, cfg.RabbitMQ.Password, cfg.RabbitMQ.Host, cfg.RabbitMQ.Port)
+	broker, err := messaging.NewMessageBroker(brokerUrl)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to connect to RabbitMQ, running without messaging")
+	} else {
+		defer broker.Close()
+		log.Info().Str("url", brokerUrl).Msg("Connected to RabbitMQ")
+
+		if err := broker.DeclareExchange("booking_events", "topic"); err != nil {
+			log.Error().Err(err).Msg("Failed to declare exchange")
+		}
+
+		// Refunds must not be lost, so cancellations are acknowledged only
+		// once handled and kept in a dead-letter queue if handling keeps failing
+		q, err := broker.DeclareQueueWithDeadLetter("payment_booking_cancellations")
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to declare queue")
+		} else {
+			if err := broker.BindQueue(q.Name, "booking.cancelled", "booking_events"); err != nil {
+				log.Error().Err(err).Msg("Failed to bind queue")
+			}
+
+			err = broker.SubscribeAcked(q.Name, func(body []byte) error {
+				return paymentService.HandleBookingCancelled(context.Background(), body)
+			})
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to subscribe to booking events")
+			} else {
+				log.Info().Msg("Subscribed to booking cancellations")
+			}
+		}
+	}
+	httpHandler := handler.NewHTTPHandler(paymentService, cfg.Services.Token)
 
 	httpAddr := fmt.Sprintf(":%d", cfg.HTTPPort)
 	mux := http.NewServeMux()
@@ -10,4 +10,5 @@ var (
 	ErrRefundNotAllowed     = errors.New("refund not allowed for this payment")
 	ErrInvalidPaymentMethod = errors.New("invalid payment method")
 	ErrUnauthorized         = errors.New("unauthorized to perform this action")
+	ErrDepositNotHeld       = errors.New("no security deposit is held for this payment")
 )
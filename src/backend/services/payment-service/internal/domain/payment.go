@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,11 +10,22 @@ import (
 type PaymentStatus string
 
 const (
-	StatusPending    PaymentStatus = "pending"
-	StatusProcessing PaymentStatus = "processing"
-	StatusCompleted  PaymentStatus = "completed"
-	StatusFailed     PaymentStatus = "failed"
-	StatusRefunded   PaymentStatus = "refunded"
+	StatusPending           PaymentStatus = "pending"
+	StatusProcessing        PaymentStatus = "processing"
+	StatusCompleted         PaymentStatus = "completed"
+	StatusFailed            PaymentStatus = "failed"
+	StatusRefunded          PaymentStatus = "refunded"
+	StatusPartiallyRefunded PaymentStatus = "partially_refunded"
+	StatusCancelled         PaymentStatus = "cancelled"
+)
+
+// Deposit statuses
+const (
+	DepositPending           = "pending"
+	DepositHeld              = "held"
+	DepositReleased          = "released"
+	DepositCaptured          = "captured"
+	DepositPartiallyCaptured = "partially_captured"
 )
 
 type PaymentMethod string
@@ -26,27 +38,34 @@ const (
 )
 
 type Payment struct {
-	ID                    uuid.UUID     `json:"id" bson:"_id"`
-	BookingID             uuid.UUID     `json:"booking_id" bson:"booking_id"`
-	UserID                uuid.UUID     `json:"user_id" bson:"user_id"`
-	PaymentType           string        `json:"payment_type" bson:"payment_type"`
-	Amount                float64       `json:"amount" bson:"amount"`
-	Currency              string        `json:"currency" bson:"currency"`
-	Status                PaymentStatus `json:"status" bson:"status"`
-	Method                PaymentMethod `json:"method" bson:"method"`
-	RentalFee             float64       `json:"rental_fee" bson:"rental_fee"`
-	SecurityDeposit       float64       `json:"security_deposit" bson:"security_deposit"`
-	ServiceFee            float64       `json:"service_fee" bson:"service_fee"`
-	AdditionalServices    float64       `json:"additional_services" bson:"additional_services"`
-	Tax                   float64       `json:"tax" bson:"tax"`
-	DepositHeld           bool          `json:"deposit_held" bson:"deposit_held"`
-	DepositStatus         string        `json:"deposit_status" bson:"deposit_status"`
-	ProviderName          string        `json:"provider_name" bson:"provider_name"`
-	ProviderTransactionID string        `json:"provider_transaction_id" bson:"provider_transaction_id"`
-	CheckoutURL           string        `json:"checkout_url" bson:"checkout_url"`
-	ReceiptURL            string        `json:"receipt_url" bson:"receipt_url"`
-	CreatedAt             time.Time     `json:"created_at" bson:"created_at"`
-	UpdatedAt             time.Time     `json:"updated_at" bson:"updated_at"`
+	ID                 uuid.UUID     `json:"id" bson:"_id"`
+	BookingID          uuid.UUID     `json:"booking_id" bson:"booking_id"`
+	UserID             uuid.UUID     `json:"user_id" bson:"user_id"`
+	PaymentType        string        `json:"payment_type" bson:"payment_type"`
+	Amount             float64       `json:"amount" bson:"amount"`
+	Currency           string        `json:"currency" bson:"currency"`
+	Status             PaymentStatus `json:"status" bson:"status"`
+	Method             PaymentMethod `json:"method" bson:"method"`
+	RentalFee          float64       `json:"rental_fee" bson:"rental_fee"`
+	SecurityDeposit    float64       `json:"security_deposit" bson:"security_deposit"`
+	ServiceFee         float64       `json:"service_fee" bson:"service_fee"`
+	AdditionalServices float64       `json:"additional_services" bson:"additional_services"`
+	Lines              []PaymentLine `json:"lines,omitempty" bson:"lines,omitempty"`
+	Tax                float64       `json:"tax" bson:"tax"`
+	RefundedAmount     float64       `json:"refunded_amount" bson:"refunded_amount"`
+	DepositHeld        bool          `json:"deposit_held" bson:"deposit_held"`
+	DepositStatus      string        `json:"deposit_status" bson:"deposit_status"`
+	DepositCaptured    float64       `json:"deposit_captured" bson:"deposit_captured"`
+	// CancellationRefund is what was refunded when the booking was cancelled,
+	// which happens once per booking. It is set with the refund so that a
+	// redelivered cancellation doesn't refund the payment again.
+	CancellationRefund    *float64  `json:"cancellation_refund,omitempty" bson:"cancellation_refund,omitempty"`
+	ProviderName          string    `json:"provider_name" bson:"provider_name"`
+	ProviderTransactionID string    `json:"provider_transaction_id" bson:"provider_transaction_id"`
+	CheckoutURL           string    `json:"checkout_url" bson:"checkout_url"`
+	ReceiptURL            string    `json:"receipt_url" bson:"receipt_url"`
+	CreatedAt             time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at" bson:"updated_at"`
 }
 
 func NewPayment(bookingID, userID uuid.UUID, amount float64, method PaymentMethod) *Payment {
@@ -62,3 +81,125 @@ func NewPayment(bookingID, userID uuid.UUID, amount float64, method PaymentMetho
 		UpdatedAt: time.Now(),
 	}
 }
+
+// PaymentLine is one line of what a payment is for, such as the rental days
+// or an add-on
+type PaymentLine struct {
+	Description string     `json:"description" bson:"description"`
+	Quantity    int        `json:"quantity" bson:"quantity"`
+	UnitPrice   float64    `json:"unit_price" bson:"unit_price"`
+	Amount      float64    `json:"amount" bson:"amount"`
+	AddOnID     *uuid.UUID `json:"add_on_id,omitempty" bson:"add_on_id,omitempty"`
+}
+
+// Breakdown itemises a booking payment apart from its security deposit:
+// the rental itself, add-ons and the platform's service fee
+type Breakdown struct {
+	RentalFee          float64       `json:"rental_fee"`
+	AdditionalServices float64       `json:"additional_services"`
+	ServiceFee         float64       `json:"service_fee"`
+	Lines              []PaymentLine `json:"lines"`
+}
+
+// ApplyBreakdown records what the payment is for. Together with the security
+// deposit the breakdown must come to the payment's amount.
+func (p *Payment) ApplyBreakdown(b Breakdown) error {
+	if b.RentalFee < 0 || b.AdditionalServices < 0 || b.ServiceFee < 0 {
+		return ErrInvalidAmount
+	}
+	if math.Abs(b.RentalFee+b.AdditionalServices+b.ServiceFee+p.SecurityDeposit-p.Amount) > 0.01 {
+		return ErrInvalidAmount
+	}
+
+	p.RentalFee = b.RentalFee
+	p.AdditionalServices = b.AdditionalServices
+	p.ServiceFee = b.ServiceFee
+	p.Lines = b.Lines
+	return nil
+}
+
+// Refundable returns how much of the payment can still be refunded
+func (p *Payment) Refundable() float64 {
+	if p.Status != StatusCompleted && p.Status != StatusPartiallyRefunded {
+		return 0
+	}
+	return p.Amount - p.RefundedAmount
+}
+
+// ApplyRefund records a refund against the payment
+func (p *Payment) ApplyRefund(amount float64) {
+	p.RefundedAmount += amount
+	if p.RefundedAmount >= p.Amount {
+		p.Status = StatusRefunded
+		// A fully refunded payment returns the deposit with it
+		p.releaseDeposit()
+	} else {
+		p.Status = StatusPartiallyRefunded
+	}
+}
+
+// RefundCancellation refunds amount for the booking's cancellation and
+// records that the cancellation was refunded. A cancellation's refund always
+// includes the deposit, so the deposit is released even if the rest of the
+// payment is only partly refunded and can no longer be captured.
+func (p *Payment) RefundCancellation(amount float64) {
+	p.ApplyRefund(amount)
+	p.releaseDeposit()
+	p.CancellationRefund = &amount
+}
+
+// releaseDeposit marks a held deposit as returned to the renter
+func (p *Payment) releaseDeposit() {
+	if p.DepositHeld {
+		p.DepositHeld = false
+		p.DepositStatus = DepositReleased
+	}
+}
+
+// HoldDeposit marks the security deposit as held once the payment completes
+func (p *Payment) HoldDeposit() {
+	if p.SecurityDeposit > 0 && p.Status == StatusCompleted && p.DepositStatus == DepositPending {
+		p.DepositHeld = true
+		p.DepositStatus = DepositHeld
+	}
+}
+
+// DepositSettled reports whether the deposit was released or captured
+func (p *Payment) DepositSettled() bool {
+	switch p.DepositStatus {
+	case DepositReleased, DepositCaptured, DepositPartiallyCaptured:
+		return true
+	}
+	return false
+}
+
+// SettleDeposit captures part of the held deposit for the owner and refunds the
+// rest to the renter. It returns the amount released.
+func (p *Payment) SettleDeposit(capture float64) (float64, error) {
+	if !p.DepositHeld {
+		return 0, ErrDepositNotHeld
+	}
+	if capture < 0 || capture > p.SecurityDeposit {
+		return 0, ErrInvalidAmount
+	}
+
+	release := p.SecurityDeposit - capture
+	if refundable := p.Refundable(); release > refundable {
+		release = refundable
+	}
+
+	p.DepositHeld = false
+	p.DepositCaptured = capture
+	if release > 0 {
+		p.ApplyRefund(release)
+	}
+	switch {
+	case capture == 0:
+		p.DepositStatus = DepositReleased
+	case capture == p.SecurityDeposit:
+		p.DepositStatus = DepositCaptured
+	default:
+		p.DepositStatus = DepositPartiallyCaptured
+	}
+	return release, nil
+}
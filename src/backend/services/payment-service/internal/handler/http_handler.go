@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"net/http"
 
@@ -9,12 +10,16 @@ import (
 	"github.com/rentalflow/payment-service/internal/service"
 )
 
+// serviceTokenHeader carries the token other services call internal endpoints with
+const serviceTokenHeader = "X-Service-Token"
+
 type HTTPHandler struct {
 	paymentService *service.PaymentService
+	serviceToken   string
 }
 
-func NewHTTPHandler(paymentService *service.PaymentService) *HTTPHandler {
-	return &HTTPHandler{paymentService: paymentService}
+func NewHTTPHandler(paymentService *service.PaymentService, serviceToken string) *HTTPHandler {
+	return &HTTPHandler{paymentService: paymentService, serviceToken: serviceToken}
 }
 
 func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
@@ -24,10 +29,25 @@ func (h *HTTPHandler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/payments", h.GetPayment)
 	mux.HandleFunc("/api/payments/booking", h.GetBookingPayments)
 	mux.HandleFunc("/api/payments/refund", h.ProcessRefund)
+	mux.HandleFunc("/internal/payments/void", h.internal(h.VoidBookingPayments))
+	mux.HandleFunc("/internal/payments/deposit/settle", h.internal(h.SettleDeposit))
 	mux.HandleFunc("/api/payments/status", h.UpdateStatus)
 	mux.HandleFunc("/api/payments/verify", h.VerifyPayment)
 }
 
+// internal guards endpoints only other services may call. They are served
+// outside /api, which the gateway forwards, and need the shared service token.
+func (h *HTTPHandler) internal(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(serviceTokenHeader)
+		if h.serviceToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.serviceToken)) != 1 {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
 func (h *HTTPHandler) Health(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
@@ -45,10 +65,12 @@ func (h *HTTPHandler) InitializePayment(w http.ResponseWriter, r *http.Request)
 	}
 
 	var req struct {
-		BookingID string  `json:"booking_id"`
-		UserID    string  `json:"user_id"`
-		Amount    float64 `json:"amount"`
-		Method    string  `json:"method"`
+		BookingID       string            `json:"booking_id"`
+		UserID          string            `json:"user_id"`
+		Amount          float64           `json:"amount"`
+		SecurityDeposit float64           `json:"security_deposit"`
+		Method          string            `json:"method"`
+		Breakdown       *domain.Breakdown `json:"breakdown"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -60,7 +82,7 @@ func (h *HTTPHandler) InitializePayment(w http.ResponseWriter, r *http.Request)
 	userID, _ := uuid.Parse(req.UserID)
 	method := domain.PaymentMethod(req.Method)
 
-	payment, err := h.paymentService.InitializePayment(r.Context(), bookingID, userID, req.Amount, method)
+	payment, err := h.paymentService.InitializePayment(r.Context(), bookingID, userID, req.Amount, req.SecurityDeposit, method, req.Breakdown)
 	if err != nil {
 		h.handleError(w, err)
 		return
@@ -141,6 +163,86 @@ func (h *HTTPHandler) ProcessRefund(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// VoidBookingPayments cancels a booking's open payments and refunds completed
+// ones. Only the booking service calls it, when a booking falls through.
+func (h *HTTPHandler) VoidBookingPayments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		BookingID string `json:"booking_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+
+	payments, err := h.paymentService.VoidBookingPayments(r.Context(), bookingID)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"voided": payments,
+		"count":  len(payments),
+	})
+}
+
+// SettleDeposit captures part of a booking's held deposit and releases the
+// rest. Only the booking service calls it, once a damage claim is settled.
+func (h *HTTPHandler) SettleDeposit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		BookingID     string  `json:"booking_id"`
+		CaptureAmount float64 `json:"capture_amount"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookingID, err := uuid.Parse(req.BookingID)
+	if err != nil {
+		http.Error(w, "Invalid booking_id", http.StatusBadRequest)
+		return
+	}
+
+	payment, settled, err := h.paymentService.SettleDeposit(r.Context(), bookingID, req.CaptureAmount)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if payment == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"settled": false})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"settled":         settled,
+		"payment_id":      payment.ID.String(),
+		"deposit_status":  payment.DepositStatus,
+		"captured_amount": payment.DepositCaptured,
+		"released_amount": payment.SecurityDeposit - payment.DepositCaptured,
+	})
+}
+
 func (h *HTTPHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -207,6 +309,8 @@ func (h *HTTPHandler) handleError(w http.ResponseWriter, err error) {
 		w.WriteHeader(http.StatusBadRequest)
 	case domain.ErrUnauthorized:
 		w.WriteHeader(http.StatusForbidden)
+	case domain.ErrDepositNotHeld:
+		w.WriteHeader(http.StatusConflict)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
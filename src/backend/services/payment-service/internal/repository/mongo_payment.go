@@ -59,6 +59,11 @@ func (r *MongoPaymentRepository) Update(ctx context.Context, payment *domain.Pay
 		"$set": bson.M{
 			"status":                  payment.Status,
 			"provider_transaction_id": payment.ProviderTransactionID,
+			"refunded_amount":         payment.RefundedAmount,
+			"deposit_held":            payment.DepositHeld,
+			"deposit_status":          payment.DepositStatus,
+			"deposit_captured":        payment.DepositCaptured,
+			"cancellation_refund":     payment.CancellationRefund,
 			"updated_at":              time.Now(),
 		},
 	}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/google/uuid"
 	"github.com/rentalflow/payment-service/internal/chapa"
@@ -22,13 +24,38 @@ func NewPaymentService(paymentRepo repository.PaymentRepository, chapaClient *ch
 	}
 }
 
-func (s *PaymentService) InitializePayment(ctx context.Context, bookingID, userID uuid.UUID, amount float64, method domain.PaymentMethod) (*domain.Payment, error) {
-	if amount <= 0 {
+// InitializePayment opens a payment for a booking. securityDeposit is the part
+// of the amount held as a deposit until the rental is checked in.
+// breakdown, if given, itemises the rest of the amount.
+func (s *PaymentService) InitializePayment(ctx context.Context, bookingID, userID uuid.UUID, amount, securityDeposit float64, method domain.PaymentMethod,
+	breakdown *domain.Breakdown) (*domain.Payment, error) {
+	if amount <= 0 || securityDeposit < 0 || securityDeposit > amount {
 		return nil, domain.ErrInvalidAmount
 	}
 
+	// Reuse an open payment for the same booking so retried requests don't
+	// create duplicate checkouts
+	existing, err := s.paymentRepo.GetByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range existing {
+		if p.Status == domain.StatusPending && p.Amount == amount && p.SecurityDeposit == securityDeposit && p.Method == method {
+			return p, nil
+		}
+	}
+
 	payment := domain.NewPayment(bookingID, userID, amount, method)
 	payment.PaymentType = "booking"
+	if securityDeposit > 0 {
+		payment.SecurityDeposit = securityDeposit
+		payment.DepositStatus = domain.DepositPending
+	}
+	if breakdown != nil {
+		if err := payment.ApplyBreakdown(*breakdown); err != nil {
+			return nil, err
+		}
+	}
 	payment.ProviderName = string(method)
 
 	// Handle Chapa payments
@@ -88,6 +115,7 @@ func (s *PaymentService) UpdatePaymentStatus(ctx context.Context, paymentID uuid
 
 	payment.Status = status
 	payment.ProviderTransactionID = transactionID
+	payment.HoldDeposit()
 
 	if err := s.paymentRepo.Update(ctx, payment); err != nil {
 		return nil, err
@@ -109,18 +137,138 @@ func (s *PaymentService) ProcessRefund(ctx context.Context, paymentID uuid.UUID,
 		return nil, err
 	}
 
-	if payment.Status != domain.StatusCompleted {
+	refundable := payment.Refundable()
+	if refundable <= 0 {
 		return nil, domain.ErrRefundNotAllowed
 	}
 
-	if amount > payment.Amount {
+	if amount > refundable {
 		return nil, domain.ErrInvalidAmount
 	}
+	if amount <= 0 {
+		amount = refundable
+	}
 
-	payment.Status = domain.StatusRefunded
+	payment.ApplyRefund(amount)
 	if err := s.paymentRepo.Update(ctx, payment); err != nil {
 		return nil, err
 	}
 
 	return payment, nil
 }
+
+// VoidBookingPayments cancels the booking's open payments and refunds completed
+// ones. Payments already cancelled, failed or refunded are left as they are.
+func (s *PaymentService) VoidBookingPayments(ctx context.Context, bookingID uuid.UUID) ([]*domain.Payment, error) {
+	payments, err := s.paymentRepo.GetByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var voided []*domain.Payment
+	for _, payment := range payments {
+		switch payment.Status {
+		case domain.StatusPending, domain.StatusProcessing:
+			payment.Status = domain.StatusCancelled
+		case domain.StatusCompleted, domain.StatusPartiallyRefunded:
+			payment.ApplyRefund(payment.Refundable())
+		default:
+			continue
+		}
+
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return nil, err
+		}
+		voided = append(voided, payment)
+	}
+
+	return voided, nil
+}
+
+// HandleBookingCancelled refunds a cancelled booking according to the refund
+// its cancellation policy produced. Unpaid payments are simply cancelled.
+// Payments already refunded for the cancellation are skipped and count
+// towards the refund, so a redelivered event refunds only what is left.
+func (s *PaymentService) HandleBookingCancelled(ctx context.Context, eventData []byte) error {
+	var event struct {
+		ID     uuid.UUID `json:"id"`
+		Refund *struct {
+			Total float64 `json:"total"`
+		} `json:"refund"`
+	}
+
+	if err := json.Unmarshal(eventData, &event); err != nil {
+		return err
+	}
+
+	payments, err := s.paymentRepo.GetByBooking(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	remaining := 0.0
+	if event.Refund != nil {
+		remaining = event.Refund.Total
+	}
+	for _, payment := range payments {
+		if payment.CancellationRefund != nil {
+			remaining -= *payment.CancellationRefund
+		}
+	}
+
+	for _, payment := range payments {
+		if payment.CancellationRefund != nil {
+			continue
+		}
+		switch payment.Status {
+		case domain.StatusPending, domain.StatusProcessing:
+			payment.Status = domain.StatusCancelled
+		case domain.StatusCompleted, domain.StatusPartiallyRefunded:
+			amount := math.Min(remaining, payment.Refundable())
+			if amount <= 0 {
+				continue
+			}
+			payment.RefundCancellation(amount)
+			remaining -= amount
+		default:
+			continue
+		}
+
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SettleDeposit settles the security deposit held for a booking, capturing
+// capture for the owner and refunding the rest, and reports whether it did.
+// With no deposit held it returns the payment whose deposit was already
+// settled, if any, so a retried settlement can tell it went through; it
+// returns nil for bookings that never had a deposit.
+func (s *PaymentService) SettleDeposit(ctx context.Context, bookingID uuid.UUID, capture float64) (*domain.Payment, bool, error) {
+	payments, err := s.paymentRepo.GetByBooking(ctx, bookingID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var settled *domain.Payment
+	for _, payment := range payments {
+		if !payment.DepositHeld {
+			if payment.DepositSettled() {
+				settled = payment
+			}
+			continue
+		}
+		if _, err := payment.SettleDeposit(capture); err != nil {
+			return nil, false, err
+		}
+		if err := s.paymentRepo.Update(ctx, payment); err != nil {
+			return nil, false, err
+		}
+		return payment, true, nil
+	}
+
+	return settled, false, nil
+}
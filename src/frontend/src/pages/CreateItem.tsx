@@ -2,7 +2,6 @@ import React, { useState, useRef } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { itemsApi } from '../services/api';
 import { useAuth } from '../context/AuthContext';
-import { uploadImages } from '../services/cloudinary';
 import './CreateItem.css';
 
 export function CreateItemPage() {
@@ -67,16 +66,8 @@ export function CreateItemPage() {
         setIsLoading(true);
 
         try {
-            // Upload images to Cloudinary first
-            let imageUrls: string[] = [];
-            if (images.length > 0) {
-                setUploadProgress('Uploading images...');
-                imageUrls = await uploadImages(images);
-                setUploadProgress('');
-            }
-
-            // Create item with image URLs
-            await itemsApi.create({
+            // Create the item, then upload its images to it
+            const created = await itemsApi.create({
                 owner_id: user.id,
                 title: formData.title,
                 description: formData.description,
@@ -88,8 +79,15 @@ export function CreateItemPage() {
                 security_deposit: formData.security_deposit ? parseFloat(formData.security_deposit) : 0,
                 city: formData.city,
                 address: formData.address || undefined,
-                images: imageUrls,
-            });
+            }) as { id: string };
+
+            if (images.length > 0) {
+                setUploadProgress('Uploading images...');
+                for (const file of images) {
+                    await itemsApi.uploadImage(created.id, user.id, file);
+                }
+                setUploadProgress('');
+            }
             navigate('/owner/items');
         } catch (err: any) {
             setError(err.message || 'Failed to create item');
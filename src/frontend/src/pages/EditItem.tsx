@@ -2,7 +2,6 @@ import React, { useState, useEffect } from 'react';
 import { useParams, useNavigate } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import { itemsApi } from '../services/api';
-import { uploadImages } from '../services/cloudinary';
 import './CreateItem.css';
 
 export function EditItemPage() {
@@ -28,6 +27,7 @@ export function EditItemPage() {
         specifications: {} as Record<string, string>,
     });
 
+    const [originalImages, setOriginalImages] = useState<string[]>([]);
     const [existingImages, setExistingImages] = useState<string[]>([]);
     const [newImageFiles, setNewImageFiles] = useState<File[]>([]);
     const [newImagePreviews, setNewImagePreviews] = useState<string[]>([]);
@@ -64,6 +64,7 @@ export function EditItemPage() {
                     city: item.city || '',
                     specifications: item.specifications || {},
                 });
+                setOriginalImages(item.images || []);
                 setExistingImages(item.images || []);
             } catch (err: any) {
                 setError(err.message || 'Failed to load item');
@@ -109,16 +110,17 @@ export function EditItemPage() {
         setError('');
 
         try {
-            // Upload new images if any
-            let newImageUrls: string[] = [];
+            // Images are removed and uploaded one at a time through the item
+            for (const url of originalImages.filter(url => !existingImages.includes(url))) {
+                await itemsApi.removeImage(id, user.id, url);
+            }
             if (newImageFiles.length > 0) {
                 setUploadingImages(true);
-                newImageUrls = await uploadImages(newImageFiles);
+                for (const file of newImageFiles) {
+                    await itemsApi.uploadImage(id, user.id, file);
+                }
             }
 
-            // Combine existing and new images
-            const allImages = [...existingImages, ...newImageUrls];
-
             const itemData = {
                 title: formData.title,
                 description: formData.description,
@@ -131,7 +133,6 @@ export function EditItemPage() {
                 address: formData.address || undefined,
                 city: formData.city,
                 specifications: Object.keys(formData.specifications).length > 0 ? formData.specifications : undefined,
-                images: allImages,
             };
 
             await itemsApi.update(id, itemData);
@@ -87,14 +87,16 @@ export function ItemDetailPage() {
             start.setHours(0, 0, 0, 0);
             end.setHours(23, 59, 59, 999);
 
-            await bookingsApi.create({
+            // Prices are set by the server; the booking is created from its quote
+            const quote = await bookingsApi.quote({
                 renter_id: user.id,
-                owner_id: item.owner_id,
                 rental_item_id: item.id,
                 start_date: start.toISOString(),
                 end_date: end.toISOString(),
-                daily_rate: item.daily_rate,
-                security_deposit: item.security_deposit || 0,
+            });
+            await bookingsApi.create({
+                renter_id: user.id,
+                quote: quote.quote,
             });
             setBookingSuccess(true);
         } catch (error: any) {
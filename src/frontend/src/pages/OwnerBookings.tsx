@@ -16,7 +16,7 @@ export function OwnerBookingsPage() {
             if (!user) return;
 
             try {
-                const result = await bookingsApi.getOwnerBookings(user.id, 1, 20);
+                const result = await bookingsApi.getOwnerBookings(user.id, { limit: 20 });
                 const bookingsList = result.bookings || [];
 
                 // Fetch item details for each booking to get images
@@ -1,7 +1,6 @@
 import { useState, useEffect } from 'react';
 import { useAuth } from '../context/AuthContext';
 import { usersApi } from '../services/api';
-import { uploadToCloudinary } from '../services/cloudinary';
 import './Profile.css';
 
 export function ProfilePage() {
@@ -62,9 +61,8 @@ export function ProfilePage() {
         setError('');
 
         try {
-            const imageUrl = await uploadToCloudinary(file);
-            await usersApi.updateAvatar(user.id, imageUrl);
-            updateUser({ ...user, avatar_url: imageUrl });
+            const result = await usersApi.uploadAvatar(user.id, file);
+            updateUser({ ...user, avatar_url: result.avatar_url });
             setSuccess('Profile picture updated successfully!');
         } catch (err: any) {
             setError(err.message || 'Failed to upload avatar');
@@ -11,8 +11,9 @@ async function request<T>(
 ): Promise<T> {
     const token = localStorage.getItem('access_token');
 
+    // Multipart bodies need the browser to set the content type and boundary
     const headers: HeadersInit = {
-        'Content-Type': 'application/json',
+        ...(options.body instanceof FormData ? {} : { 'Content-Type': 'application/json' }),
         ...options.headers,
     };
 
@@ -33,6 +34,40 @@ async function request<T>(
     return response.json();
 }
 
+function upload<T>(endpoint: string, form: FormData): Promise<T> {
+    return request<T>(endpoint, { method: 'POST', body: form });
+}
+
+// download fetches a file, such as an export, rather than JSON
+async function download(endpoint: string): Promise<Blob> {
+    const token = localStorage.getItem('access_token');
+    const response = await fetch(`${API_BASE_URL}${endpoint}`, {
+        headers: token ? { Authorization: `Bearer ${token}` } : {},
+    });
+
+    if (!response.ok) {
+        const error = await response.json().catch(() => ({ error: 'Unknown error' }));
+        throw new Error(error.error || `HTTP ${response.status}`);
+    }
+
+    return response.blob();
+}
+
+export interface MediaVariant {
+    url: string;
+    width: number;
+    height: number;
+}
+
+export interface MediaAsset {
+    url: string;
+    content_type: string;
+    size: number;
+    width?: number;
+    height?: number;
+    variants?: Record<string, MediaVariant>;
+}
+
 // ========== Auth API ==========
 export const authApi = {
     register: (data: {
@@ -96,6 +131,23 @@ export const usersApi = {
             body: JSON.stringify({ user_id: userId, avatar_url: avatarUrl }),
         }),
 
+    uploadAvatar: (userId: string, file: File) => {
+        const form = new FormData();
+        form.append('user_id', userId);
+        form.append('avatar', file);
+        return upload<{ id: string; avatar_url: string; avatar: MediaAsset }>('/api/auth/avatar', form);
+    },
+
+    uploadDocument: (userId: string, documentType: 'driver_license' | 'national_id' | 'passport', file: File) => {
+        const form = new FormData();
+        form.append('user_id', userId);
+        form.append('document_type', documentType);
+        form.append('document', file);
+        return upload<{ id: string; document_type: string; document_url: string; uploaded_at: string }>(
+            '/api/auth/documents', form
+        );
+    },
+
     changePassword: (userId: string, currentPassword: string, newPassword: string) =>
         request('/api/auth/change-password', {
             method: 'POST',
@@ -108,6 +160,192 @@ export const usersApi = {
 };
 
 // ========== Items API ==========
+export interface AvailabilityRange {
+    start: string;
+    end: string;
+    status: 'available' | 'booked' | 'maintenance' | 'blocked';
+    bookable: boolean;
+}
+
+export interface BlackoutPattern {
+    repeat: 'once' | 'weekly' | 'yearly';
+    weekdays?: number[];
+    start_date?: string;
+    end_date?: string;
+    reason?: string;
+}
+
+export interface RentalRules {
+    min_days: number;
+    max_days: number;
+    lead_time_hours: number;
+    max_advance_days: number;
+    blackouts: BlackoutPattern[];
+}
+
+export interface ItemAvailability {
+    item_id: string;
+    from: string;
+    to: string;
+    rules: RentalRules;
+    days: { date: string; status: AvailabilityRange['status']; bookable: boolean }[];
+    ranges: AvailabilityRange[];
+}
+
+export interface FacetCount {
+    value: string;
+    label?: string;
+    count: number;
+}
+
+export interface SearchResponse {
+    items: any[];
+    total: number;
+    page: number;
+    facets: {
+        categories: FacetCount[];
+        subcategories: FacetCount[];
+        cities: FacetCount[];
+        price_bands: FacetCount[];
+    };
+    corrected_query?: string;
+}
+
+export interface AttributeSpec {
+    key: string;
+    label: string;
+    type: 'string' | 'number' | 'integer' | 'boolean' | 'enum';
+    unit?: string;
+    options?: string[];
+    required: boolean;
+    aliases?: string[];
+}
+
+export interface SpecSchema {
+    id: string;
+    category: string;
+    subcategory: string;
+    attributes: AttributeSpec[];
+    updated_by: string;
+    created_at: string;
+    updated_at: string;
+}
+
+export type ImportFormat = 'csv' | 'ndjson';
+
+export interface ImportRowResult {
+    line: number;
+    item_id?: string;
+    errors?: string[];
+}
+
+export interface ImportJob {
+    id: string;
+    owner_id: string;
+    format: ImportFormat;
+    status: 'pending' | 'running' | 'completed';
+    total: number;
+    succeeded: number;
+    failed: number;
+    results: ImportRowResult[];
+    created_at: string;
+    started_at?: string;
+    finished_at?: string;
+}
+
+export interface ItemUnit {
+    id: string;
+    label: string;
+    serial_number?: string;
+    is_active: boolean;
+    created_at: string;
+}
+
+export interface ItemUnits {
+    item_id: string;
+    capacity: number;
+    units: ItemUnit[];
+    added?: ItemUnit[];
+}
+
+export interface AddOnSettings {
+    name: string;
+    description?: string;
+    kind?: 'extra' | 'bundle';
+    includes?: string[];
+    pricing: 'per_day' | 'flat';
+    price: number;
+    stock?: number;
+    is_active?: boolean;
+}
+
+export interface AddOn extends AddOnSettings {
+    id: string;
+    created_at: string;
+}
+
+export interface ItemAddOns {
+    item_id: string;
+    add_ons: AddOn[];
+}
+
+export interface SelectedAddOn {
+    add_on_id: string;
+    name?: string;
+    quantity: number;
+}
+
+export type PricingRuleKind = 'weekend' | 'holiday' | 'seasonal' | 'last_minute' | 'long_stay';
+
+export interface PricingRuleSettings {
+    name?: string;
+    kind: PricingRuleKind;
+    adjustment_percent: number;
+    weekdays?: number[];
+    dates?: string[];
+    start_date?: string;
+    end_date?: string;
+    yearly?: boolean;
+    within_days?: number;
+    min_days?: number;
+    is_active?: boolean;
+}
+
+export interface PricingRule extends PricingRuleSettings {
+    id: string;
+    name: string;
+    created_at: string;
+}
+
+export interface ItemPricingRules {
+    item_id: string;
+    pricing_rules: PricingRule[];
+}
+
+export interface PriceLine {
+    description: string;
+    quantity: number;
+    unit_price: number;
+    amount: number;
+}
+
+export interface DayPrice {
+    date: string;
+    base_rate: number;
+    adjustments?: { rule_id: string; name: string; percent: number; amount: number }[];
+    price: number;
+}
+
+export interface RentalPrice {
+    item_id: string;
+    start_date: string;
+    end_date: string;
+    total_days: number;
+    days: DayPrice[];
+    lines: PriceLine[];
+    total: number;
+}
+
 export const itemsApi = {
     list: (params?: {
         category?: string;
@@ -117,6 +355,12 @@ export const itemsApi = {
         sort?: string;
         page?: number;
         page_size?: number;
+        lat?: number;
+        lng?: number;
+        radius_km?: number;
+        available_from?: string;
+        available_to?: string;
+        attrs?: string[];
     }) => {
         const searchParams = new URLSearchParams();
         if (params?.category) searchParams.set('category', params.category);
@@ -124,6 +368,16 @@ export const itemsApi = {
         if (params?.min_price) searchParams.set('min_price', params.min_price.toString());
         if (params?.max_price) searchParams.set('max_price', params.max_price.toString());
         if (params?.sort) searchParams.set('sort', params.sort);
+        if (params?.lat !== undefined && params?.lng !== undefined && params?.radius_km) {
+            searchParams.set('lat', params.lat.toString());
+            searchParams.set('lng', params.lng.toString());
+            searchParams.set('radius_km', params.radius_km.toString());
+        }
+        if (params?.available_from && params?.available_to) {
+            searchParams.set('available_from', params.available_from);
+            searchParams.set('available_to', params.available_to);
+        }
+        params?.attrs?.forEach((attr) => searchParams.append('attr', attr));
         if (params?.page) searchParams.set('page', params.page.toString());
         if (params?.page_size) searchParams.set('page_size', params.page_size.toString());
 
@@ -160,25 +414,388 @@ export const itemsApi = {
         return request<{ items: any[]; total: number }>(`/api/items/owner?${searchParams}`);
     },
 
-    search: (params?: { category?: string; city?: string; page?: number }) => {
+    search: (params?: {
+        q?: string;
+        category?: string;
+        city?: string;
+        page?: number;
+        available_from?: string;
+        available_to?: string;
+        attrs?: string[];
+    }) => {
         const searchParams = new URLSearchParams();
+        if (params?.q) searchParams.set('q', params.q);
+        if (params?.available_from && params?.available_to) {
+            searchParams.set('available_from', params.available_from);
+            searchParams.set('available_to', params.available_to);
+        }
+        params?.attrs?.forEach((attr) => searchParams.append('attr', attr));
         if (params?.category) searchParams.set('category', params.category);
         if (params?.city) searchParams.set('city', params.city);
         if (params?.page) searchParams.set('page', params.page.toString());
-        return request<{ items: any[]; total: number }>(`/api/items/search?${searchParams}`);
+        return request<SearchResponse>(`/api/items/search?${searchParams}`);
+    },
+
+    getAvailability: (id: string, from?: string, to?: string) => {
+        const searchParams = new URLSearchParams();
+        if (from) searchParams.set('from', from);
+        if (to) searchParams.set('to', to);
+        const query = searchParams.toString();
+        return request<ItemAvailability>(
+            `/api/items/${id}/availability${query ? `?${query}` : ''}`
+        );
+    },
+
+    getRules: (id: string) =>
+        request<{ item_id: string; rules: RentalRules }>(`/api/items/${id}/rules`),
+
+    setRules: (id: string, ownerId: string, rules: RentalRules) =>
+        request<{ item_id: string; rules: RentalRules }>(`/api/items/${id}/rules`, {
+            method: 'PUT',
+            body: JSON.stringify({ owner_id: ownerId, rules }),
+        }),
+
+    uploadImage: (id: string, ownerId: string, file: File) => {
+        const form = new FormData();
+        form.append('owner_id', ownerId);
+        form.append('image', file);
+        return upload<{ item_id: string; image: MediaAsset; images: string[] }>(`/api/items/${id}/images`, form);
+    },
+
+    removeImage: (id: string, ownerId: string, url: string) =>
+        request<{ item_id: string; images: string[] }>(
+            `/api/items/${id}/images?owner_id=${ownerId}&url=${encodeURIComponent(url)}`,
+            { method: 'DELETE' }
+        ),
+
+    importItems: (ownerId: string, file: File, format: ImportFormat) => {
+        const form = new FormData();
+        form.append('file', file);
+        return upload<ImportJob>(`/api/items/imports?owner_id=${ownerId}&format=${format}`, form);
+    },
+
+    getImport: (id: string, ownerId: string) =>
+        request<ImportJob>(`/api/items/imports?id=${id}&owner_id=${ownerId}`),
+
+    listImports: (ownerId: string) =>
+        request<{ imports: ImportJob[] }>(`/api/items/imports?owner_id=${ownerId}`),
+
+    getImportErrors: (id: string, ownerId: string) =>
+        download(`/api/items/import-errors?id=${id}&owner_id=${ownerId}`),
+
+    exportItems: (ownerId: string, params?: {
+        format?: ImportFormat;
+        dataset?: 'items' | 'maintenance' | 'availability';
+        from?: string;
+        to?: string;
+    }) => {
+        const searchParams = new URLSearchParams({ owner_id: ownerId });
+        if (params?.format) searchParams.set('format', params.format);
+        if (params?.dataset) searchParams.set('dataset', params.dataset);
+        if (params?.from && params?.to) {
+            searchParams.set('from', params.from);
+            searchParams.set('to', params.to);
+        }
+        return download(`/api/items/export?${searchParams}`);
+    },
+
+    getSchemas: (category?: string) =>
+        request<{ schemas: SpecSchema[] }>(
+            `/api/items/schemas${category ? `?category=${category}` : ''}`
+        ),
+
+    saveSchema: (adminId: string, category: string, subcategory: string, attributes: AttributeSpec[]) =>
+        request<SpecSchema>('/api/items/schemas', {
+            method: 'PUT',
+            body: JSON.stringify({ admin_id: adminId, category, subcategory, attributes }),
+        }),
+
+    deleteSchema: (adminId: string, category: string, subcategory?: string) => {
+        const searchParams = new URLSearchParams({ admin_id: adminId, category });
+        if (subcategory) searchParams.set('subcategory', subcategory);
+        return request(`/api/items/schemas?${searchParams}`, { method: 'DELETE' });
+    },
+
+    listUnits: (itemId: string, ownerId: string) =>
+        request<ItemUnits>(`/api/items/${itemId}/units?owner_id=${ownerId}`),
+
+    addUnits: (itemId: string, ownerId: string, data: { count?: number; label?: string; serial_number?: string }) =>
+        request<ItemUnits>(`/api/items/${itemId}/units`, {
+            method: 'POST',
+            body: JSON.stringify({ owner_id: ownerId, ...data }),
+        }),
+
+    updateUnit: (itemId: string, unitId: string, ownerId: string, data: { label: string; serial_number?: string; is_active: boolean }) =>
+        request<ItemUnit>(`/api/items/${itemId}/units?unit_id=${unitId}`, {
+            method: 'PUT',
+            body: JSON.stringify({ owner_id: ownerId, ...data }),
+        }),
+
+    removeUnit: (itemId: string, unitId: string, ownerId: string) =>
+        request<ItemUnits>(`/api/items/${itemId}/units?unit_id=${unitId}&owner_id=${ownerId}`, { method: 'DELETE' }),
+
+    listAddOns: (itemId: string, ownerId?: string) =>
+        request<ItemAddOns>(`/api/items/${itemId}/addons${ownerId ? `?owner_id=${ownerId}` : ''}`),
+
+    createAddOn: (itemId: string, ownerId: string, data: AddOnSettings) =>
+        request<AddOn>(`/api/items/${itemId}/addons`, {
+            method: 'POST',
+            body: JSON.stringify({ owner_id: ownerId, ...data }),
+        }),
+
+    updateAddOn: (itemId: string, addOnId: string, ownerId: string, data: AddOnSettings) =>
+        request<AddOn>(`/api/items/${itemId}/addons?add_on_id=${addOnId}`, {
+            method: 'PUT',
+            body: JSON.stringify({ owner_id: ownerId, ...data }),
+        }),
+
+    removeAddOn: (itemId: string, addOnId: string, ownerId: string) =>
+        request<ItemAddOns>(`/api/items/${itemId}/addons?add_on_id=${addOnId}&owner_id=${ownerId}`, { method: 'DELETE' }),
+
+    listPricingRules: (itemId: string, ownerId: string) =>
+        request<ItemPricingRules>(`/api/items/${itemId}/pricing-rules?owner_id=${ownerId}`),
+
+    createPricingRule: (itemId: string, ownerId: string, data: PricingRuleSettings) =>
+        request<PricingRule>(`/api/items/${itemId}/pricing-rules`, {
+            method: 'POST',
+            body: JSON.stringify({ owner_id: ownerId, ...data }),
+        }),
+
+    updatePricingRule: (itemId: string, ruleId: string, ownerId: string, data: PricingRuleSettings) =>
+        request<PricingRule>(`/api/items/${itemId}/pricing-rules?rule_id=${ruleId}`, {
+            method: 'PUT',
+            body: JSON.stringify({ owner_id: ownerId, ...data }),
+        }),
+
+    removePricingRule: (itemId: string, ruleId: string, ownerId: string) =>
+        request<ItemPricingRules>(`/api/items/${itemId}/pricing-rules?rule_id=${ruleId}&owner_id=${ownerId}`, { method: 'DELETE' }),
+
+    price: (itemId: string, startDate: string, endDate: string) => {
+        const searchParams = new URLSearchParams({ start_date: startDate, end_date: endDate });
+        return request<RentalPrice>(`/api/items/${itemId}/price?${searchParams}`);
     },
 };
 
+// ========== Maintenance API ==========
+export interface MaintenanceLog {
+    id: string;
+    rental_item_id: string;
+    unit_id?: string;
+    maintenance_type: string;
+    description: string;
+    start_date: string;
+    end_date?: string;
+    cost: number;
+    status: 'scheduled' | 'in_progress' | 'completed';
+    slot_id?: string;
+    started_at?: string;
+    completed_at?: string;
+    created_at: string;
+}
+
+export interface MaintenanceCosts {
+    count: number;
+    total: number;
+    spent: number;
+    planned: number;
+    by_type: { maintenance_type: string; count: number; cost: number }[];
+}
+
+export interface MaintenancePlanSettings {
+    maintenance_type: string;
+    description?: string;
+    interval_months?: number;
+    usage_days?: number;
+    estimated_cost?: number;
+    duration_days?: number;
+    lead_days?: number;
+    is_active?: boolean;
+}
+
+export interface MaintenancePlan extends Required<MaintenancePlanSettings> {
+    id: string;
+    rental_item_id: string;
+    owner_id: string;
+    last_serviced_at: string;
+    serviced_units?: { unit_id: string; serviced_at: string }[];
+    pending_log_id?: string;
+    last_checked_at?: string;
+    last_error?: string;
+    created_at: string;
+    updated_at: string;
+    forecasts?: { unit_id?: string; rented_days: number; due_at?: string }[];
+}
+
+export const maintenanceApi = {
+    schedule: (data: {
+        item_id: string;
+        owner_id: string;
+        unit_id?: string;
+        maintenance_type: string;
+        description?: string;
+        start_date: string;
+        end_date: string;
+        cost?: number;
+    }) =>
+        request<MaintenanceLog>('/api/maintenance', {
+            method: 'POST',
+            body: JSON.stringify(data),
+        }),
+
+    get: (id: string, ownerId: string) =>
+        request<MaintenanceLog>(`/api/maintenance?id=${id}&owner_id=${ownerId}`),
+
+    listByItem: (itemId: string, ownerId: string, page?: number, pageSize?: number, unitId?: string) => {
+        const searchParams = new URLSearchParams({ item_id: itemId, owner_id: ownerId });
+        if (unitId) searchParams.set('unit_id', unitId);
+        if (page) searchParams.set('page', page.toString());
+        if (pageSize) searchParams.set('page_size', pageSize.toString());
+        return request<{ maintenance: MaintenanceLog[]; total: number }>(`/api/maintenance?${searchParams}`);
+    },
+
+    start: (id: string, ownerId: string) =>
+        request<MaintenanceLog>(`/api/maintenance/${id}/start`, {
+            method: 'POST',
+            body: JSON.stringify({ owner_id: ownerId }),
+        }),
+
+    complete: (id: string, ownerId: string, cost?: number) =>
+        request<MaintenanceLog>(`/api/maintenance/${id}/complete`, {
+            method: 'POST',
+            body: JSON.stringify({ owner_id: ownerId, cost }),
+        }),
+
+    listPlans: (itemId: string, ownerId: string) =>
+        request<{ plans: MaintenancePlan[] }>(`/api/maintenance/plans?item_id=${itemId}&owner_id=${ownerId}`),
+
+    createPlan: (itemId: string, ownerId: string, settings: MaintenancePlanSettings, lastServicedAt?: string) =>
+        request<MaintenancePlan>('/api/maintenance/plans', {
+            method: 'POST',
+            body: JSON.stringify({ item_id: itemId, owner_id: ownerId, last_serviced_at: lastServicedAt, ...settings }),
+        }),
+
+    updatePlan: (id: string, ownerId: string, settings: MaintenancePlanSettings) =>
+        request<MaintenancePlan>(`/api/maintenance/plans?id=${id}`, {
+            method: 'PUT',
+            body: JSON.stringify({ owner_id: ownerId, ...settings }),
+        }),
+
+    deletePlan: (id: string, ownerId: string) =>
+        request(`/api/maintenance/plans?id=${id}&owner_id=${ownerId}`, { method: 'DELETE' }),
+
+    getCosts: (ownerId: string, itemId?: string) => {
+        const searchParams = new URLSearchParams({ owner_id: ownerId });
+        if (itemId) searchParams.set('item_id', itemId);
+        return request<MaintenanceCosts>(`/api/maintenance/costs?${searchParams}`);
+    },
+};
+
+// ========== Categories API ==========
+
+export interface Category {
+    id: string;
+    slug: string;
+    name: string;
+    parent?: string;
+    ancestors: string[];
+    is_active: boolean;
+    sort_order: number;
+    created_at: string;
+    updated_at: string;
+}
+
+export interface CategoryNode extends Category {
+    children: CategoryNode[];
+}
+
+export const categoriesApi = {
+    list: (includeInactive?: boolean) =>
+        request<{ categories: CategoryNode[] }>(
+            `/api/categories${includeInactive ? '?include_inactive=true' : ''}`
+        ),
+
+    get: (slug: string) =>
+        request<Category>(`/api/categories/${slug}`),
+
+    items: (slug: string, page?: number, pageSize?: number) => {
+        const searchParams = new URLSearchParams();
+        if (page) searchParams.set('page', page.toString());
+        if (pageSize) searchParams.set('page_size', pageSize.toString());
+        const query = searchParams.toString();
+        return request<{ category: Category; items: any[]; total: number; page: number }>(
+            `/api/categories/${slug}/items${query ? `?${query}` : ''}`
+        );
+    },
+
+    create: (adminId: string, data: { slug: string; name: string; parent?: string; sort_order?: number }) =>
+        request<Category>('/api/categories', {
+            method: 'POST',
+            body: JSON.stringify({ admin_id: adminId, ...data }),
+        }),
+
+    update: (adminId: string, slug: string, data: { name?: string; parent?: string; is_active?: boolean; sort_order?: number }) =>
+        request<Category>(`/api/categories/${slug}`, {
+            method: 'PUT',
+            body: JSON.stringify({ admin_id: adminId, ...data }),
+        }),
+
+    delete: (adminId: string, slug: string) =>
+        request(`/api/categories/${slug}?admin_id=${adminId}`, { method: 'DELETE' }),
+};
+
 // ========== Bookings API ==========
+export interface BookingListQuery {
+    view?: 'upcoming' | 'current' | 'past';
+    status?: string[];
+    itemId?: string;
+    from?: string;
+    to?: string;
+    cursor?: string;
+    limit?: number;
+}
+
+export interface BookingPage {
+    bookings: any[];
+    next_cursor?: string;
+    has_more: boolean;
+}
+
+function bookingListParams(query: BookingListQuery): URLSearchParams {
+    const params = new URLSearchParams();
+    if (query.view) params.set('view', query.view);
+    if (query.status?.length) params.set('status', query.status.join(','));
+    if (query.itemId) params.set('item_id', query.itemId);
+    if (query.from) params.set('from', query.from);
+    if (query.to) params.set('to', query.to);
+    if (query.cursor) params.set('cursor', query.cursor);
+    if (query.limit) params.set('limit', query.limit.toString());
+    return params;
+}
+
 export const bookingsApi = {
-    create: (data: {
+    quote: (data: {
         renter_id: string;
-        owner_id: string;
         rental_item_id: string;
         start_date: string;
         end_date: string;
-        daily_rate: number;
-        security_deposit: number;
+        add_ons?: SelectedAddOn[];
+    }) => request<{
+        quote: string;
+        days: DayPrice[];
+        lines: PriceLine[];
+        add_ons: SelectedAddOn[];
+        add_ons_total: number;
+        total_amount: number;
+        expires_at: string;
+    }>('/api/bookings/quote', {
+        method: 'POST',
+        body: JSON.stringify(data),
+    }),
+
+    create: (data: {
+        renter_id: string;
+        quote: string;
+        payment_method?: string;
     }) => request('/api/bookings', {
         method: 'POST',
         body: JSON.stringify(data),
@@ -187,18 +804,16 @@ export const bookingsApi = {
     get: (id: string) =>
         request<any>(`/api/bookings?id=${id}`),
 
-    getRenterBookings: (renterId: string, page?: number, pageSize?: number) => {
-        const params = new URLSearchParams({ renter_id: renterId });
-        if (page) params.set('page', page.toString());
-        if (pageSize) params.set('page_size', pageSize.toString());
-        return request<{ bookings: any[]; total: number }>(`/api/bookings/renter?${params}`);
+    getRenterBookings: (renterId: string, query: BookingListQuery = {}) => {
+        const params = bookingListParams(query);
+        params.set('renter_id', renterId);
+        return request<BookingPage>(`/api/bookings/renter?${params}`);
     },
 
-    getOwnerBookings: (ownerId: string, page?: number, pageSize?: number) => {
-        const params = new URLSearchParams({ owner_id: ownerId });
-        if (page) params.set('page', page.toString());
-        if (pageSize) params.set('page_size', pageSize.toString());
-        return request<{ bookings: any[]; total: number }>(`/api/bookings/owner?${params}`);
+    getOwnerBookings: (ownerId: string, query: BookingListQuery = {}) => {
+        const params = bookingListParams(query);
+        params.set('owner_id', ownerId);
+        return request<BookingPage>(`/api/bookings/owner?${params}`);
     },
 
     confirm: (bookingId: string, ownerId: string) =>